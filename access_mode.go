@@ -0,0 +1,127 @@
+package sqlx
+
+import (
+	"context"
+	"strings"
+	"unicode"
+)
+
+// AccessMode classifies a query as read or write, for routing queries between primary and replicas.
+type AccessMode int
+
+const (
+	// AccessModeAuto resolves access mode by the verb of query
+	AccessModeAuto AccessMode = iota
+	AccessModeRead
+	AccessModeWrite
+)
+
+func (mode AccessMode) String() string {
+	switch mode {
+	case AccessModeRead:
+		return "read"
+	case AccessModeWrite:
+		return "write"
+	}
+	return "auto"
+}
+
+type contextKeyAccessMode int
+
+// ContextWithAccessMode marks queries executed with the ctx as the access mode,
+// overrides the auto detection. For example, a SELECT locking rows should be AccessModeWrite.
+func ContextWithAccessMode(ctx context.Context, mode AccessMode) context.Context {
+	return context.WithValue(ctx, contextKeyAccessMode(1), mode)
+}
+
+func AccessModeFromContext(ctx context.Context) AccessMode {
+	if ctx == nil {
+		return AccessModeAuto
+	}
+	if mode, ok := ctx.Value(contextKeyAccessMode(1)).(AccessMode); ok {
+		return mode
+	}
+	return AccessModeAuto
+}
+
+// ResolveAccessMode returns the access mode marked in ctx, or detected by query when not marked.
+func ResolveAccessMode(ctx context.Context, query string) AccessMode {
+	if mode := AccessModeFromContext(ctx); mode != AccessModeAuto {
+		return mode
+	}
+	return DetectAccessMode(query)
+}
+
+// DetectAccessMode detects access mode by the verb of query.
+// SELECT / SHOW / EXPLAIN / DESCRIBE are read, unless rows locked or data modified in WITH.
+// All others are write.
+func DetectAccessMode(query string) AccessMode {
+	words := sqlWords(query)
+
+	if len(words) == 0 {
+		return AccessModeWrite
+	}
+
+	switch words[0] {
+	case "SELECT", "SHOW", "EXPLAIN", "DESCRIBE", "DESC", "VALUES", "TABLE", "WITH":
+		for i, w := range words {
+			switch w {
+			case "INSERT", "UPDATE", "DELETE", "MERGE":
+				return AccessModeWrite
+			case "SHARE":
+				// FOR SHARE / LOCK IN SHARE MODE
+				if i > 0 && (words[i-1] == "FOR" || words[i-1] == "IN") {
+					return AccessModeWrite
+				}
+			}
+		}
+		return AccessModeRead
+	}
+
+	return AccessModeWrite
+}
+
+// sqlWords returns upper-cased words of query, with comments and quoted literals skipped.
+func sqlWords(query string) (words []string) {
+	word := strings.Builder{}
+
+	flush := func() {
+		if word.Len() > 0 {
+			words = append(words, strings.ToUpper(word.String()))
+			word.Reset()
+		}
+	}
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+
+		switch {
+		case c == '-' && i+1 < len(query) && query[i+1] == '-':
+			flush()
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(query) && query[i+1] == '*':
+			flush()
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return
+			}
+			i = i + 2 + end + 1
+		case c == '\'' || c == '"' || c == '`':
+			flush()
+			end := strings.IndexByte(query[i+1:], c)
+			if end < 0 {
+				return
+			}
+			i = i + 1 + end
+		case c == '_' || unicode.IsLetter(rune(c)):
+			word.WriteByte(c)
+		default:
+			flush()
+		}
+	}
+
+	flush()
+	return
+}
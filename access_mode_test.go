@@ -0,0 +1,41 @@
+package sqlx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-courier/sqlx/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestResolveAccessMode(t *testing.T) {
+	cases := map[string]struct {
+		query string
+		mode  sqlx.AccessMode
+	}{
+		"select":                {"SELECT * FROM t WHERE f_name = 'delete'", sqlx.AccessModeRead},
+		"select with comment":   {"/* UPDATE */ select f_id FROM t", sqlx.AccessModeRead},
+		"select for update":     {"SELECT * FROM t\nFOR UPDATE", sqlx.AccessModeWrite},
+		"select for share":      {"SELECT * FROM t FOR SHARE", sqlx.AccessModeWrite},
+		"with select":           {"WITH t_tmp(f_id) AS (SELECT f_id FROM t) SELECT * FROM t_tmp", sqlx.AccessModeRead},
+		"with delete":           {"WITH t_tmp AS (DELETE FROM t RETURNING *) SELECT * FROM t_tmp", sqlx.AccessModeWrite},
+		"show":                  {"SHOW TABLES", sqlx.AccessModeRead},
+		"insert":                {"INSERT INTO t (f_id) VALUES (?)", sqlx.AccessModeWrite},
+		"update":                {"  UPDATE t SET f_name = ?", sqlx.AccessModeWrite},
+		"delete":                {"DELETE FROM t", sqlx.AccessModeWrite},
+		"create table":          {"CREATE TABLE t (f_id bigint)", sqlx.AccessModeWrite},
+		"empty":                 {"", sqlx.AccessModeWrite},
+		"line comment prefixed": {"-- SELECT\nDELETE FROM t", sqlx.AccessModeWrite},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			NewWithT(t).Expect(sqlx.ResolveAccessMode(context.Background(), c.query)).To(Equal(c.mode))
+		})
+	}
+
+	t.Run("override by context", func(t *testing.T) {
+		ctx := sqlx.ContextWithAccessMode(context.Background(), sqlx.AccessModeWrite)
+		NewWithT(t).Expect(sqlx.ResolveAccessMode(ctx, "SELECT 1")).To(Equal(sqlx.AccessModeWrite))
+	})
+}
@@ -35,18 +35,23 @@ func (o onConflict) DoUpdateSet(assignments ...*Assignment) *onConflict {
 	return &o
 }
 
+// IsNil
+// conflict target could be omitted only for DO NOTHING
 func (o *onConflict) IsNil() bool {
-	return o == nil || IsNilExpr(o.columns) || (!o.doNothing && len(o.assignments) == 0)
+	return o == nil || (!o.doNothing && (IsNilExpr(o.columns) || len(o.assignments) == 0))
 }
 
 func (o *onConflict) Ex(ctx context.Context) *Ex {
 	e := Expr("ON CONFLICT ")
 
-	e.WriteGroup(func(e *Ex) {
-		e.WriteExpr(o.columns)
-	})
+	if !IsNilExpr(o.columns) {
+		e.WriteGroup(func(e *Ex) {
+			e.WriteExpr(o.columns)
+		})
+		e.WriteByte(' ')
+	}
 
-	e.WriteString(" DO ")
+	e.WriteString("DO ")
 
 	if o.doNothing {
 		e.WriteString("NOTHING")
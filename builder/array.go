@@ -0,0 +1,11 @@
+package builder
+
+// Array wraps slice as one arg bound as array, instead of expanded as list of args like In,
+// for dialects support array parameters, like c.Expr("# = ANY(?)", Array(ids)) of postgres.
+func Array(values interface{}) *ArrayArg {
+	return &ArrayArg{Values: values}
+}
+
+type ArrayArg struct {
+	Values interface{}
+}
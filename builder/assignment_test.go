@@ -17,4 +17,29 @@ func TestAssignment(t *testing.T) {
 			})),
 		).To(BeExpr("(a,b) VALUES (?,?),(?,?)", 1, 2, 3, 4))
 	})
+
+	t.Run("ValueByExpr", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(
+			Col("f_updated_at").ValueByExpr(Expr("now()")),
+		).To(BeExpr("f_updated_at = now()"))
+
+		gomega.NewWithT(t).Expect(
+			Col("f_count").ValueByExpr(Col("f_count").Incr(2)),
+		).To(BeExpr("f_count = f_count + ?", 2))
+
+		gomega.NewWithT(t).Expect(
+			Col("f_deleted_at").ValueByExpr(nil),
+		).To(BeExpr("f_deleted_at = NULL"))
+	})
+
+	t.Run("AssignmentsByFieldValues with expr", func(t *testing.T) {
+		table := T("t", Col("f_name").Field("Name"), Col("f_count").Field("Count"))
+
+		gomega.NewWithT(t).Expect(
+			Update(table).Set(table.AssignmentsByFieldValues(FieldValues{
+				"Name":  "name",
+				"Count": table.F("Count").Incr(1),
+			})...),
+		).To(BeExpr("UPDATE t SET f_count = f_count + ?, f_name = ?", 1, "name"))
+	})
 }
@@ -0,0 +1,99 @@
+package builder
+
+import (
+	"bytes"
+)
+
+// BindVars replaces value holders `?` of query by bindVar of the dialect,
+// the one code path of placeholders for drivers of all dialects, see Dialect.BindVar.
+func BindVars(query string, bindVar func(i int) string) string {
+	holders := ValueHolders(query)
+	if len(holders) == 0 {
+		return query
+	}
+
+	e := bytes.NewBufferString("")
+
+	last := 0
+	for index, i := range holders {
+		e.WriteString(query[last:i])
+		e.WriteString(bindVar(index + 1))
+		last = i + 1
+	}
+	e.WriteString(query[last:])
+
+	return e.String()
+}
+
+// ValueHolders returns positions of value holders `?` of query,
+// `?` in quoted strings or identifiers, and jsonb operators `?`, `?|`, `?&` of postgres are skipped,
+// which are never value holders of other dialects either.
+func ValueHolders(query string) []int {
+	holders := make([]int, 0)
+
+	var quote byte
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+
+		// '' in string is closed and reopened, which keeps the string quoted
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		switch c {
+		case '\'', '"':
+			quote = c
+		case '?':
+			if !isJSONBOperator(query, i) {
+				holders = append(holders, i)
+			}
+		}
+	}
+
+	return holders
+}
+
+// isJSONBOperator returns true when `?` at i is jsonb operator.
+// `?|` and `?&` are always operators, but `?||` is value holder concatenated.
+// `?` is operator when it is between operand and string key, like `f_data ? 'key'` or `f_data ? ?`.
+func isJSONBOperator(query string, i int) bool {
+	if i+1 < len(query) {
+		switch query[i+1] {
+		case '&':
+			return true
+		case '|':
+			return !(i+2 < len(query) && query[i+2] == '|')
+		}
+	}
+
+	prev := byte(0)
+	for j := i - 1; j >= 0; j-- {
+		if !isSpace(query[j]) {
+			prev = query[j]
+			break
+		}
+	}
+
+	next := byte(0)
+	for j := i + 1; j < len(query); j++ {
+		if !isSpace(query[j]) {
+			next = query[j]
+			break
+		}
+	}
+
+	return isOperandEnd(prev) && (next == '\'' || next == '?')
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isOperandEnd(c byte) bool {
+	return c == '_' || c == ')' || c == ']' || c == '\'' || c == '"' ||
+		('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
@@ -0,0 +1,22 @@
+package builder
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+func TestBindVars(t *testing.T) {
+	named := func(i int) string {
+		return "@p" + strconv.Itoa(i)
+	}
+
+	gomega.NewWithT(t).Expect(BindVars("SELECT * FROM t WHERE f_a = ? AND f_b = '?'", named)).To(gomega.Equal("SELECT * FROM t WHERE f_a = @p1 AND f_b = '?'"))
+	gomega.NewWithT(t).Expect(BindVars("SELECT * FROM t WHERE f_a = ? AND f_b = ?", named)).To(gomega.Equal("SELECT * FROM t WHERE f_a = @p1 AND f_b = @p2"))
+	gomega.NewWithT(t).Expect(BindVars("SELECT * FROM t", named)).To(gomega.Equal("SELECT * FROM t"))
+}
+
+func TestValueHolders(t *testing.T) {
+	gomega.NewWithT(t).Expect(ValueHolders(`SELECT * FROM t WHERE f_a = ? AND "f_?" = '?' AND f_data ? 'key'`)).To(gomega.Equal([]int{28}))
+}
@@ -54,13 +54,15 @@ func (c *Column) IsNil() bool {
 func (c *Column) Ex(ctx context.Context) *Ex {
 	toggles := TogglesFromContext(ctx)
 
+	name := QuoteIdent(ctx, c.Name)
+
 	if c.Table != nil && (c.exactly || toggles.Is(ToggleMultiTable)) {
 		if toggles.Is(ToggleNeedAutoAlias) {
-			return Expr("?.? AS ?", c.Table, Expr(c.Name), Expr(c.Name)).Ex(ctx)
+			return Expr("?.? AS ?", c.Table, Expr(name), Expr(name)).Ex(ctx)
 		}
-		return Expr("?.?", c.Table, Expr(c.Name)).Ex(ctx)
+		return Expr("?.?", c.Table, Expr(name)).Ex(ctx)
 	}
-	return Expr(c.Name).Ex(ctx)
+	return Expr(name).Ex(ctx)
 }
 
 func (c *Column) Expr(query string, args ...interface{}) *Ex {
@@ -97,19 +99,45 @@ func (c Column) Type(v interface{}, tagValue string) *Column {
 	return &c
 }
 
+// GeneratedAs marks the column generated by expr, storage should be STORED or VIRTUAL, empty means STORED
+func (c Column) GeneratedAs(expr string, storage string) *Column {
+	ct := ColumnType{}
+	if c.ColumnType != nil {
+		ct = *c.ColumnType
+	}
+	ct.GeneratedExpr = expr
+	ct.GeneratedStorage = strings.ToUpper(storage)
+	c.ColumnType = &ct
+	return &c
+}
+
 func (c Column) On(table *Table) *Column {
 	c.Table = table
 	return &c
 }
 
+// CommentText returns lines of description joined, which is stored as comment of column in database
+func (c *Column) CommentText() string {
+	return strings.Join(c.Description, "\n")
+}
+
 func (c *Column) T() *Table {
 	return c.Table
 }
 
+// ValueBy assigns value to column, value as SqlExpr is written as raw sql with its args, like c.Incr(1)
 func (c *Column) ValueBy(v interface{}) *Assignment {
 	return ColumnsAndValues(c, v)
 }
 
+// ValueByExpr assigns sql expression to column, like Expr("now()") or c.Incr(1), NULL when expr is nil
+func (c *Column) ValueByExpr(expr SqlExpr) *Assignment {
+	if IsNilExpr(expr) {
+		return ColumnsAndValues(c, Expr("NULL"))
+	}
+	return ColumnsAndValues(c, expr)
+}
+
 func (c *Column) Incr(d int) SqlExpr {
 	return c.Expr("# + ?", d)
 }
@@ -206,6 +234,87 @@ func (c *Column) Lte(v interface{}) SqlCondition {
 	return AsCond(c.Expr("# <= ?", v))
 }
 
+// JSONGet returns value at path of jsonb column as jsonb, like f_data -> 'a' -> 'b' of postgres,
+// keys of path are bound as args, the column itself is returned when path is empty.
+func (c *Column) JSONGet(path ...string) SqlExpr {
+	return c.jsonPath("->", path)
+}
+
+// JSONGetText returns value at path of jsonb column as text, like f_data -> 'a' ->> 'b' of postgres
+func (c *Column) JSONGetText(path ...string) SqlExpr {
+	return c.jsonPath("->>", path)
+}
+
+func (c *Column) jsonPath(op string, path []string) *Ex {
+	e := Expr("#")
+	args := make([]interface{}, len(path))
+
+	for i, key := range path {
+		if i == len(path)-1 {
+			e.WriteString(" " + op + " ?")
+		} else {
+			e.WriteString(" -> ?")
+		}
+		args[i] = key
+	}
+
+	return c.Expr(e.String(), args...)
+}
+
+// JSONContains returns condition of jsonb column containing v, like f_data @> '{"a":1}'::jsonb of postgres,
+// v is written as it is, like Expr("?::jsonb", data)
+func (c *Column) JSONContains(v SqlExpr) SqlCondition {
+	return AsCond(c.Expr("# @> ?", v))
+}
+
+// JSONContainedBy returns condition of jsonb column contained by v, like f_data <@ '{"a":1}'::jsonb of postgres
+func (c *Column) JSONContainedBy(v SqlExpr) SqlCondition {
+	return AsCond(c.Expr("# <@ ?", v))
+}
+
+// Equal returns true when definitions of columns are same, names are not compared.
+// SQL type compared by GetDataType of each engine or by go type when both columns have one.
+// Table.Diff compares data types resolved by dialect instead,
+// for columns from information schema have no go type.
+func (c *Column) Equal(other *Column) bool {
+	if c == nil || other == nil {
+		return c == other
+	}
+
+	ct, oct := c.ColumnType, other.ColumnType
+
+	if ct == nil || oct == nil {
+		return ct == oct
+	}
+
+	if ct.GetDataType != nil && oct.GetDataType != nil {
+		for _, engine := range dataTypeEngines {
+			if ct.GetDataType(engine) != oct.GetDataType(engine) {
+				return false
+			}
+		}
+	} else if ct.Type != nil && oct.Type != nil && ct.Type != oct.Type {
+		return false
+	}
+
+	return ct.Length == oct.Length &&
+		ct.Decimal == oct.Decimal &&
+		ct.Null == oct.Null &&
+		ct.AutoIncrement == oct.AutoIncrement &&
+		ct.Charset == oct.Charset &&
+		ct.Collation == oct.Collation &&
+		equalStringPtr(ct.Default, oct.Default) &&
+		equalStringPtr(ct.OnUpdate, oct.OnUpdate) &&
+		ct.GeneratedDef() == oct.GeneratedDef()
+}
+
+func equalStringPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
 func ColumnTypeFromTypeAndTag(typ reflect.Type, nameAndFlags string) *ColumnType {
 	ct := &ColumnType{}
 	ct.Type = reflectx.Deref(typ)
@@ -224,6 +333,8 @@ func ColumnTypeFromTypeAndTag(typ reflect.Type, nameAndFlags string) *ColumnType
 				ct.Null = true
 			case "autoincrement":
 				ct.AutoIncrement = true
+			case "version":
+				ct.Version = true
 			case "deprecated":
 				rename := ""
 				if len(nameAndValue) > 1 {
@@ -253,11 +364,33 @@ func ColumnTypeFromTypeAndTag(typ reflect.Type, nameAndFlags string) *ColumnType
 					panic(fmt.Errorf("missing default value"))
 				}
 				ct.Default = &nameAndValue[1]
+			case "backfill":
+				if len(nameAndValue) == 1 {
+					panic(fmt.Errorf("missing backfill value"))
+				}
+				ct.Backfill = &nameAndValue[1]
 			case "onupdate":
 				if len(nameAndValue) == 1 {
 					panic(fmt.Errorf("missing onupdate value"))
 				}
 				ct.OnUpdate = &nameAndValue[1]
+			case "after":
+				if len(nameAndValue) == 1 {
+					panic(fmt.Errorf("missing after value"))
+				}
+				ct.After = strings.ToLower(nameAndValue[1])
+			case "first":
+				ct.After = ColumnPositionFirst
+			case "charset":
+				if len(nameAndValue) == 1 {
+					panic(fmt.Errorf("missing charset value"))
+				}
+				ct.Charset = nameAndValue[1]
+			case "collate":
+				if len(nameAndValue) == 1 {
+					panic(fmt.Errorf("missing collate value"))
+				}
+				ct.Collation = nameAndValue[1]
 			}
 		}
 	}
@@ -272,17 +405,81 @@ type ColumnType struct {
 	Length  uint64
 	Decimal uint64
 
+	// Default is the raw sql of default value, like `'0'` or `CURRENT_TIMESTAMP`, nil means no default
 	Default  *string
 	OnUpdate *string
+	// Backfill is the raw sql of value to replace existed NULLs when the column is changed to NOT NULL,
+	// declared by tag flag `backfill='0'`
+	Backfill *string
 
 	Null          bool
 	AutoIncrement bool
+	// Version marks the column used for optimistic locking
+	Version bool
 
 	Comment string
 
+	// Charset and Collation of text column, declared by tag flags `charset=utf8mb4,collate=utf8mb4_bin`,
+	// empty means inherited from table or database. Charset is ignored by postgres.
+	Charset   string
+	Collation string
+
+	// After is name of the column which the column is added after, or ColumnPositionFirst,
+	// declared by tag flag `after=f_name` or `first`, only for dialects could place columns like mysql.
+	After string
+
+	// GeneratedExpr is the expression of generated column, like `GENERATED ALWAYS AS (expr) STORED`
+	GeneratedExpr string
+	// GeneratedStorage is STORED or VIRTUAL, STORED as default
+	GeneratedStorage string
+
 	DeprecatedActions *DeprecatedActions
 }
 
+// WithoutDefault returns copy of column type without default value, for comparing definitions except default
+func (ct ColumnType) WithoutDefault() *ColumnType {
+	ct.Default = nil
+	return &ct
+}
+
+// withCollationDeclared returns copy of prev column type keeping charset and collation only declared by current,
+// for the undeclared ones of current are inherited, which are loaded from database as the defaults of table.
+func withCollationDeclared(prev *ColumnType, current *ColumnType) *ColumnType {
+	ct := *prev
+	if current.Charset == "" {
+		ct.Charset = ""
+	}
+	if current.Collation == "" {
+		ct.Collation = ""
+	}
+	return &ct
+}
+
+// ColumnPositionFirst places the column first when added, which could not be a column name for names are lower case
+const ColumnPositionFirst = "FIRST"
+
+const (
+	GeneratedStorageStored  = "STORED"
+	GeneratedStorageVirtual = "VIRTUAL"
+)
+
+// IsGenerated returns true when the column is generated, which could not be inserted or updated
+func (ct *ColumnType) IsGenerated() bool {
+	return ct != nil && ct.GeneratedExpr != ""
+}
+
+// GeneratedDef returns definition of generated column for comparing
+func (ct *ColumnType) GeneratedDef() string {
+	if !ct.IsGenerated() {
+		return ""
+	}
+	storage := strings.ToUpper(ct.GeneratedStorage)
+	if storage == "" {
+		storage = GeneratedStorageStored
+	}
+	return unwrapParentheses(ct.GeneratedExpr) + " " + storage
+}
+
 type DeprecatedActions struct {
 	RenameTo string `name:"rename"`
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/go-courier/ptr"
 	. "github.com/go-courier/sqlx/v2/builder"
+	"github.com/go-courier/sqlx/v2/builder/buidertestingutils"
 	"github.com/onsi/gomega"
 )
 
@@ -19,6 +20,10 @@ func TestColumnTypeFromTypeAndTag(t *testing.T) {
 			Type:          reflect.TypeOf(1),
 			AutoIncrement: true,
 		},
+		`,version`: &ColumnType{
+			Type:    reflect.TypeOf(uint64(1)),
+			Version: true,
+		},
 		`,null`: &ColumnType{
 			Type: reflect.TypeOf(float64(1.1)),
 			Null: true,
@@ -43,3 +48,61 @@ func TestColumnTypeFromTypeAndTag(t *testing.T) {
 		})
 	}
 }
+
+func TestColumn_Equal(t *testing.T) {
+	col := Col("f_name").Type("", ",size=128,default=''")
+
+	gomega.NewWithT(t).Expect(col.Equal(Col("f_other").Type("", ",size=128,default=''"))).To(gomega.BeTrue())
+	gomega.NewWithT(t).Expect(col.Equal(Col("f_name").Type("", ",size=64,default=''"))).To(gomega.BeFalse())
+	gomega.NewWithT(t).Expect(col.Equal(Col("f_name").Type("", ",size=128,default='',null"))).To(gomega.BeFalse())
+	gomega.NewWithT(t).Expect(col.Equal(Col("f_name").Type("", ",size=128"))).To(gomega.BeFalse())
+	gomega.NewWithT(t).Expect(col.Equal(Col("f_name").Type(1, ",size=128,default=''"))).To(gomega.BeFalse())
+
+	t.Run("charset and collation", func(t *testing.T) {
+		col := Col("f_name").Type("", ",size=128,charset=utf8mb4,collate=utf8mb4_bin")
+
+		gomega.NewWithT(t).Expect(col.Equal(Col("f_name").Type("", ",size=128,charset=utf8mb4,collate=utf8mb4_bin"))).To(gomega.BeTrue())
+		gomega.NewWithT(t).Expect(col.Equal(Col("f_name").Type("", ",size=128,charset=latin1,collate=utf8mb4_bin"))).To(gomega.BeFalse())
+		gomega.NewWithT(t).Expect(col.Equal(Col("f_name").Type("", ",size=128,charset=utf8mb4,collate=utf8mb4_general_ci"))).To(gomega.BeFalse())
+	})
+
+	t.Run("data types of sqlite", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(Col("f_data").Type(textData(""), "").Equal(Col("f_data").Type(blobInSQLiteData(""), ""))).To(gomega.BeFalse())
+	})
+}
+
+type textData string
+
+func (textData) DataType(engine string) string {
+	return "text"
+}
+
+type blobInSQLiteData string
+
+func (blobInSQLiteData) DataType(engine string) string {
+	if engine == "sqlite" {
+		return "blob"
+	}
+	return "text"
+}
+
+func TestColumn_JSON(t *testing.T) {
+	col := Col("f_data")
+
+	t.Run("get", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(col.JSONGet("a", "b")).To(buidertestingutils.BeExpr("f_data -> ? -> ?", "a", "b"))
+		gomega.NewWithT(t).Expect(col.JSONGet()).To(buidertestingutils.BeExpr("f_data"))
+	})
+
+	t.Run("get text", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(col.JSONGetText("a", "b")).To(buidertestingutils.BeExpr("f_data -> ? ->> ?", "a", "b"))
+		gomega.NewWithT(t).Expect(
+			Where(AsCond(Expr("? = ?", col.JSONGetText("name"), "x"))),
+		).To(buidertestingutils.BeExpr("WHERE f_data ->> ? = ?", "name", "x"))
+	})
+
+	t.Run("contains", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(col.JSONContains(Expr("?::jsonb", `{"a":1}`))).To(buidertestingutils.BeExpr("f_data @> ?::jsonb", `{"a":1}`))
+		gomega.NewWithT(t).Expect(col.JSONContainedBy(Expr("?::jsonb", `{"a":1}`))).To(buidertestingutils.BeExpr("f_data <@ ?::jsonb", `{"a":1}`))
+	})
+}
@@ -20,6 +20,7 @@ type Columns struct {
 	columns       map[string]*list.Element
 	fields        map[string]*list.Element
 	autoIncrement *Column
+	version       *Column
 }
 
 func (cols *Columns) IsNil() bool {
@@ -39,10 +40,61 @@ func (cols *Columns) Ex(ctx context.Context) *Ex {
 	return e.Ex(ctx)
 }
 
+// ExprOn returns projection of columns qualified by table, like `s.t.f_id,s.t.f_name`,
+// columns are qualified by their own tables when t is nil.
+func (cols *Columns) ExprOn(t *Table) SqlExpr {
+	return cols.exprOn(t, false)
+}
+
+// AliasedExprOn returns projection like ExprOn with each column aliased as table_col, like `s.t.f_id AS t_f_id`,
+// for columns of same name from joined tables could be scanned apart.
+func (cols *Columns) AliasedExprOn(t *Table) SqlExpr {
+	return cols.exprOn(t, true)
+}
+
+func (cols *Columns) exprOn(t *Table, aliased bool) SqlExpr {
+	return ExprBy(func(ctx context.Context) *Ex {
+		e := Expr("")
+
+		cols.Range(func(col *Column, idx int) {
+			if idx > 0 {
+				e.WriteByte(',')
+			}
+
+			table := t
+			if table == nil {
+				table = col.Table
+			}
+
+			if table == nil {
+				e.WriteString(QuoteIdent(ctx, col.Name))
+				return
+			}
+
+			e.WriteExpr(table)
+			e.WriteByte('.')
+			e.WriteString(QuoteIdent(ctx, col.Name))
+
+			if aliased {
+				e.WriteString(" AS ")
+				e.WriteString(table.Name)
+				e.WriteByte('_')
+				e.WriteString(col.Name)
+			}
+		})
+
+		return e.Ex(ctx)
+	})
+}
+
 func (cols *Columns) AutoIncrement() (col *Column) {
 	return cols.autoIncrement
 }
 
+func (cols *Columns) Version() (col *Column) {
+	return cols.version
+}
+
 func (cols *Columns) Clone() *Columns {
 	c := &Columns{}
 	cols.Range(func(col *Column, idx int) {
@@ -81,14 +133,57 @@ func (cols *Columns) Fields(fieldNames ...string) (*Columns, error) {
 	return newCols, nil
 }
 
+// Pick returns new columns of the field names in declaration order, unknown fields are skipped
+func (cols *Columns) Pick(fieldNames ...string) *Columns {
+	picked := map[string]bool{}
+	for _, fieldName := range fieldNames {
+		picked[fieldName] = true
+	}
+
+	newCols := &Columns{}
+	cols.Range(func(col *Column, idx int) {
+		if picked[col.FieldName] {
+			newCols.Add(col)
+		}
+	})
+	return newCols
+}
+
+// Omit returns new columns except the field names in declaration order, unknown fields are skipped.
+// Unlike Remove, the columns self are not changed.
+func (cols *Columns) Omit(fieldNames ...string) *Columns {
+	omitted := map[string]bool{}
+	for _, fieldName := range fieldNames {
+		omitted[fieldName] = true
+	}
+
+	newCols := &Columns{}
+	cols.Range(func(col *Column, idx int) {
+		if !omitted[col.FieldName] {
+			newCols.Add(col)
+		}
+	})
+	return newCols
+}
+
+// FieldNames returns field names of columns in the order of Range, which lines up with ColNames
 func (cols *Columns) FieldNames() []string {
-	fieldNames := make([]string, 0)
+	fieldNames := make([]string, 0, cols.Len())
 	cols.Range(func(col *Column, idx int) {
 		fieldNames = append(fieldNames, col.FieldName)
 	})
 	return fieldNames
 }
 
+// ColNames returns names of columns in the order of Range, which lines up with FieldNames
+func (cols *Columns) ColNames() []string {
+	colNames := make([]string, 0, cols.Len())
+	cols.Range(func(col *Column, idx int) {
+		colNames = append(colNames, col.Name)
+	})
+	return colNames
+}
+
 func (cols *Columns) F(fileName string) (col *Column) {
 	if cols.fields != nil {
 		if c, ok := cols.fields[fileName]; ok {
@@ -132,7 +227,8 @@ func (cols *Columns) Col(columnName string) (col *Column) {
 	return nil
 }
 
-func (cols *Columns) Add(columns ...*Column) {
+// Add appends columns, nil columns are skipped, and returns cols for chaining
+func (cols *Columns) Add(columns ...*Column) *Columns {
 	if cols.columns == nil {
 		cols.columns = map[string]*list.Element{}
 		cols.fields = map[string]*list.Element{}
@@ -147,25 +243,75 @@ func (cols *Columns) Add(columns ...*Column) {
 				}
 				cols.autoIncrement = col
 			}
+			if col.ColumnType != nil && col.ColumnType.Version {
+				if cols.version != nil {
+					panic(fmt.Errorf("Version field can only have one, now %s, but %s want to replace", cols.version.Name, col.Name))
+				}
+				cols.version = col
+			}
 			e := cols.l.PushBack(col)
 			cols.columns[col.Name] = e
 			cols.fields[col.FieldName] = e
 		}
 	}
+	return cols
+}
+
+// Intersect returns columns of cols which are also in other, matched by field name, in order of cols
+func (cols *Columns) Intersect(other *Columns) *Columns {
+	newCols := &Columns{}
+	cols.Range(func(col *Column, idx int) {
+		if other.contains(col) {
+			newCols.Add(col)
+		}
+	})
+	return newCols
+}
+
+// Union returns columns of cols, followed by columns of other not in cols, matched by field name
+func (cols *Columns) Union(other *Columns) *Columns {
+	newCols := cols.Clone()
+	other.Range(func(col *Column, idx int) {
+		if !cols.contains(col) {
+			newCols.Add(col)
+		}
+	})
+	return newCols
+}
+
+// contains matches col by field name, or by column name when col has no field
+func (cols *Columns) contains(col *Column) bool {
+	if cols == nil {
+		return false
+	}
+	if col.FieldName == "" {
+		return cols.Col(col.Name) != nil
+	}
+	return cols.F(col.FieldName) != nil
 }
 
 func (cols *Columns) Remove(name string) {
 	name = strings.ToLower(name)
 	if cols.columns != nil {
 		if e, exists := cols.columns[name]; exists {
+			col := e.Value.(*Column)
 			cols.l.Remove(e)
 			delete(cols.columns, name)
+			if cols.fields[col.FieldName] == e {
+				delete(cols.fields, col.FieldName)
+			}
+			if cols.autoIncrement == col {
+				cols.autoIncrement = nil
+			}
+			if cols.version == col {
+				cols.version = nil
+			}
 		}
 	}
 }
 
 func (cols *Columns) Range(cb func(col *Column, idx int)) {
-	if cols.l != nil {
+	if cols != nil && cols.l != nil {
 		i := 0
 		for e := cols.l.Front(); e != nil; e = e.Next() {
 			cb(e.Value.(*Column), i)
@@ -12,6 +12,7 @@ func TestColumns(t *testing.T) {
 	t.Run("empty columns", func(t *testing.T) {
 		gomega.NewWithT(t).Expect(columns.Len()).To(gomega.Equal(0))
 		gomega.NewWithT(t).Expect(columns.AutoIncrement()).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(columns.Version()).To(gomega.BeNil())
 	})
 	t.Run("added cols", func(t *testing.T) {
 		columns.Add(
@@ -23,6 +24,25 @@ func TestColumns(t *testing.T) {
 		gomega.NewWithT(t).Expect(autoIncrementCol).NotTo(gomega.BeNil())
 		gomega.NewWithT(t).Expect(autoIncrementCol.Name).To(gomega.Equal("f_id"))
 
+		t.Run("get version col", func(t *testing.T) {
+			columns.Add(
+				Col("F_version").Field("Version").Type(uint64(1), `,version`),
+			)
+
+			gomega.NewWithT(t).Expect(columns.Version().Name).To(gomega.Equal("f_version"))
+			gomega.NewWithT(t).Expect(func() {
+				columns.Clone().Add(Col("F_version2").Field("Version2").Type(uint64(1), `,version`))
+			}).To(gomega.Panic())
+
+			columns.Remove("F_version")
+
+			gomega.NewWithT(t).Expect(columns.Version()).To(gomega.BeNil())
+			gomega.NewWithT(t).Expect(columns.F("Version")).To(gomega.BeNil())
+			gomega.NewWithT(t).Expect(func() {
+				columns.Clone().Add(Col("F_version2").Field("Version2").Type(uint64(1), `,version`))
+			}).NotTo(gomega.Panic())
+		})
+
 		t.Run("get col by FieldName", func(t *testing.T) {
 
 			gomega.NewWithT(t).Expect(columns.F("ID2")).To(gomega.BeNil())
@@ -43,6 +63,97 @@ func TestColumns(t *testing.T) {
 	})
 }
 
+func TestColumns_PickAndOmit(t *testing.T) {
+	columns := Columns{}
+	columns.Add(
+		Col("f_id").Field("ID").Type(1, `,autoincrement`),
+		Col("f_name").Field("Name").Type("", ``),
+		Col("f_data").Field("Data").Type([]byte(""), ``),
+	)
+
+	t.Run("pick", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(columns.Pick("Name", "ID", "Unknown").FieldNames()).To(gomega.Equal([]string{"ID", "Name"}))
+	})
+
+	t.Run("omit", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(columns.Omit("Data", "Unknown").FieldNames()).To(gomega.Equal([]string{"ID", "Name"}))
+		gomega.NewWithT(t).Expect(columns.Len()).To(gomega.Equal(3))
+	})
+}
+
+func TestColumns_Names(t *testing.T) {
+	columns := Columns{}
+	columns.Add(
+		Col("f_id").Field("ID").Type(1, `,autoincrement`),
+		Col("f_name").Field("Name").Type("", ``),
+		Col("f_data").Field("Data").Type([]byte(""), ``),
+	)
+
+	gomega.NewWithT(t).Expect(columns.FieldNames()).To(gomega.Equal([]string{"ID", "Name", "Data"}))
+	gomega.NewWithT(t).Expect(columns.ColNames()).To(gomega.Equal([]string{"f_id", "f_name", "f_data"}))
+
+	var nilColumns *Columns
+
+	gomega.NewWithT(t).Expect(nilColumns.FieldNames()).To(gomega.BeEmpty())
+	gomega.NewWithT(t).Expect(nilColumns.ColNames()).To(gomega.BeEmpty())
+}
+
+func TestColumns_AddChaining(t *testing.T) {
+	columns := &Columns{}
+
+	gomega.NewWithT(t).Expect(columns.Add(Col("f_id")).Add(nil, Col("f_name"))).To(gomega.BeIdenticalTo(columns))
+	gomega.NewWithT(t).Expect(columns.ColNames()).To(gomega.Equal([]string{"f_id", "f_name"}))
+
+	keys := &Keys{}
+
+	gomega.NewWithT(t).Expect(keys.Add(Index("i_id", nil)).Add(nil, Index("i_name", nil))).To(gomega.BeIdenticalTo(keys))
+	gomega.NewWithT(t).Expect(keys.Len()).To(gomega.Equal(2))
+}
+
 func MustCols(cols *Columns, err error) *Columns {
 	return cols
 }
+
+func TestColumns_IntersectAndUnion(t *testing.T) {
+	columns := (&Columns{}).Add(
+		Col("f_id").Field("ID"),
+		Col("f_name").Field("Name"),
+		Col("f_age").Field("Age"),
+	)
+	other := (&Columns{}).Add(
+		Col("f_user_name").Field("Name"),
+		Col("f_org_id").Field("OrgID"),
+		Col("f_id").Field("ID"),
+	)
+
+	gomega.NewWithT(t).Expect(columns.Intersect(other).ColNames()).To(gomega.Equal([]string{"f_id", "f_name"}))
+	gomega.NewWithT(t).Expect(columns.Union(other).ColNames()).To(gomega.Equal([]string{"f_id", "f_name", "f_age", "f_org_id"}))
+
+	gomega.NewWithT(t).Expect(columns.Intersect(nil).Len()).To(gomega.Equal(0))
+	gomega.NewWithT(t).Expect(columns.Union(nil).ColNames()).To(gomega.Equal([]string{"f_id", "f_name", "f_age"}))
+
+	t.Run("matched by column name without field", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(Cols("f_a", "f_b").Intersect(Cols("f_b", "f_c")).ColNames()).To(gomega.Equal([]string{"f_b"}))
+		gomega.NewWithT(t).Expect(Cols("f_a", "f_b").Union(Cols("f_b", "f_c")).ColNames()).To(gomega.Equal([]string{"f_a", "f_b", "f_c"}))
+	})
+}
+
+func TestColumns_ExprOn(t *testing.T) {
+	user := T("t_user", Col("f_id"), Col("f_name")).WithSchema("s")
+	org := T("t_org", Col("f_id"))
+
+	t.Run("qualified by table", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(ResolveExpr(user.Columns.ExprOn(user)).Query()).To(gomega.Equal("s.t_user.f_id,s.t_user.f_name"))
+	})
+
+	t.Run("qualified by own tables", func(t *testing.T) {
+		cols := &Columns{}
+		cols.Add(user.Col("f_name"), org.Col("f_id"))
+
+		gomega.NewWithT(t).Expect(ResolveExpr(cols.AliasedExprOn(nil)).Query()).To(gomega.Equal("s.t_user.f_name AS t_user_f_name,t_org.f_id AS t_org_f_id"))
+	})
+
+	t.Run("unqualified kept", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(ResolveExpr(&user.Columns).Query()).To(gomega.Equal("f_id,f_name"))
+	})
+}
@@ -0,0 +1,236 @@
+package builder
+
+import (
+	"container/list"
+	"strings"
+)
+
+// Check creates check constraint, expr should be boolean expression
+func Check(name string, expr SqlExpr) *Constraint {
+	return &Constraint{
+		Name: name,
+		Expr: expr,
+	}
+}
+
+var _ TableDefinition = (*Constraint)(nil)
+
+type Constraint struct {
+	Table *Table
+
+	Name string
+	Expr SqlExpr
+}
+
+func (c Constraint) On(table *Table) *Constraint {
+	c.Table = table
+	return &c
+}
+
+func (c *Constraint) T() *Table {
+	return c.Table
+}
+
+// Def returns expression with args interpolated, which is used in ddl and for comparing
+func (c *Constraint) Def() string {
+	if IsNilExpr(c.Expr) {
+		return ""
+	}
+	e := ResolveExpr(c.Expr)
+	return unwrapParentheses(interpolateHolders(e.Query(), e.Args()))
+}
+
+// normalizeCheckDef normalizes Def for comparing with definition loaded from database,
+// which is rewritten by database like ((f_age >= 0) AND ((f_name)::text <> 'a'::text)) of postgres,
+// or (`f_age` >= 0) of mysql.
+// Casts, quotes of identifiers, charset introducers of strings and parentheses for grouping are removed,
+// and words out of strings are lowercased,
+// the expression should be written in the form the database keeps, like = ANY (ARRAY[...]) instead of IN of postgres.
+func normalizeCheckDef(def string) string {
+	b := strings.Builder{}
+	// true for parentheses of function calls, which are kept
+	calls := make([]bool, 0)
+
+	lastByte := func() byte {
+		if b.Len() == 0 {
+			return 0
+		}
+		return b.String()[b.Len()-1]
+	}
+
+	writeSpace := func() {
+		switch lastByte() {
+		case 0, ' ', ',', '(':
+		default:
+			b.WriteByte(' ')
+		}
+	}
+
+	trimSpace := func() {
+		if lastByte() == ' ' {
+			str := b.String()
+			b.Reset()
+			b.WriteString(str[:len(str)-1])
+		}
+	}
+
+	for i := 0; i < len(def); i++ {
+		c := def[i]
+
+		switch {
+		case c == '\'':
+			end := i + 1
+			for ; end < len(def); end++ {
+				if def[end] == '\'' {
+					if end+1 < len(def) && def[end+1] == '\'' {
+						end++
+						continue
+					}
+					break
+				}
+			}
+			if end >= len(def) {
+				end = len(def) - 1
+			}
+			b.WriteString(def[i : end+1])
+			i = end
+		case c == '`' || c == '"':
+		case c == ':' && i+1 < len(def) && def[i+1] == ':':
+			i = skipCast(def, i+2) - 1
+		case c == '_' && !isIdentByte(lastByte()) && isCharsetIntroducer(def[i:]):
+			i += strings.IndexByte(def[i:], '\'') - 1
+		case c == '(':
+			call := isIdentByte(lastByte())
+			calls = append(calls, call)
+			if call {
+				b.WriteByte(c)
+			}
+		case c == ')':
+			call := false
+			if n := len(calls); n > 0 {
+				call = calls[n-1]
+				calls = calls[:n-1]
+			}
+			if call {
+				trimSpace()
+				b.WriteByte(c)
+			}
+		case c == ',':
+			trimSpace()
+			b.WriteByte(c)
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			writeSpace()
+		default:
+			if 'A' <= c && c <= 'Z' {
+				c += 'a' - 'A'
+			}
+			b.WriteByte(c)
+		}
+	}
+
+	return strings.TrimSpace(b.String())
+}
+
+// skipCast returns the index after type name of cast, like text, character varying, numeric(10,2) or text[]
+func skipCast(def string, i int) int {
+	word := func(i int) int {
+		for i < len(def) && (isIdentByte(def[i]) || def[i] == '"') {
+			i++
+		}
+		return i
+	}
+
+	i = word(i)
+
+	for _, suffix := range []string{" varying", " precision", " without time zone", " with time zone"} {
+		if strings.HasPrefix(def[i:], suffix) {
+			i += len(suffix)
+			break
+		}
+	}
+
+	if i < len(def) && def[i] == '(' {
+		if end := strings.IndexByte(def[i:], ')'); end > 0 {
+			i += end + 1
+		}
+	}
+
+	for strings.HasPrefix(def[i:], "[]") {
+		i += 2
+	}
+
+	return i
+}
+
+// isCharsetIntroducer returns true when s starts with charset introducer of string, like _utf8mb4'x' of mysql
+func isCharsetIntroducer(s string) bool {
+	end := strings.IndexByte(s, '\'')
+	if end < 2 {
+		return false
+	}
+	for i := 1; i < end; i++ {
+		if c := s[i]; !('a' <= c && c <= 'z' || '0' <= c && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+type Constraints struct {
+	m map[string]*list.Element
+	l *list.List
+}
+
+func (cs *Constraints) Len() int {
+	if cs.l == nil {
+		return 0
+	}
+	return cs.l.Len()
+}
+
+func (cs *Constraints) Constraint(name string) *Constraint {
+	if cs.m != nil {
+		if c, ok := cs.m[strings.ToLower(name)]; ok {
+			return c.Value.(*Constraint)
+		}
+	}
+	return nil
+}
+
+func (cs *Constraints) Add(nextConstraints ...*Constraint) {
+	if cs.m == nil {
+		cs.m = map[string]*list.Element{}
+		cs.l = list.New()
+	}
+	for _, c := range nextConstraints {
+		if c == nil {
+			continue
+		}
+		c.Name = strings.ToLower(c.Name)
+		cs.m[c.Name] = cs.l.PushBack(c)
+	}
+}
+
+func (cs *Constraints) Remove(name string) {
+	name = strings.ToLower(name)
+	if cs.m != nil {
+		if e, exists := cs.m[name]; exists {
+			cs.l.Remove(e)
+			delete(cs.m, name)
+		}
+	}
+}
+
+func (cs *Constraints) Range(cb func(c *Constraint, idx int)) {
+	if cs.l != nil {
+		i := 0
+		for e := cs.l.Front(); e != nil; e = e.Next() {
+			cb(e.Value.(*Constraint), i)
+			i++
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+func TestNormalizeCheckDef(t *testing.T) {
+	cases := map[string][2]string{
+		"postgres": {
+			"f_age >= 0 AND f_name <> '' AND lower(f_name) <> 'Admin'",
+			"((f_age >= 0) AND ((f_name)::text <> ''::text) AND (lower((f_name)::text) <> 'Admin'::text))",
+		},
+		"postgres cast with modifier": {
+			"f_score > 0.5 AND f_status = ANY (ARRAY['a', 'b'])",
+			"((f_score > (0.5)::numeric(10,2)) AND ((f_status)::text = ANY ((ARRAY['a'::character varying, 'b'::character varying])::text[])))",
+		},
+		"mysql": {
+			"f_age >= 0 AND f_status IN ('a', 'b_c')",
+			"((`f_age` >= 0) and (`f_status` in (_utf8mb4'a',_utf8mb4'b_c')))",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			gomega.NewWithT(t).Expect(normalizeCheckDef(c[1])).To(gomega.Equal(normalizeCheckDef(c[0])))
+		})
+	}
+
+	t.Run("strings kept", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(normalizeCheckDef("f_name <> 'A''s (x)::text'")).To(gomega.Equal("f_name <> 'A''s (x)::text'"))
+	})
+
+	t.Run("changed", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(normalizeCheckDef("(f_age >= 18)")).NotTo(gomega.Equal(normalizeCheckDef("f_age >= 0")))
+	})
+}
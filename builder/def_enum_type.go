@@ -0,0 +1,168 @@
+package builder
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+)
+
+// Enum declares enum type with ordered values, which is created as schema object before tables,
+// like `CREATE TYPE name AS ENUM (...)` of postgres.
+// Columns use it by data type named as the enum type, like DataTypeDescriber returns the name.
+func Enum(name string, values ...string) *EnumType {
+	return &EnumType{
+		Name:   name,
+		Values: values,
+	}
+}
+
+type EnumType struct {
+	Schema string
+	Name   string
+	Values []string
+}
+
+func (e EnumType) WithSchema(schema string) *EnumType {
+	e.Schema = schema
+	e.Values = copyStrings(e.Values)
+	return &e
+}
+
+func (e *EnumType) IsNil() bool {
+	return e == nil
+}
+
+// Ex renders name of enum type, qualified by Schema or default schema from context like Table
+func (e *EnumType) Ex(ctx context.Context) *Ex {
+	schema := e.Schema
+	if schema == "" {
+		schema = DefaultSchemaFromContext(ctx)
+	}
+	if schema != "" {
+		return Expr(QuoteIdent(ctx, schema) + "." + QuoteIdent(ctx, e.Name)).Ex(ctx)
+	}
+	return Expr(QuoteIdent(ctx, e.Name)).Ex(ctx)
+}
+
+// Diff returns statements to evolve enum type from prev.
+// Values could only be added, dropping or reordering values returns error, which postgres could not do.
+func (e *EnumType) Diff(prev *EnumType, dialect EnumTypeDialect) (exprList []SqlExpr, err error) {
+	if prev == nil {
+		return []SqlExpr{dialect.CreateEnumType(e)}, nil
+	}
+
+	indexes := map[string]int{}
+	for i, v := range e.Values {
+		indexes[v] = i
+	}
+
+	existed := map[string]bool{}
+	last := -1
+
+	for _, v := range prev.Values {
+		i, ok := indexes[v]
+		if !ok {
+			return nil, fmt.Errorf("value %s of enum type %s could not be dropped", v, e.Name)
+		}
+		if i < last {
+			return nil, fmt.Errorf("values of enum type %s could not be reordered", e.Name)
+		}
+		existed[v] = true
+		last = i
+	}
+
+	firstExisted := -1
+	for i, v := range e.Values {
+		if existed[v] {
+			firstExisted = i
+			break
+		}
+	}
+
+	if firstExisted == -1 {
+		for i, v := range e.Values {
+			if i == 0 {
+				exprList = append(exprList, dialect.AddEnumValue(e, v, "", ""))
+				continue
+			}
+			exprList = append(exprList, dialect.AddEnumValue(e, v, "", e.Values[i-1]))
+		}
+		return exprList, nil
+	}
+
+	// leading values are added before the next one reversely, for the anchor should exist
+	for i := firstExisted - 1; i >= 0; i-- {
+		exprList = append(exprList, dialect.AddEnumValue(e, e.Values[i], e.Values[i+1], ""))
+	}
+
+	for i := firstExisted + 1; i < len(e.Values); i++ {
+		if !existed[e.Values[i]] {
+			exprList = append(exprList, dialect.AddEnumValue(e, e.Values[i], "", e.Values[i-1]))
+		}
+	}
+
+	return exprList, nil
+}
+
+type EnumTypes struct {
+	l *list.List
+	m map[string]*list.Element
+}
+
+func (enumTypes *EnumTypes) Len() int {
+	if enumTypes.l == nil {
+		return 0
+	}
+	return enumTypes.l.Len()
+}
+
+func (enumTypes *EnumTypes) Names() (names []string) {
+	enumTypes.Range(func(e *EnumType, idx int) {
+		names = append(names, e.Name)
+	})
+	return
+}
+
+func (enumTypes *EnumTypes) EnumType(name string) *EnumType {
+	if enumTypes.m != nil {
+		if e, ok := enumTypes.m[name]; ok {
+			return e.Value.(*EnumType)
+		}
+	}
+	return nil
+}
+
+func (enumTypes *EnumTypes) Add(nextEnumTypes ...*EnumType) {
+	if enumTypes.m == nil {
+		enumTypes.m = map[string]*list.Element{}
+		enumTypes.l = list.New()
+	}
+	for _, e := range nextEnumTypes {
+		if e == nil {
+			continue
+		}
+		if _, ok := enumTypes.m[e.Name]; ok {
+			enumTypes.Remove(e.Name)
+		}
+		enumTypes.m[e.Name] = enumTypes.l.PushBack(e)
+	}
+}
+
+func (enumTypes *EnumTypes) Remove(name string) {
+	if enumTypes.m != nil {
+		if e, exists := enumTypes.m[name]; exists {
+			enumTypes.l.Remove(e)
+			delete(enumTypes.m, name)
+		}
+	}
+}
+
+func (enumTypes *EnumTypes) Range(cb func(e *EnumType, idx int)) {
+	if enumTypes.l != nil {
+		i := 0
+		for e := enumTypes.l.Front(); e != nil; e = e.Next() {
+			cb(e.Value.(*EnumType), i)
+			i++
+		}
+	}
+}
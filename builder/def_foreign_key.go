@@ -0,0 +1,147 @@
+package builder
+
+import (
+	"container/list"
+	"strings"
+)
+
+const (
+	ForeignKeyActionCascade    = "CASCADE"
+	ForeignKeyActionSetNull    = "SET NULL"
+	ForeignKeyActionSetDefault = "SET DEFAULT"
+	ForeignKeyActionRestrict   = "RESTRICT"
+	ForeignKeyActionNoAction   = "NO ACTION"
+)
+
+func FK(name string, columns *Columns) *ForeignKey {
+	return &ForeignKey{
+		Name:    name,
+		Columns: columns,
+	}
+}
+
+var _ TableDefinition = (*ForeignKey)(nil)
+
+type ForeignKey struct {
+	Columns *Columns
+	Table   *Table
+
+	Name string
+
+	RefTable   *Table
+	RefColumns *Columns
+
+	OnDelete string
+	OnUpdate string
+}
+
+func (fk ForeignKey) On(table *Table) *ForeignKey {
+	fk.Table = table
+	return &fk
+}
+
+func (fk ForeignKey) References(table *Table, columns *Columns) *ForeignKey {
+	fk.RefTable = table
+	fk.RefColumns = columns
+	return &fk
+}
+
+func (fk ForeignKey) OnDeleteAction(action string) *ForeignKey {
+	fk.OnDelete = strings.ToUpper(action)
+	return &fk
+}
+
+func (fk ForeignKey) OnUpdateAction(action string) *ForeignKey {
+	fk.OnUpdate = strings.ToUpper(action)
+	return &fk
+}
+
+func (fk *ForeignKey) T() *Table {
+	return fk.Table
+}
+
+// HasCol returns true when the column participates in the foreign key
+func (fk *ForeignKey) HasCol(colName string) bool {
+	return fk.Columns != nil && fk.Columns.Col(colName) != nil
+}
+
+// Def returns definition of foreign key for comparing.
+// Referenced table is compared by name, for its schema is resolved when rendering,
+// and NO ACTION is omitted as the default action, which is what databases report when not declared.
+func (fk *ForeignKey) Def() string {
+	b := strings.Builder{}
+
+	b.WriteString(ResolveExpr(fk.Columns).Query())
+	b.WriteString(" REFERENCES ")
+	if fk.RefTable != nil {
+		b.WriteString(fk.RefTable.Name)
+	}
+	b.WriteString(" ")
+	b.WriteString(ResolveExpr(fk.RefColumns).Query())
+
+	if fk.OnDelete != "" && fk.OnDelete != ForeignKeyActionNoAction {
+		b.WriteString(" ON DELETE ")
+		b.WriteString(fk.OnDelete)
+	}
+	if fk.OnUpdate != "" && fk.OnUpdate != ForeignKeyActionNoAction {
+		b.WriteString(" ON UPDATE ")
+		b.WriteString(fk.OnUpdate)
+	}
+
+	return b.String()
+}
+
+type ForeignKeys struct {
+	m map[string]*list.Element
+	l *list.List
+}
+
+func (fks *ForeignKeys) Len() int {
+	if fks.l == nil {
+		return 0
+	}
+	return fks.l.Len()
+}
+
+func (fks *ForeignKeys) ForeignKey(name string) *ForeignKey {
+	if fks.m != nil {
+		if c, ok := fks.m[strings.ToLower(name)]; ok {
+			return c.Value.(*ForeignKey)
+		}
+	}
+	return nil
+}
+
+func (fks *ForeignKeys) Add(nextForeignKeys ...*ForeignKey) {
+	if fks.m == nil {
+		fks.m = map[string]*list.Element{}
+		fks.l = list.New()
+	}
+	for _, fk := range nextForeignKeys {
+		if fk == nil {
+			continue
+		}
+		fk.Name = strings.ToLower(fk.Name)
+		fks.m[fk.Name] = fks.l.PushBack(fk)
+	}
+}
+
+func (fks *ForeignKeys) Remove(name string) {
+	name = strings.ToLower(name)
+	if fks.m != nil {
+		if e, exists := fks.m[name]; exists {
+			fks.l.Remove(e)
+			delete(fks.m, name)
+		}
+	}
+}
+
+func (fks *ForeignKeys) Range(cb func(fk *ForeignKey, idx int)) {
+	if fks.l != nil {
+		i := 0
+		for e := fks.l.Front(); e != nil; e = e.Next() {
+			cb(e.Value.(*ForeignKey), i)
+			i++
+		}
+	}
+}
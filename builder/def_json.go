@@ -0,0 +1,510 @@
+package builder
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// engines which data types of GetDataType are exported to json
+var dataTypeEngines = []string{"postgres", "mysql", "sqlite"}
+
+var goTypes = &goTypeRegistry{}
+
+// goTypeRegistry keeps registered go types by reflect.Type, types of same name from different packages not overwriting each other,
+// and indexes them by name which is exported to json.
+type goTypeRegistry struct {
+	rw     sync.RWMutex
+	types  map[reflect.Type]bool
+	byName map[string][]reflect.Type
+}
+
+func (r *goTypeRegistry) register(typ reflect.Type) {
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	if r.types == nil {
+		r.types = map[reflect.Type]bool{}
+		r.byName = map[string][]reflect.Type{}
+	}
+
+	if r.types[typ] {
+		return
+	}
+
+	r.types[typ] = true
+	r.byName[typ.String()] = append(r.byName[typ.String()], typ)
+}
+
+// lookup returns registered go types of name, more than one when types of different packages share the name
+func (r *goTypeRegistry) lookup(name string) []reflect.Type {
+	r.rw.RLock()
+	defer r.rw.RUnlock()
+
+	return r.byName[name]
+}
+
+func init() {
+	for _, v := range []interface{}{
+		false,
+		int(0), int8(0), int16(0), int32(0), int64(0),
+		uint(0), uint8(0), uint16(0), uint32(0), uint64(0),
+		float32(0), float64(0),
+		"", []byte(nil),
+		time.Time{},
+	} {
+		RegisterGoType(v)
+	}
+}
+
+// RegisterGoType registers go type of v, for resolving Column.Type when unmarshal from json.
+// Types which implement DataTypeDescriber are not required, their data types are exported.
+func RegisterGoType(v interface{}) {
+	goTypes.register(reflect.TypeOf(v))
+}
+
+type jsonTable struct {
+	Name          string            `json:"name"`
+	Schema        string            `json:"schema,omitempty"`
+	ModelName     string            `json:"modelName,omitempty"`
+	RenameFrom    string            `json:"renameFrom,omitempty"`
+	Versioned     bool              `json:"versioned,omitempty"`
+	StorageParams map[string]string `json:"storageParams,omitempty"`
+	Description   []string          `json:"description,omitempty"`
+	Columns       *Columns          `json:"columns"`
+	Keys          *Keys             `json:"keys"`
+	ForeignKeys   []*ForeignKey     `json:"foreignKeys,omitempty"`
+	Constraints   []*Constraint     `json:"constraints,omitempty"`
+}
+
+func (t *Table) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&jsonTable{
+		Name:          t.Name,
+		Schema:        t.Schema,
+		ModelName:     t.ModelName,
+		RenameFrom:    t.RenameFrom,
+		Versioned:     t.Versioned,
+		StorageParams: t.StorageParams,
+		Description:   t.Description,
+		Columns:       &t.Columns,
+		Keys:          &t.Keys,
+		ForeignKeys:   t.foreignKeyList(),
+		Constraints:   t.constraintList(),
+	})
+}
+
+func (t *Table) constraintList() (list []*Constraint) {
+	t.Constraints.Range(func(c *Constraint, idx int) {
+		list = append(list, c)
+	})
+	return
+}
+
+func (t *Table) foreignKeyList() (list []*ForeignKey) {
+	t.ForeignKeys.Range(func(fk *ForeignKey, idx int) {
+		list = append(list, fk)
+	})
+	return
+}
+
+func (t *Table) UnmarshalJSON(data []byte) error {
+	jt := &jsonTable{
+		Columns: &Columns{},
+		Keys:    &Keys{},
+	}
+	if err := json.Unmarshal(data, jt); err != nil {
+		return err
+	}
+
+	*t = Table{
+		Name:          jt.Name,
+		Schema:        jt.Schema,
+		ModelName:     jt.ModelName,
+		RenameFrom:    jt.RenameFrom,
+		Versioned:     jt.Versioned,
+		StorageParams: jt.StorageParams,
+		Description:   jt.Description,
+	}
+
+	jt.Columns.Range(func(col *Column, idx int) {
+		t.AddCol(col)
+	})
+
+	var err error
+
+	jt.Keys.Range(func(key *Key, idx int) {
+		if err != nil {
+			return
+		}
+		cols, e := t.Cols(key.Columns.ColNames()...)
+		if e != nil {
+			err = fmt.Errorf("key %s: %s", key.Name, e)
+			return
+		}
+		key.Columns = cols
+		t.AddKey(key)
+	})
+
+	for _, fk := range jt.ForeignKeys {
+		if err != nil {
+			break
+		}
+		cols, e := t.Cols(fk.Columns.ColNames()...)
+		if e != nil {
+			err = fmt.Errorf("foreign key %s: %s", fk.Name, e)
+			break
+		}
+		fk.Columns = cols
+		t.AddForeignKey(fk)
+	}
+
+	for _, c := range jt.Constraints {
+		t.AddConstraint(c)
+	}
+
+	return err
+}
+
+// UnmarshalTables unmarshal tables from snapshot marshaled by Tables.MarshalJSON,
+// which could be diffed with tables of models or loaded from database.
+func UnmarshalTables(data []byte) (*Tables, error) {
+	tables := &Tables{}
+	if err := json.Unmarshal(data, tables); err != nil {
+		return nil, err
+	}
+	return tables, nil
+}
+
+// MarshalJSON marshals tables as list in order of adding, columns in order of declaring,
+// so snapshot of same tables is always the same.
+func (tables *Tables) MarshalJSON() ([]byte, error) {
+	list := make([]*Table, 0)
+	tables.Range(func(tab *Table, idx int) {
+		list = append(list, tab)
+	})
+	return json.Marshal(list)
+}
+
+// UnmarshalJSON unmarshal tables, referenced tables of foreign keys are bound to the unmarshaled ones.
+func (tables *Tables) UnmarshalJSON(data []byte) error {
+	list := make([]*Table, 0)
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+
+	*tables = Tables{}
+	tables.Add(list...)
+
+	tables.Range(func(tab *Table, idx int) {
+		tab.ForeignKeys.Range(func(fk *ForeignKey, idx int) {
+			if fk.RefTable == nil || fk.RefTable.Schema != tab.Schema {
+				return
+			}
+			if refTable := tables.Table(fk.RefTable.Name); refTable != nil {
+				if cols, err := refTable.Cols(fk.RefColumns.ColNames()...); err == nil {
+					fk.RefTable = refTable
+					fk.RefColumns = cols
+				}
+			}
+		})
+	})
+
+	return nil
+}
+
+type jsonColumn struct {
+	Name          string            `json:"name"`
+	FieldName     string            `json:"fieldName,omitempty"`
+	Description   []string          `json:"description,omitempty"`
+	Relation      []string          `json:"relation,omitempty"`
+	Type          string            `json:"type,omitempty"`
+	DataTypes     map[string]string `json:"dataTypes,omitempty"`
+	Length        uint64            `json:"length,omitempty"`
+	Decimal       uint64            `json:"decimal,omitempty"`
+	Default       *string           `json:"default,omitempty"`
+	OnUpdate      *string           `json:"onUpdate,omitempty"`
+	Backfill      *string           `json:"backfill,omitempty"`
+	Null          bool              `json:"null,omitempty"`
+	AutoIncrement bool              `json:"autoIncrement,omitempty"`
+	Version       bool              `json:"version,omitempty"`
+	Comment       string            `json:"comment,omitempty"`
+	Charset       string            `json:"charset,omitempty"`
+	Collation     string            `json:"collation,omitempty"`
+	After         string            `json:"after,omitempty"`
+	Generated     *struct {
+		Expr    string `json:"expr"`
+		Storage string `json:"storage,omitempty"`
+	} `json:"generated,omitempty"`
+	Deprecated *struct {
+		RenameTo string `json:"renameTo,omitempty"`
+	} `json:"deprecated,omitempty"`
+}
+
+func (c *Column) MarshalJSON() ([]byte, error) {
+	jc := &jsonColumn{
+		Name:        c.Name,
+		FieldName:   c.FieldName,
+		Description: c.Description,
+		Relation:    c.Relation,
+	}
+
+	if ct := c.ColumnType; ct != nil {
+		if ct.Type != nil {
+			jc.Type = ct.Type.String()
+		}
+		if ct.GetDataType != nil {
+			jc.DataTypes = map[string]string{}
+			for _, engine := range dataTypeEngines {
+				jc.DataTypes[engine] = ct.GetDataType(engine)
+			}
+		}
+
+		jc.Length = ct.Length
+		jc.Decimal = ct.Decimal
+		jc.Default = ct.Default
+		jc.OnUpdate = ct.OnUpdate
+		jc.Backfill = ct.Backfill
+		jc.Null = ct.Null
+		jc.AutoIncrement = ct.AutoIncrement
+		jc.Version = ct.Version
+		jc.Comment = ct.Comment
+		jc.Charset = ct.Charset
+		jc.Collation = ct.Collation
+		jc.After = ct.After
+
+		if ct.IsGenerated() {
+			jc.Generated = &struct {
+				Expr    string `json:"expr"`
+				Storage string `json:"storage,omitempty"`
+			}{
+				Expr:    ct.GeneratedExpr,
+				Storage: ct.GeneratedStorage,
+			}
+		}
+
+		if ct.DeprecatedActions != nil {
+			jc.Deprecated = &struct {
+				RenameTo string `json:"renameTo,omitempty"`
+			}{
+				RenameTo: ct.DeprecatedActions.RenameTo,
+			}
+		}
+	}
+
+	return json.Marshal(jc)
+}
+
+func (c *Column) UnmarshalJSON(data []byte) error {
+	jc := &jsonColumn{}
+	if err := json.Unmarshal(data, jc); err != nil {
+		return err
+	}
+
+	ct := &ColumnType{
+		Length:        jc.Length,
+		Decimal:       jc.Decimal,
+		Default:       jc.Default,
+		OnUpdate:      jc.OnUpdate,
+		Backfill:      jc.Backfill,
+		Null:          jc.Null,
+		AutoIncrement: jc.AutoIncrement,
+		Version:       jc.Version,
+		Comment:       jc.Comment,
+		Charset:       jc.Charset,
+		Collation:     jc.Collation,
+		After:         jc.After,
+	}
+
+	if jc.Generated != nil {
+		ct.GeneratedExpr = jc.Generated.Expr
+		ct.GeneratedStorage = jc.Generated.Storage
+	}
+
+	if jc.Deprecated != nil {
+		ct.DeprecatedActions = &DeprecatedActions{RenameTo: jc.Deprecated.RenameTo}
+	}
+
+	if jc.DataTypes != nil {
+		dataTypes := jc.DataTypes
+		ct.GetDataType = func(engine string) string {
+			return dataTypes[engine]
+		}
+	}
+
+	if jc.Type != "" {
+		switch types := goTypes.lookup(jc.Type); len(types) {
+		case 0:
+			if ct.GetDataType == nil {
+				return fmt.Errorf("unknown go type %s of column %s, should be registered by RegisterGoType", jc.Type, jc.Name)
+			}
+		case 1:
+			ct.Type = types[0]
+		default:
+			return fmt.Errorf("ambiguous go type %s of column %s, registered from %d packages", jc.Type, jc.Name, len(types))
+		}
+	}
+
+	*c = Column{
+		Name:        jc.Name,
+		FieldName:   jc.FieldName,
+		Description: jc.Description,
+		Relation:    jc.Relation,
+		ColumnType:  ct,
+	}
+
+	return nil
+}
+
+func (cols *Columns) MarshalJSON() ([]byte, error) {
+	list := cols.List()
+	if list == nil {
+		list = make([]*Column, 0)
+	}
+	return json.Marshal(list)
+}
+
+func (cols *Columns) UnmarshalJSON(data []byte) error {
+	list := make([]*Column, 0)
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*cols = Columns{}
+	cols.Add(list...)
+	return nil
+}
+
+type jsonKey struct {
+	Name          string                    `json:"name"`
+	IsUnique      bool                      `json:"isUnique,omitempty"`
+	Method        string                    `json:"method,omitempty"`
+	Columns       []string                  `json:"columns"`
+	Exprs         []string                  `json:"exprs,omitempty"`
+	Where         string                    `json:"where,omitempty"`
+	RenameFrom    string                    `json:"renameFrom,omitempty"`
+	StorageParams map[string]string         `json:"storageParams,omitempty"`
+	Orders        map[string]KeyColumnOrder `json:"orders,omitempty"`
+}
+
+func (key *Key) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&jsonKey{
+		Name:          key.Name,
+		IsUnique:      key.IsUnique,
+		Method:        key.Method,
+		Columns:       key.Columns.ColNames(),
+		Exprs:         key.Exprs,
+		Where:         key.Where,
+		RenameFrom:    key.RenameFrom,
+		StorageParams: key.StorageParams,
+		Orders:        key.Orders,
+	})
+}
+
+// UnmarshalJSON unmarshal key with columns only named,
+// which should be bound to columns of table like Table.UnmarshalJSON does.
+func (key *Key) UnmarshalJSON(data []byte) error {
+	jk := &jsonKey{}
+	if err := json.Unmarshal(data, jk); err != nil {
+		return err
+	}
+	*key = Key{
+		Name:          jk.Name,
+		IsUnique:      jk.IsUnique,
+		Method:        jk.Method,
+		Columns:       Cols(jk.Columns...),
+		Exprs:         jk.Exprs,
+		Where:         jk.Where,
+		RenameFrom:    jk.RenameFrom,
+		StorageParams: jk.StorageParams,
+		Orders:        jk.Orders,
+	}
+	return nil
+}
+
+func (keys *Keys) MarshalJSON() ([]byte, error) {
+	list := make([]*Key, 0, keys.Len())
+	keys.Range(func(key *Key, idx int) {
+		list = append(list, key)
+	})
+	return json.Marshal(list)
+}
+
+func (keys *Keys) UnmarshalJSON(data []byte) error {
+	list := make([]*Key, 0)
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*keys = Keys{}
+	keys.Add(list...)
+	return nil
+}
+
+type jsonForeignKey struct {
+	Name       string   `json:"name"`
+	Columns    []string `json:"columns"`
+	RefSchema  string   `json:"refSchema,omitempty"`
+	RefTable   string   `json:"refTable"`
+	RefColumns []string `json:"refColumns"`
+	OnDelete   string   `json:"onDelete,omitempty"`
+	OnUpdate   string   `json:"onUpdate,omitempty"`
+}
+
+func (fk *ForeignKey) MarshalJSON() ([]byte, error) {
+	jfk := &jsonForeignKey{
+		Name:       fk.Name,
+		Columns:    fk.Columns.ColNames(),
+		RefColumns: fk.RefColumns.ColNames(),
+		OnDelete:   fk.OnDelete,
+		OnUpdate:   fk.OnUpdate,
+	}
+	if fk.RefTable != nil {
+		jfk.RefSchema = fk.RefTable.Schema
+		jfk.RefTable = fk.RefTable.Name
+	}
+	return json.Marshal(jfk)
+}
+
+// UnmarshalJSON unmarshal foreign key with columns and referenced table only named.
+func (fk *ForeignKey) UnmarshalJSON(data []byte) error {
+	jfk := &jsonForeignKey{}
+	if err := json.Unmarshal(data, jfk); err != nil {
+		return err
+	}
+	refTable := T(jfk.RefTable)
+	refTable.Schema = jfk.RefSchema
+
+	*fk = ForeignKey{
+		Name:       jfk.Name,
+		Columns:    Cols(jfk.Columns...),
+		RefTable:   refTable,
+		RefColumns: Cols(jfk.RefColumns...),
+		OnDelete:   jfk.OnDelete,
+		OnUpdate:   jfk.OnUpdate,
+	}
+	return nil
+}
+
+type jsonConstraint struct {
+	Name string `json:"name"`
+	Expr string `json:"expr"`
+}
+
+func (c *Constraint) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&jsonConstraint{
+		Name: c.Name,
+		Expr: c.Def(),
+	})
+}
+
+func (c *Constraint) UnmarshalJSON(data []byte) error {
+	jc := &jsonConstraint{}
+	if err := json.Unmarshal(data, jc); err != nil {
+		return err
+	}
+	*c = Constraint{
+		Name: jc.Name,
+		Expr: Expr(jc.Expr),
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package builder_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	. "github.com/go-courier/sqlx/v2/builder"
+	"github.com/onsi/gomega"
+)
+
+// diffDialect renders data types only, which is enough for diffing tables without changes
+type diffDialect struct {
+	Dialect
+}
+
+func (diffDialect) PrimaryKeyName() string {
+	return "pkey"
+}
+
+func (diffDialect) DataType(columnType *ColumnType) SqlExpr {
+	dataType := ""
+	if columnType.GetDataType != nil {
+		dataType = columnType.GetDataType("postgres")
+	} else {
+		dataType = columnType.Type.String()
+	}
+
+	e := Expr(fmt.Sprintf("%s(%d) null=%v autoincrement=%v", dataType, columnType.Length, columnType.Null, columnType.AutoIncrement))
+	if columnType.Default != nil {
+		e.WriteString(" default=" + *columnType.Default)
+	}
+	return e
+}
+
+func TestTableJSON(t *testing.T) {
+	RegisterGoType(Point{})
+
+	table := T("t",
+		Col("F_id").Field("ID").Type(uint64(0), ",autoincrement"),
+		Col("f_name").Field("Name").Type("", ",size=128,default=''"),
+		Col("F_geo").Field("Geo").Type(&Point{}, ",null"),
+		Col("F_created_at").Field("CreatedAt").Type(int64(0), ",default='0'"),
+		UniqueIndex("I_name", Cols("F_name")).Using("BTREE"),
+		Index("I_geo", Cols("F_geo")).Using("SPATIAL"),
+	)
+
+	data, err := json.Marshal(table)
+	gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+
+	unmarshaled := &Table{}
+	gomega.NewWithT(t).Expect(json.Unmarshal(data, unmarshaled)).To(gomega.BeNil())
+
+	gomega.NewWithT(t).Expect(unmarshaled.FieldNames()).To(gomega.Equal([]string{"ID", "Name", "Geo", "CreatedAt"}))
+	gomega.NewWithT(t).Expect(unmarshaled.Key("i_name").Columns.Col("f_name").Table).To(gomega.Equal(unmarshaled))
+
+	gomega.NewWithT(t).Expect(table.Diff(unmarshaled, diffDialect{})).To(gomega.HaveLen(0))
+	gomega.NewWithT(t).Expect(unmarshaled.Diff(table, diffDialect{})).To(gomega.HaveLen(0))
+
+	remarshaled, err := json.Marshal(unmarshaled)
+	gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+	gomega.NewWithT(t).Expect(string(remarshaled)).To(gomega.Equal(string(data)))
+}
+
+func TestTablesJSON(t *testing.T) {
+	tOrg := T("t_org",
+		Col("f_id").Field("ID").Type(uint64(0), ",autoincrement"),
+		Col("f_name").Field("Name").Type("", ",size=128,default=''"),
+		PrimaryKey(Cols("f_id")),
+	)
+	tOrg.Description = []string{"org"}
+
+	tUser := T("t_user",
+		Col("f_id").Field("ID").Type(uint64(0), ",autoincrement"),
+		Col("f_org_id").Field("OrgID").Type(uint64(0), ""),
+		Col("f_nickname").Field("Nickname").Type("", ",size=64,null"),
+		PrimaryKey(Cols("f_id")),
+		Index("i_org", Cols("f_org_id")),
+	)
+	tUser.AddForeignKey(FK("fk_org", Cols("f_org_id")).References(tOrg, Cols("f_id")))
+
+	tables := &Tables{}
+	tables.Add(tUser, tOrg)
+
+	data, err := json.Marshal(tables)
+	gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+
+	unmarshaled, err := UnmarshalTables(data)
+	gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+
+	gomega.NewWithT(t).Expect(unmarshaled.TableNames()).To(gomega.Equal([]string{"t_user", "t_org"}))
+	gomega.NewWithT(t).Expect(unmarshaled.Table("t_org").Description).To(gomega.Equal([]string{"org"}))
+	gomega.NewWithT(t).Expect(unmarshaled.Table("t_user").Col("f_nickname").Null).To(gomega.BeTrue())
+	gomega.NewWithT(t).Expect(unmarshaled.Table("t_user").ForeignKey("fk_org").RefTable).To(gomega.Equal(unmarshaled.Table("t_org")))
+
+	gomega.NewWithT(t).Expect(tables.Diff(unmarshaled, diffDialect{}, true)).To(gomega.HaveLen(0))
+
+	for i := 0; i < 5; i++ {
+		remarshaled, err := json.Marshal(unmarshaled)
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(string(remarshaled)).To(gomega.Equal(string(data)))
+	}
+
+	_, err = UnmarshalTables([]byte(`[{"name":"t","columns":[],"keys":[{"name":"i","columns":["f_missing"]}]}]`))
+	gomega.NewWithT(t).Expect(err).NotTo(gomega.BeNil())
+}
+
+type Score float64
+
+func TestRegisterGoType(t *testing.T) {
+	t.Run("unknown", func(t *testing.T) {
+		col := &Column{}
+		err := json.Unmarshal([]byte(`{"name":"f_score","type":"builder_test.Score"}`), col)
+		gomega.NewWithT(t).Expect(err).To(gomega.MatchError("unknown go type builder_test.Score of column f_score, should be registered by RegisterGoType"))
+	})
+
+	t.Run("registered concurrently", func(t *testing.T) {
+		wg := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				RegisterGoType(Score(0))
+				_ = json.Unmarshal([]byte(`{"name":"f_score","type":"builder_test.Score"}`), &Column{})
+			}()
+		}
+		wg.Wait()
+
+		col := &Column{}
+		gomega.NewWithT(t).Expect(json.Unmarshal([]byte(`{"name":"f_score","type":"builder_test.Score"}`), col)).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(col.ColumnType.Type.String()).To(gomega.Equal("builder_test.Score"))
+	})
+}
@@ -24,6 +24,36 @@ func UniqueIndex(name string, columns *Columns) *Key {
 	}
 }
 
+// PartialIndex creates index only covers rows matched the predicate where
+func PartialIndex(name string, columns *Columns, where string) *Key {
+	return &Key{
+		Name:    name,
+		Columns: columns,
+		Where:   where,
+	}
+}
+
+// PartialUniqueIndex creates unique index only covers rows matched the predicate where,
+// like `CREATE UNIQUE INDEX ... WHERE deleted_at IS NULL`
+func PartialUniqueIndex(name string, columns *Columns, where string) *Key {
+	return &Key{
+		Name:     name,
+		IsUnique: true,
+		Columns:  columns,
+		Where:    where,
+	}
+}
+
+// ExprIndex creates index of expressions, like `lower(f_email)`,
+// columns could be indexed alongside by WithExprs on index of columns
+func ExprIndex(name string, exprs ...string) *Key {
+	return &Key{
+		Name:    name,
+		Columns: &Columns{},
+		Exprs:   exprs,
+	}
+}
+
 var _ TableDefinition = (*Key)(nil)
 
 type Key struct {
@@ -33,6 +63,68 @@ type Key struct {
 	Name     string
 	IsUnique bool
 	Method   string
+	// Exprs are expressions indexed after columns, like `lower(f_email)`
+	Exprs []string
+	// Where is predicate of partial index
+	Where string
+	// RenameFrom is the old name of index, for renaming index instead of rebuilding when definition not changed
+	RenameFrom string
+	// StorageParams are storage parameters of index, like fillfactor of postgres
+	StorageParams map[string]string
+	// Orders are sort orders of columns by column name, columns not in are ascending
+	Orders map[string]KeyColumnOrder
+}
+
+const (
+	NullsFirst = "FIRST"
+	NullsLast  = "LAST"
+)
+
+// KeyColumnOrder is sort order of column in index, like `DESC NULLS LAST`
+type KeyColumnOrder struct {
+	Desc bool `json:"desc,omitempty"`
+	// Nulls is NullsFirst or NullsLast, empty means default of the database
+	Nulls string `json:"nulls,omitempty"`
+}
+
+// ParseKeyColumnOrder parses order like `DESC NULLS LAST` of column in index
+func ParseKeyColumnOrder(s string) KeyColumnOrder {
+	order := KeyColumnOrder{}
+	words := strings.Fields(strings.ToUpper(s))
+	for i, word := range words {
+		switch word {
+		case "DESC":
+			order.Desc = true
+		case "NULLS":
+			if i+1 < len(words) {
+				order.Nulls = words[i+1]
+			}
+		}
+	}
+	return order
+}
+
+func (order KeyColumnOrder) String() string {
+	s := ""
+	if order.Desc {
+		s = "DESC"
+	}
+	if order.Nulls != "" {
+		if s != "" {
+			s += " "
+		}
+		s += "NULLS " + order.Nulls
+	}
+	return s
+}
+
+// normalized drops Nulls same as the default of the standard, which is LAST of ascending and FIRST of descending,
+// so orders declared explicitly equal to the ones loaded from database
+func (order KeyColumnOrder) normalized() KeyColumnOrder {
+	if (!order.Desc && order.Nulls == NullsLast) || (order.Desc && order.Nulls == NullsFirst) {
+		order.Nulls = ""
+	}
+	return order
 }
 
 func (key Key) On(table *Table) *Key {
@@ -45,6 +137,130 @@ func (key Key) Using(method string) *Key {
 	return &key
 }
 
+// WithOrder sets sort order of column colName in index, like `CREATE INDEX ... (f_a DESC NULLS LAST)`
+func (key Key) WithOrder(colName string, order KeyColumnOrder) *Key {
+	orders := make(map[string]KeyColumnOrder, len(key.Orders)+1)
+	for name, o := range key.Orders {
+		orders[name] = o
+	}
+	orders[strings.ToLower(colName)] = order
+	key.Orders = orders
+	return &key
+}
+
+// HasOrders returns true when some columns of the key are not in default ascending order
+func (key *Key) HasOrders() bool {
+	for _, order := range key.Orders {
+		if order != (KeyColumnOrder{}) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasNullsOrders returns true when nulls ordering of some columns declared, which is not supported by all dialects
+func (key *Key) HasNullsOrders() bool {
+	for _, order := range key.Orders {
+		if order.Nulls != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (key Key) WithExprs(exprs ...string) *Key {
+	key.Exprs = append(copyStrings(key.Exprs), exprs...)
+	return &key
+}
+
+// HasExprs returns true when the key indexes expressions
+func (key *Key) HasExprs() bool {
+	return len(key.Exprs) > 0
+}
+
+// Members returns columns with their orders and expressions indexed, separated by comma
+func (key *Key) Members() SqlExpr {
+	return key.members(false)
+}
+
+func (key *Key) members(normalized bool) SqlExpr {
+	if !key.HasExprs() && !key.HasOrders() {
+		return key.Columns
+	}
+
+	e := Expr("")
+
+	if key.HasOrders() {
+		key.Columns.Range(func(col *Column, idx int) {
+			if idx > 0 {
+				e.WriteByte(',')
+			}
+			e.WriteExpr(col)
+
+			order := key.Orders[col.Name]
+			if normalized {
+				order = order.normalized()
+			}
+			if s := order.String(); s != "" {
+				e.WriteByte(' ')
+				e.WriteString(s)
+			}
+		})
+	} else {
+		e.WriteExpr(key.Columns)
+	}
+
+	for i, expr := range key.Exprs {
+		if i > 0 || !key.Columns.IsNil() {
+			e.WriteByte(',')
+		}
+		e.WriteString(expr)
+	}
+
+	return e
+}
+
+// IsPartial returns true when the key is partial index
+func (key *Key) IsPartial() bool {
+	return key.Where != ""
+}
+
+// Def returns definition of key for comparing, nulls orders same as the default are omitted
+func (key *Key) Def() string {
+	def := ResolveExpr(key.members(true)).Query()
+	if key.IsPartial() {
+		def += " WHERE " + unwrapParentheses(key.Where)
+	}
+	return def
+}
+
+// unwrapParentheses trims outer parentheses and spaces,
+// for expressions like predicates of partial index are wrapped with parentheses when loaded from database
+func unwrapParentheses(expr string) string {
+	expr = strings.TrimSpace(expr)
+	for strings.HasPrefix(expr, "(") && strings.HasSuffix(expr, ")") && isWrapped(expr) {
+		expr = strings.TrimSpace(expr[1 : len(expr)-1])
+	}
+	return expr
+}
+
+// isWrapped returns true when the first parenthesis matches the last one
+func isWrapped(s string) bool {
+	depth := 0
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i == len(s)-1
+			}
+		}
+	}
+	return false
+}
+
 func (key *Key) T() *Table {
 	return key.Table
 }
@@ -86,7 +302,18 @@ func (keys *Keys) Key(keyName string) (key *Key) {
 	return nil
 }
 
-func (keys *Keys) Add(nextKeys ...*Key) {
+// PrimaryKey returns the primary key, nil when not declared
+func (keys *Keys) PrimaryKey() (key *Key) {
+	keys.Range(func(k *Key, idx int) {
+		if key == nil && k.IsPrimary() {
+			key = k
+		}
+	})
+	return
+}
+
+// Add appends keys, nil keys are skipped, and returns keys for chaining
+func (keys *Keys) Add(nextKeys ...*Key) *Keys {
 	if keys.m == nil {
 		keys.m = map[string]*list.Element{}
 		keys.l = list.New()
@@ -98,6 +325,7 @@ func (keys *Keys) Add(nextKeys ...*Key) {
 		key.Name = strings.ToLower(key.Name)
 		keys.m[key.Name] = keys.l.PushBack(key)
 	}
+	return keys
 }
 
 func (keys *Keys) Remove(name string) {
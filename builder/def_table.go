@@ -29,6 +29,10 @@ func T(tableName string, tableDefinitions ...TableDefinition) *Table {
 		switch d := tableDef.(type) {
 		case *Key:
 			t.AddKey(d)
+		case *ForeignKey:
+			t.AddForeignKey(d)
+		case *Constraint:
+			t.AddConstraint(d)
 		}
 	}
 	return t
@@ -41,21 +45,45 @@ type Table struct {
 	Schema    string
 	ModelName string
 	Model     Model
+	// RenameFrom is the old name of table,
+	// when the table not exists, the old one will be renamed and diffed
+	RenameFrom string
+	// Versioned marks the table system-versioned by triggers,
+	// whose history table is created and altered in lockstep, see HistoryTable
+	Versioned bool
+	// StorageParams are storage parameters of table, like fillfactor of postgres,
+	// which are diffed by dialects implemented StorageParamsDialect, and ignored by others
+	StorageParams map[string]string
+	// PartitionBy declares the table partitioned, rendered by dialects implemented PartitionDialect
+	PartitionBy *PartitionBy
+	// PartitionOf declares the table a partition, see Partition
+	PartitionOf *PartitionOf
 
 	Columns
 	Keys
+
+	ForeignKeys ForeignKeys
+	Constraints Constraints
 }
 
 func (t *Table) TableName() string {
 	return t.Name
 }
 
+// CommentText returns lines of description joined, which is stored as comment of table in database
+func (t *Table) CommentText() string {
+	return strings.Join(t.Description, "\n")
+}
+
 func (t *Table) IsNil() bool {
 	return t == nil || len(t.Name) == 0
 }
 
+// WithSchema returns copy of table with schema, description and definitions are copied,
+// but Model is shared, which is used as the prototype of rows only.
 func (t Table) WithSchema(schema string) *Table {
 	t.Schema = schema
+	t.Description = copyStrings(t.Description)
 
 	cols := Columns{}
 	t.Columns.Range(func(col *Column, idx int) {
@@ -69,14 +97,211 @@ func (t Table) WithSchema(schema string) *Table {
 	})
 	t.Keys = keys
 
+	fks := ForeignKeys{}
+	t.ForeignKeys.Range(func(fk *ForeignKey, idx int) {
+		fks.Add(fk.On(&t))
+	})
+	t.ForeignKeys = fks
+
+	constraints := Constraints{}
+	t.Constraints.Range(func(c *Constraint, idx int) {
+		constraints.Add(c.On(&t))
+	})
+	t.Constraints = constraints
+
 	return &t
 }
 
+// Clone returns deep copy of the table,
+// columns, keys and foreign keys are copied and bound to the clone,
+// so they could be changed without affecting the source table.
+func (t *Table) Clone() *Table {
+	table := *t
+	table.Description = copyStrings(t.Description)
+	table.StorageParams = copyStorageParams(t.StorageParams)
+
+	table.Columns = Columns{}
+	t.Columns.Range(func(col *Column, idx int) {
+		c := col.On(&table)
+		if col.ColumnType != nil {
+			ct := *col.ColumnType
+			c.ColumnType = &ct
+		}
+		table.Columns.Add(c)
+	})
+
+	table.Keys = Keys{}
+	t.Keys.Range(func(key *Key, idx int) {
+		k := key.On(&table)
+		k.Columns = table.rebindCols(key.Columns)
+		table.Keys.Add(k)
+	})
+
+	table.ForeignKeys = ForeignKeys{}
+	t.ForeignKeys.Range(func(fk *ForeignKey, idx int) {
+		f := fk.On(&table)
+		f.Columns = table.rebindCols(fk.Columns)
+		table.ForeignKeys.Add(f)
+	})
+
+	table.Constraints = Constraints{}
+	t.Constraints.Range(func(c *Constraint, idx int) {
+		table.Constraints.Add(c.On(&table))
+	})
+
+	if t.PartitionBy != nil {
+		table.PartitionBy = &PartitionBy{Strategy: t.PartitionBy.Strategy, Columns: table.rebindCols(t.PartitionBy.Columns)}
+	}
+
+	return &table
+}
+
+// WithoutForeignKeys returns Clone of the table without foreign keys,
+// for creating tables in foreign key cycle before adding foreign keys.
+func (t *Table) WithoutForeignKeys() *Table {
+	table := t.Clone()
+	table.ForeignKeys = ForeignKeys{}
+	return table
+}
+
+// CopyStructure returns copy of the table named newName, like Clone, for creating staging or backup tables,
+// RenameFrom, ModelName, Versioned and PartitionOf are dropped, for the copy is a standalone table,
+// and keys are dropped unless withKeys, for index names should be unique in schema on some dialects.
+func (t *Table) CopyStructure(newName string, withKeys bool) *Table {
+	table := t.Clone()
+	table.Name = newName
+	table.RenameFrom = ""
+	table.ModelName = ""
+	table.Versioned = false
+	table.PartitionOf = nil
+
+	if !withKeys {
+		table.Keys = Keys{}
+	}
+
+	return table
+}
+
+func copyStrings(list []string) []string {
+	if list == nil {
+		return nil
+	}
+	return append(make([]string, 0, len(list)), list...)
+}
+
+func copyStorageParams(params map[string]string) map[string]string {
+	if params == nil {
+		return nil
+	}
+	m := make(map[string]string, len(params))
+	for k, v := range params {
+		m[k] = v
+	}
+	return m
+}
+
+// rebindCols returns columns of the table with same names, unknown columns are kept
+func (t *Table) rebindCols(cols *Columns) *Columns {
+	if cols == nil {
+		return nil
+	}
+	newCols := &Columns{}
+	cols.Range(func(col *Column, idx int) {
+		if c := t.Col(col.Name); c != nil {
+			newCols.Add(c)
+		} else {
+			newCols.Add(col)
+		}
+	})
+	return newCols
+}
+
+// CheckReservedWords returns error when name of table, its columns or indexes collides with reserved words of the dialect,
+// which will break the unquoted sql. Primary keys are skipped, for named by the dialect.
+func (t *Table) CheckReservedWords(dialect Dialect) error {
+	names := make([]string, 0)
+
+	if dialect.IsReservedWord(t.Name) {
+		names = append(names, t.Name)
+	}
+
+	t.Columns.Range(func(col *Column, idx int) {
+		if col.DeprecatedActions == nil && dialect.IsReservedWord(col.Name) {
+			names = append(names, col.Name)
+		}
+	})
+
+	t.Keys.Range(func(key *Key, idx int) {
+		if !key.IsPrimary() && dialect.IsReservedWord(key.Name) {
+			names = append(names, key.Name)
+		}
+	})
+
+	if len(names) > 0 {
+		return fmt.Errorf("table %s uses reserved words of %s: %s", t.Name, dialect.DriverName(), strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// Validate returns error listing all mistakes of table definition, for failing fast before diffing,
+// like duplicated columns, keys or foreign keys of undeclared columns, primary key of nullable columns,
+// and deprecated columns renamed to undeclared columns.
+func (t *Table) Validate() error {
+	problems := make([]string, 0)
+
+	declared := map[string]bool{}
+
+	t.Columns.Range(func(col *Column, idx int) {
+		if declared[col.Name] {
+			problems = append(problems, fmt.Sprintf("column %s is duplicated", col.Name))
+		}
+		declared[col.Name] = true
+	})
+
+	t.Columns.Range(func(col *Column, idx int) {
+		if col.DeprecatedActions != nil && col.DeprecatedActions.RenameTo != "" {
+			if renameTo := strings.ToLower(col.DeprecatedActions.RenameTo); !declared[renameTo] {
+				problems = append(problems, fmt.Sprintf("column %s is renamed to undeclared column %s", col.Name, renameTo))
+			}
+		}
+	})
+
+	t.Keys.Range(func(key *Key, idx int) {
+		key.Columns.Range(func(col *Column, idx int) {
+			c := t.Col(col.Name)
+			if c == nil {
+				problems = append(problems, fmt.Sprintf("key %s uses undeclared column %s", key.Name, col.Name))
+				return
+			}
+			if key.IsPrimary() && c.ColumnType != nil && c.Null {
+				problems = append(problems, fmt.Sprintf("primary key %s uses nullable column %s", key.Name, col.Name))
+			}
+		})
+	})
+
+	t.ForeignKeys.Range(func(fk *ForeignKey, idx int) {
+		fk.Columns.Range(func(col *Column, idx int) {
+			if t.Col(col.Name) == nil {
+				problems = append(problems, fmt.Sprintf("foreign key %s uses undeclared column %s", fk.Name, col.Name))
+			}
+		})
+	})
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid table %s: %s", t.Name, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
 func (t *Table) Ex(ctx context.Context) *Ex {
-	if t.Schema != "" {
-		return Expr(t.Schema + "." + t.Name).Ex(ctx)
+	schema := t.Schema
+	if schema == "" {
+		schema = DefaultSchemaFromContext(ctx)
+	}
+	if schema != "" {
+		return Expr(QuoteIdent(ctx, schema) + "." + QuoteIdent(ctx, t.Name)).Ex(ctx)
 	}
-	return Expr(t.Name).Ex(ctx)
+	return Expr(QuoteIdent(ctx, t.Name)).Ex(ctx)
 }
 
 func (t *Table) AddCol(d *Column) {
@@ -86,19 +311,82 @@ func (t *Table) AddCol(d *Column) {
 	t.Columns.Add(d.On(t))
 }
 
+func (t *Table) AddCols(cols ...*Column) {
+	for _, col := range cols {
+		t.AddCol(col)
+	}
+}
+
+// AddKey adds key to table, panics when adding another primary key, for a table has only one primary key.
+// Key of the same name is replaced.
 func (t *Table) AddKey(key *Key) {
 	if key == nil {
 		return
 	}
+	if key.IsPrimary() {
+		if pk := t.PrimaryKey(); pk != nil && pk.Name != strings.ToLower(key.Name) {
+			panic(fmt.Errorf("duplicated primary key %s of table %s, %s declared", key.Name, t.Name, pk.Name))
+		}
+	}
 	t.Keys.Add(key.On(t))
 }
 
+func (t *Table) AddKeys(keys ...*Key) {
+	for _, key := range keys {
+		t.AddKey(key)
+	}
+}
+
+func (t *Table) PrimaryKey() *Key {
+	return t.Keys.PrimaryKey()
+}
+
+func (t *Table) AddForeignKey(fk *ForeignKey) {
+	if fk == nil {
+		return
+	}
+	t.ForeignKeys.Add(fk.On(t))
+}
+
+func (t *Table) ForeignKey(name string) *ForeignKey {
+	return t.ForeignKeys.ForeignKey(name)
+}
+
+func (t *Table) AddConstraint(c *Constraint) {
+	if c == nil {
+		return
+	}
+	t.Constraints.Add(c.On(t))
+}
+
+func (t *Table) Constraint(name string) *Constraint {
+	return t.Constraints.Constraint(name)
+}
+
 func (t *Table) Expr(query string, args ...interface{}) *Ex {
+	e, missing := t.expr(query, args...)
+	if len(missing) > 0 {
+		panic(fmt.Errorf("missing field fieldName %s of table %s", missing[0], t.Name))
+	}
+	return e
+}
+
+// ExprStrict is like Expr, but returns error with all #Field placeholders not resolved by F instead of panic,
+// for checking field names of queries before executing.
+func (t *Table) ExprStrict(query string, args ...interface{}) (*Ex, error) {
+	e, missing := t.expr(query, args...)
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing fields %s of table %s", strings.Join(missing, ", "), t.Name)
+	}
+	return e, nil
+}
+
+func (t *Table) expr(query string, args ...interface{}) (e *Ex, missing []string) {
 	if query == "" {
-		return nil
+		return nil, nil
 	}
 
-	e := Expr("")
+	e = Expr("")
 
 	s := &scanner.Scanner{}
 	s.Init(bytes.NewBuffer([]byte(query)))
@@ -109,40 +397,35 @@ func (t *Table) Expr(query string, args ...interface{}) *Ex {
 	for tok := s.Next(); tok != scanner.EOF; tok = s.Next() {
 		switch tok {
 		case '#':
-			fieldNameBuf := bytes.NewBuffer(nil)
-
-			e.WriteHolder(0)
-
-			for {
-				tok = s.Next()
-
-				if tok == scanner.EOF {
-					break
-				}
+			// ## escapes literal #
+			if s.Peek() == '#' {
+				e.WriteRune(s.Next())
+				continue
+			}
 
-				if (tok >= 'A' && tok <= 'Z') ||
-					(tok >= 'a' && tok <= 'z') ||
-					(tok >= '0' && tok <= '9') ||
-					tok == '_' {
+			// jsonb operators #> #>> #- of postgres are kept as they are
+			if next := s.Peek(); next == '>' || next == '-' {
+				e.WriteRune(tok)
+				continue
+			}
 
-					fieldNameBuf.WriteRune(tok)
-					continue
-				}
+			fieldNameBuf := bytes.NewBuffer(nil)
 
-				e.WriteRune(tok)
+			e.WriteHolder(0)
 
-				break
+			// peek to keep the next token, which may be another placeholder like #A#B
+			for isFieldNameRune(s.Peek()) {
+				fieldNameBuf.WriteRune(s.Next())
 			}
 
-			//spew.Dump(fieldNameBuf.String())
-
 			if fieldNameBuf.Len() == 0 {
 				e.AppendArgs(t)
 			} else {
 				fieldName := fieldNameBuf.String()
-				col := t.F(fieldNameBuf.String())
+				col := t.F(fieldName)
 				if col == nil {
-					panic(fmt.Errorf("missing field fieldName %s of table %s", fieldName, t.Name))
+					missing = append(missing, fieldName)
+					continue
 				}
 				e.AppendArgs(col)
 			}
@@ -157,21 +440,23 @@ func (t *Table) Expr(query string, args ...interface{}) *Ex {
 		}
 	}
 
-	return e
+	return e, missing
 }
 
-func (t *Table) ColumnsAndValuesByFieldValues(fieldValues FieldValues) (columns *Columns, args []interface{}) {
-	fieldNames := make([]string, 0)
-	for fieldName := range fieldValues {
-		fieldNames = append(fieldNames, fieldName)
-	}
-
-	sort.Strings(fieldNames)
+func isFieldNameRune(r rune) bool {
+	return (r >= 'A' && r <= 'Z') ||
+		(r >= 'a' && r <= 'z') ||
+		(r >= '0' && r <= '9') ||
+		r == '_'
+}
 
+// ColumnsAndValuesByFieldValues returns columns and values of fields in order of SortedFieldNames,
+// so the same FieldValues always renders the same sql.
+func (t *Table) ColumnsAndValuesByFieldValues(fieldValues FieldValues) (columns *Columns, args []interface{}) {
 	columns = &Columns{}
 
-	for _, fieldName := range fieldNames {
-		if col := t.F(fieldName); col != nil {
+	for _, fieldName := range SortedFieldNames(fieldValues) {
+		if col := t.F(fieldName); col != nil && !col.IsGenerated() {
 			columns.Add(col)
 			args = append(args, fieldValues[fieldName])
 		}
@@ -179,17 +464,323 @@ func (t *Table) ColumnsAndValuesByFieldValues(fieldValues FieldValues) (columns
 	return
 }
 
+// AssignmentsByFieldValues returns assignments of fields in order of SortedFieldNames,
+// so the same FieldValues always renders the same sql.
 func (t *Table) AssignmentsByFieldValues(fieldValues FieldValues) (assignments Assignments) {
-	for fieldName, value := range fieldValues {
+	// the later field wins when fields map to same column
+	indexes := map[string]int{}
+
+	for _, fieldName := range SortedFieldNames(fieldValues) {
 		col := t.F(fieldName)
-		if col != nil {
-			assignments = append(assignments, col.ValueBy(value))
+		if col == nil || col.IsGenerated() {
+			continue
 		}
+		if i, ok := indexes[col.Name]; ok {
+			assignments[i] = col.ValueBy(fieldValues[fieldName])
+			continue
+		}
+		indexes[col.Name] = len(assignments)
+		assignments = append(assignments, col.ValueBy(fieldValues[fieldName]))
 	}
 	return
 }
 
 func (t *Table) Diff(prevTable *Table, dialect Dialect) (exprList []SqlExpr) {
+	for _, action := range t.DiffActions(prevTable, dialect) {
+		exprList = append(exprList, action.Expr)
+	}
+	return
+}
+
+// DiffOptions tunes DiffWith
+type DiffOptions struct {
+	// IgnoreColumns are names of columns managed outside, like tsvector column maintained by triggers,
+	// which are never created, altered or dropped
+	IgnoreColumns []string
+	// IgnoreIndexes are names of indexes managed outside, which are never created, altered, renamed or dropped
+	IgnoreIndexes []string
+}
+
+// without returns copy of table without ignored columns and indexes, nil table is kept
+func (opts DiffOptions) without(t *Table) *Table {
+	if t.IsNil() || (len(opts.IgnoreColumns) == 0 && len(opts.IgnoreIndexes) == 0) {
+		return t
+	}
+
+	table := t.Clone()
+	for _, name := range opts.IgnoreColumns {
+		table.Columns.Remove(name)
+	}
+	for _, name := range opts.IgnoreIndexes {
+		table.Keys.Remove(strings.ToLower(name))
+	}
+	return table
+}
+
+// DiffWith diffs like Diff, but columns and indexes ignored by opts are skipped on both sides
+func (t *Table) DiffWith(prevTable *Table, dialect Dialect, opts DiffOptions) (exprList []SqlExpr) {
+	for _, action := range t.DiffActionsWith(prevTable, dialect, opts) {
+		exprList = append(exprList, action.Expr)
+	}
+	return
+}
+
+// DiffActionsWith diffs like DiffActions, but columns and indexes ignored by opts are skipped on both sides
+func (t *Table) DiffActionsWith(prevTable *Table, dialect Dialect, opts DiffOptions) []DiffAction {
+	return opts.without(t).DiffActions(opts.without(prevTable), dialect)
+}
+
+const (
+	DiffActionCreateTable         = "create_table"
+	DiffActionRenameTable         = "rename_table"
+	DiffActionDropTable           = "drop_table"
+	DiffActionModifyTableComment  = "modify_table_comment"
+	DiffActionAddColumn           = "add_column"
+	DiffActionDropColumn          = "drop_column"
+	DiffActionRenameColumn        = "rename_column"
+	DiffActionModifyColumn        = "modify_column"
+	DiffActionBackfillColumn      = "backfill_column"
+	DiffActionModifyColumnComment = "modify_column_comment"
+	DiffActionSetColumnDefault    = "set_column_default"
+	DiffActionDropColumnDefault   = "drop_column_default"
+	DiffActionAddIndex            = "add_index"
+	DiffActionDropIndex           = "drop_index"
+	DiffActionRenameIndex         = "rename_index"
+	DiffActionDropPrimaryKey      = "drop_primary_key"
+	DiffActionAddPrimaryKey       = "add_primary_key"
+	DiffActionAddForeignKey       = "add_foreign_key"
+	DiffActionDropForeignKey      = "drop_foreign_key"
+	DiffActionAddConstraint       = "add_constraint"
+	DiffActionDropConstraint      = "drop_constraint"
+	// DiffActionSetTableStorageParams and DiffActionSetIndexStorageParams set or reset changed storage parameters only
+	DiffActionSetTableStorageParams = "set_table_storage_params"
+	DiffActionSetIndexStorageParams = "set_index_storage_params"
+	// DiffActionRebuildTable rebuilds the table to apply all changes of definition, see RebuildDialect
+	DiffActionRebuildTable = "rebuild_table"
+)
+
+// Risk tells how modifying column is applied by the database
+type Risk string
+
+const (
+	// RiskSafe changes metadata only, or is done in place without copying rows
+	RiskSafe Risk = "safe"
+	// RiskTableRewrite rewrites the whole table under lock, which should be scheduled in maintenance window
+	RiskTableRewrite Risk = "table_rewrite"
+)
+
+// DiffAction is one change of table diff, which keeps the intent for reviewing
+type DiffAction struct {
+	// Kind is one of DiffAction*
+	Kind string
+	// Target is name of the table, column, index, foreign key or constraint changed
+	Target string
+	// Risk of DiffActionModifyColumn, empty for other kinds
+	Risk Risk
+	// RequiresBackfill is true when DiffActionModifyColumn changes the column to NOT NULL without Backfill,
+	// which fails when NULLs existed
+	RequiresBackfill bool
+	Expr             SqlExpr
+	// destructive is true when DiffActionRebuildTable merged destructive changes or loses columns
+	destructive bool
+}
+
+// IsDestructive returns true when the change drops table, column, index, primary key, foreign key or constraint,
+// which may lose data or its integrity, or rebuilds the table with some of them dropped
+func (action DiffAction) IsDestructive() bool {
+	switch action.Kind {
+	case DiffActionDropTable, DiffActionDropColumn, DiffActionDropIndex, DiffActionDropPrimaryKey,
+		DiffActionDropForeignKey, DiffActionDropConstraint:
+		return true
+	}
+	return action.destructive
+}
+
+// HasDestructiveChanges returns true when some of actions is destructive, for requiring approval before migrating
+func HasDestructiveChanges(actions []DiffAction) bool {
+	for _, action := range actions {
+		if action.IsDestructive() {
+			return true
+		}
+	}
+	return false
+}
+
+// backfill replaces NULLs of column by its Backfill, before the column is changed to NOT NULL
+func backfill(col *Column) SqlExpr {
+	e := Expr("UPDATE ")
+	e.WriteExpr(col.Table)
+	e.WriteString(" SET ")
+	e.WriteExpr(col)
+	e.WriteString(" = ")
+	e.WriteString(*col.Backfill)
+	e.WriteString(" WHERE ")
+	e.WriteExpr(col)
+	e.WriteString(" IS NULL")
+	e.WriteEnd()
+	return e
+}
+
+// DiffStorageParams returns parameters to set which are added or changed, and names to reset which are removed
+func DiffStorageParams(params map[string]string, prev map[string]string) (set map[string]string, reset []string) {
+	for name, value := range params {
+		if prevValue, ok := prev[name]; !ok || prevValue != value {
+			if set == nil {
+				set = map[string]string{}
+			}
+			set[name] = value
+		}
+	}
+
+	for name := range prev {
+		if _, ok := params[name]; !ok {
+			reset = append(reset, name)
+		}
+	}
+
+	sort.Strings(reset)
+	return
+}
+
+// StorageParamNames returns sorted names of params, for rendering in stable order
+func StorageParamNames(params map[string]string) []string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func diffTableStorageParams(dialect Dialect, t *Table, prev *Table) (actions []DiffAction) {
+	d, ok := dialect.(StorageParamsDialect)
+	if !ok {
+		return nil
+	}
+	if set, reset := DiffStorageParams(t.StorageParams, prev.StorageParams); len(set) > 0 || len(reset) > 0 {
+		actions = append(actions, DiffAction{Kind: DiffActionSetTableStorageParams, Target: t.Name, Expr: d.SetTableStorageParams(t, set, reset)})
+	}
+	return
+}
+
+func diffIndexStorageParams(dialect Dialect, key *Key, prev *Key) (actions []DiffAction) {
+	d, ok := dialect.(StorageParamsDialect)
+	if !ok {
+		return nil
+	}
+	if set, reset := DiffStorageParams(key.StorageParams, prev.StorageParams); len(set) > 0 || len(reset) > 0 {
+		actions = append(actions, DiffAction{Kind: DiffActionSetIndexStorageParams, Target: key.Name, Expr: d.SetIndexStorageParams(key, set, reset)})
+	}
+	return
+}
+
+func modifyColumnRisk(dialect Dialect, col *Column, prev *Column) Risk {
+	if d, ok := dialect.(ColumnRiskDialect); ok {
+		return d.ModifyColumnRisk(col, prev)
+	}
+	return RiskTableRewrite
+}
+
+// DiffActions diffs like Diff, but returns changes with their kinds and targets
+func (t *Table) DiffActions(prevTable *Table, dialect Dialect) []DiffAction {
+	actions := t.diffActions(prevTable, dialect)
+	if d, ok := dialect.(RebuildDialect); ok && !prevTable.IsNil() {
+		return mergeRebuilds(d, t, prevTable, actions)
+	}
+	return actions
+}
+
+// mergeRebuilds replaces changes of definition by one rebuild of table from prevTable when some of them rebuild the table,
+// for each rebuild assumes the database matches the table except its own change, which is not true when chained.
+// Renaming columns, backfilling and comments are kept, and the rebuild follows them.
+func mergeRebuilds(dialect RebuildDialect, t *Table, prevTable *Table, actions []DiffAction) []DiffAction {
+	rebuild := false
+	for _, action := range actions {
+		if dialect.RequiresRebuild(action) {
+			rebuild = true
+			break
+		}
+	}
+	if !rebuild {
+		return actions
+	}
+
+	merged := make([]DiffAction, 0, len(actions))
+	destructive := false
+	for _, action := range actions {
+		switch action.Kind {
+		case DiffActionRenameColumn, DiffActionBackfillColumn, DiffActionModifyColumnComment, DiffActionModifyTableComment:
+			merged = append(merged, action)
+			continue
+		}
+		// the rebuild drops what the replaced changes drop
+		if action.IsDestructive() {
+			destructive = true
+		}
+	}
+
+	// columns undeclared are not copied to the rebuilt table
+	prevTable.Columns.Range(func(prevCol *Column, idx int) {
+		if prevCol.DeprecatedActions == nil && !prevCol.IsGenerated() && t.Col(prevCol.Name) == nil {
+			destructive = true
+		}
+	})
+
+	return append(merged, DiffAction{Kind: DiffActionRebuildTable, Target: t.Name, Expr: dialect.RebuildTable(t, prevTable), destructive: destructive})
+}
+
+func (t *Table) diffActions(prevTable *Table, dialect Dialect) (actions []DiffAction) {
+	if prevTable.IsNil() {
+		return []DiffAction{{Kind: DiffActionCreateTable, Target: t.Name, Expr: dialect.CreateTable(t)}}
+	}
+
+	// columns and indexes of partition are altered by the partitioned table
+	if t.IsPartition() {
+		return nil
+	}
+
+	// foreign keys should be dropped before columns they used dropped
+	fkToAdd := make([]*ForeignKey, 0)
+
+	t.ForeignKeys.Range(func(fk *ForeignKey, idx int) {
+		prevFk := prevTable.ForeignKey(fk.Name)
+		if prevFk == nil {
+			fkToAdd = append(fkToAdd, fk)
+			return
+		}
+		if fk.Def() != prevFk.Def() || t.dropsColOf(prevFk) {
+			actions = append(actions, DiffAction{Kind: DiffActionDropForeignKey, Target: prevFk.Name, Expr: dialect.DropForeignKey(prevFk)})
+			fkToAdd = append(fkToAdd, fk)
+		}
+	})
+
+	prevTable.ForeignKeys.Range(func(prevFk *ForeignKey, idx int) {
+		if t.ForeignKey(prevFk.Name) == nil {
+			actions = append(actions, DiffAction{Kind: DiffActionDropForeignKey, Target: prevFk.Name, Expr: dialect.DropForeignKey(prevFk)})
+		}
+	})
+
+	// constraints are dropped before columns changed, and added after
+	constraintsToAdd := make([]*Constraint, 0)
+
+	t.Constraints.Range(func(c *Constraint, idx int) {
+		prevConstraint := prevTable.Constraint(c.Name)
+		if prevConstraint == nil {
+			constraintsToAdd = append(constraintsToAdd, c)
+			return
+		}
+		if normalizeCheckDef(c.Def()) != normalizeCheckDef(prevConstraint.Def()) {
+			actions = append(actions, DiffAction{Kind: DiffActionDropConstraint, Target: prevConstraint.Name, Expr: dialect.DropConstraint(prevConstraint)})
+			constraintsToAdd = append(constraintsToAdd, c)
+		}
+	})
+
+	prevTable.Constraints.Range(func(prevConstraint *Constraint, idx int) {
+		if t.Constraint(prevConstraint.Name) == nil {
+			actions = append(actions, DiffAction{Kind: DiffActionDropConstraint, Target: prevConstraint.Name, Expr: dialect.DropConstraint(prevConstraint)})
+		}
+	})
+
 	// diff columns
 	t.Columns.Range(func(currentCol *Column, idx int) {
 		if prevCol := prevTable.Col(currentCol.Name); prevCol != nil {
@@ -199,37 +790,79 @@ func (t *Table) Diff(prevTable *Table, dialect Dialect) (exprList []SqlExpr) {
 					if renameTo != "" {
 						prevCol := prevTable.Col(renameTo)
 						if prevCol != nil {
-							exprList = append(exprList, dialect.DropColumn(prevCol))
+							actions = append(actions, DiffAction{Kind: DiffActionDropColumn, Target: prevCol.Name, Expr: dialect.DropColumn(prevCol)})
 						}
 						targetCol := t.Col(renameTo)
 						if targetCol == nil {
 							panic(fmt.Errorf("col `%s` is not declared", renameTo))
 						}
-						exprList = append(exprList, dialect.RenameColumn(currentCol, targetCol))
+						actions = append(actions, DiffAction{Kind: DiffActionRenameColumn, Target: currentCol.Name, Expr: dialect.RenameColumn(currentCol, targetCol)})
 						prevTable.AddCol(targetCol)
 						return
 					}
-					exprList = append(exprList, dialect.DropColumn(currentCol))
+					actions = append(actions, DiffAction{Kind: DiffActionDropColumn, Target: currentCol.Name, Expr: dialect.DropColumn(currentCol)})
+					return
+				}
+
+				// most engines could not alter expression of generated column
+				if currentCol.GeneratedDef() != prevCol.GeneratedDef() {
+					actions = append(actions, DiffAction{Kind: DiffActionDropColumn, Target: currentCol.Name, Expr: dialect.DropColumn(currentCol)})
+					actions = append(actions, DiffAction{Kind: DiffActionAddColumn, Target: currentCol.Name, Expr: dialect.AddColumn(currentCol)})
+
+					if currentCol.CommentText() != "" {
+						actions = append(actions, DiffAction{Kind: DiffActionModifyColumnComment, Target: currentCol.Name, Expr: dialect.ModifyColumnComment(currentCol)})
+					}
 					return
 				}
 
-				prevColType := dialect.DataType(prevCol.ColumnType).Ex(context.Background()).Query()
+				prevColumnType := withCollationDeclared(prevCol.ColumnType, currentCol.ColumnType)
+
+				prevColType := dialect.DataType(prevColumnType).Ex(context.Background()).Query()
 				currentColType := dialect.DataType(currentCol.ColumnType).Ex(context.Background()).Query()
 
 				if currentColType != prevColType {
-					exprList = append(exprList, dialect.ModifyColumn(currentCol, prevCol))
+					// only default changed, which is altered without rewriting the table
+					if dialect.DataType(currentCol.ColumnType.WithoutDefault()).Ex(context.Background()).Query() ==
+						dialect.DataType(prevColumnType.WithoutDefault()).Ex(context.Background()).Query() {
+						if currentCol.Default == nil {
+							actions = append(actions, DiffAction{Kind: DiffActionDropColumnDefault, Target: currentCol.Name, Expr: dialect.DropColumnDefault(currentCol)})
+						} else {
+							actions = append(actions, DiffAction{Kind: DiffActionSetColumnDefault, Target: currentCol.Name, Expr: dialect.SetColumnDefault(currentCol)})
+						}
+					} else {
+						requiresBackfill := prevCol.Null && !currentCol.Null
+						if requiresBackfill && currentCol.Backfill != nil {
+							actions = append(actions, DiffAction{Kind: DiffActionBackfillColumn, Target: currentCol.Name, Expr: backfill(currentCol)})
+							requiresBackfill = false
+						}
+						actions = append(actions, DiffAction{Kind: DiffActionModifyColumn, Target: currentCol.Name, Risk: modifyColumnRisk(dialect, currentCol, prevCol), RequiresBackfill: requiresBackfill, Expr: dialect.ModifyColumn(currentCol, prevCol)})
+					}
+				}
+
+				if currentCol.CommentText() != prevCol.CommentText() {
+					actions = append(actions, DiffAction{Kind: DiffActionModifyColumnComment, Target: currentCol.Name, Expr: dialect.ModifyColumnComment(currentCol)})
 				}
 				return
 			}
-			exprList = append(exprList, dialect.DropColumn(currentCol))
+			actions = append(actions, DiffAction{Kind: DiffActionDropColumn, Target: currentCol.Name, Expr: dialect.DropColumn(currentCol)})
 			return
 		}
 
 		if currentCol.DeprecatedActions == nil {
-			exprList = append(exprList, dialect.AddColumn(currentCol))
+			actions = append(actions, DiffAction{Kind: DiffActionAddColumn, Target: currentCol.Name, Expr: dialect.AddColumn(currentCol)})
+
+			if currentCol.CommentText() != "" {
+				actions = append(actions, DiffAction{Kind: DiffActionModifyColumnComment, Target: currentCol.Name, Expr: dialect.ModifyColumnComment(currentCol)})
+			}
 		}
 	})
 
+	if t.CommentText() != prevTable.CommentText() {
+		actions = append(actions, DiffAction{Kind: DiffActionModifyTableComment, Target: t.Name, Expr: dialect.ModifyTableComment(t)})
+	}
+
+	actions = append(actions, diffTableStorageParams(dialect, t, prevTable)...)
+
 	// indexes
 	indexes := map[string]bool{}
 
@@ -241,22 +874,70 @@ func (t *Table) Diff(prevTable *Table, dialect Dialect) (exprList []SqlExpr) {
 		indexes[name] = true
 
 		prevKey := prevTable.Key(name)
+		if key.IsPrimary() {
+			// primary key may be named differently, like PRIMARY of model and pkey loaded from postgres
+			if prevKey = prevTable.PrimaryKey(); prevKey != nil {
+				indexes[prevKey.Name] = true
+			}
+		}
 		if prevKey == nil {
-			exprList = append(exprList, dialect.AddIndex(key))
-		} else {
-			if !key.IsPrimary() && ResolveExpr(key.Columns).Query() != ResolveExpr(prevKey.Columns).Query() {
-				exprList = append(exprList, dialect.DropIndex(key))
-				exprList = append(exprList, dialect.AddIndex(key))
+			if renamedFrom := prevTable.Key(key.RenameFrom); renamedFrom != nil && !key.IsPrimary() &&
+				renamedFrom.IsUnique == key.IsUnique && renamedFrom.Def() == key.Def() {
+				indexes[renamedFrom.Name] = true
+				actions = append(actions, DiffAction{Kind: DiffActionRenameIndex, Target: key.Name, Expr: dialect.RenameIndex(renamedFrom, key)})
+				actions = append(actions, diffIndexStorageParams(dialect, key, renamedFrom)...)
+				return
+			}
+			actions = append(actions, DiffAction{Kind: DiffActionAddIndex, Target: key.Name, Expr: dialect.AddIndex(key)})
+		} else if key.IsUnique != prevKey.IsUnique || key.Def() != prevKey.Def() {
+			// primary key of changed columns is rebuilt by dropping the prev one
+			if key.IsPrimary() {
+				actions = append(actions, DiffAction{Kind: DiffActionDropPrimaryKey, Target: key.Name, Expr: dialect.DropPrimaryKey(prevKey)})
+				actions = append(actions, DiffAction{Kind: DiffActionAddPrimaryKey, Target: key.Name, Expr: dialect.AddPrimaryKey(key)})
+				return
 			}
+			actions = append(actions, DiffAction{Kind: DiffActionDropIndex, Target: key.Name, Expr: dialect.DropIndex(key)})
+			actions = append(actions, DiffAction{Kind: DiffActionAddIndex, Target: key.Name, Expr: dialect.AddIndex(key)})
+		} else {
+			actions = append(actions, diffIndexStorageParams(dialect, key, prevKey)...)
 		}
 	})
 
 	prevTable.Keys.Range(func(key *Key, idx int) {
 		if _, ok := indexes[strings.ToLower(key.Name)]; !ok {
-			exprList = append(exprList, dialect.DropIndex(key))
+			actions = append(actions, DiffAction{Kind: DiffActionDropIndex, Target: key.Name, Expr: dialect.DropIndex(key)})
 		}
 	})
 
+	for _, c := range constraintsToAdd {
+		actions = append(actions, DiffAction{Kind: DiffActionAddConstraint, Target: c.Name, Expr: dialect.AddConstraint(c)})
+	}
+
+	for _, fk := range fkToAdd {
+		actions = append(actions, DiffAction{Kind: DiffActionAddForeignKey, Target: fk.Name, Expr: dialect.AddForeignKey(fk)})
+	}
+
+	return
+}
+
+// dropsColOf returns true when some columns of the prev foreign key will be dropped or renamed
+func (t *Table) dropsColOf(prevFk *ForeignKey) (dropped bool) {
+	prevFk.Columns.Range(func(prevCol *Column, idx int) {
+		if col := t.Col(prevCol.Name); col != nil && col.DeprecatedActions != nil {
+			dropped = true
+		}
+	})
+	return
+}
+
+// TableNamesWithRenameFrom returns names of tables, and old names of tables renamed from
+func (tables *Tables) TableNamesWithRenameFrom() (names []string) {
+	tables.Range(func(tab *Table, idx int) {
+		names = append(names, tab.Name)
+		if tab.RenameFrom != "" {
+			names = append(names, tab.RenameFrom)
+		}
+	})
 	return
 }
 
@@ -304,6 +985,33 @@ func (tables *Tables) Table(tableName string) *Table {
 	return nil
 }
 
+// MustTable returns table of tableName, panics with names of tables and the nearest one when missing
+func (tables *Tables) MustTable(tableName string) *Table {
+	if t := tables.Table(tableName); t != nil {
+		return t
+	}
+
+	names := tables.TableNames()
+
+	if nearest := nearestName(tableName, names); nearest != "" {
+		panic(fmt.Errorf("missing table %s, did you mean %s? tables: %s", tableName, nearest, strings.Join(names, ", ")))
+	}
+	panic(fmt.Errorf("missing table %s, tables: %s", tableName, strings.Join(names, ", ")))
+}
+
+// Has returns true when table of tableName added
+func (tables *Tables) Has(tableName string) bool {
+	return tables.Table(tableName) != nil
+}
+
+// Len returns count of tables
+func (tables *Tables) Len() int {
+	if tables.l == nil {
+		return 0
+	}
+	return tables.l.Len()
+}
+
 func (tables *Tables) Model(structName string) *Table {
 	if tables.models != nil {
 		if c, ok := tables.models[structName]; ok {
@@ -331,3 +1039,243 @@ func (tables *Tables) Range(cb func(tab *Table, idx int)) {
 		}
 	}
 }
+
+// Diff returns statements to migrate tables from prev, see DiffActions
+func (tables *Tables) Diff(prev *Tables, dialect Dialect, dropMissing bool) (exprList []SqlExpr) {
+	for _, action := range tables.DiffActions(prev, dialect, dropMissing) {
+		exprList = append(exprList, action.Expr)
+	}
+	return
+}
+
+// DiffActions returns changes to migrate tables from prev, with history tables of versioned tables.
+// Tables created go first by foreign key dependencies, then renames and alters of common tables,
+// tables absent from tables are dropped last only when dropMissing.
+func (tables *Tables) DiffActions(prev *Tables, dialect Dialect, dropMissing bool) (actions []DiffAction) {
+	if prev == nil {
+		prev = &Tables{}
+	}
+
+	tables = tables.WithHistoryTables()
+	prev = prev.WithHistoryTables()
+
+	sorted, cyclic := tables.CreationOrder()
+
+	kept := map[string]bool{}
+	alters := make([]DiffAction, 0)
+	fks := make([]DiffAction, 0)
+
+	for _, table := range sorted {
+		prevTable := prev.Table(table.Name)
+
+		if prevTable == nil && table.RenameFrom != "" {
+			if renamedFrom := prev.Table(table.RenameFrom); renamedFrom != nil {
+				kept[renamedFrom.Name] = true
+				alters = append(alters, DiffAction{Kind: DiffActionRenameTable, Target: table.Name, Expr: dialect.RenameTable(renamedFrom, table)})
+				prevTable = renamedFrom.Clone()
+				prevTable.Name = table.Name
+			}
+		}
+
+		if prevTable == nil {
+			if cyclic {
+				actions = append(actions, DiffAction{Kind: DiffActionCreateTable, Target: table.Name, Expr: dialect.CreateTable(table.WithoutForeignKeys())})
+				table.ForeignKeys.Range(func(fk *ForeignKey, idx int) {
+					fks = append(fks, DiffAction{Kind: DiffActionAddForeignKey, Target: fk.Name, Expr: dialect.AddForeignKey(fk)})
+				})
+				continue
+			}
+			actions = append(actions, DiffAction{Kind: DiffActionCreateTable, Target: table.Name, Expr: dialect.CreateTable(table)})
+			continue
+		}
+
+		kept[prevTable.Name] = true
+
+		for _, action := range table.DiffActions(prevTable, dialect) {
+			if cyclic && action.Kind == DiffActionAddForeignKey {
+				fks = append(fks, action)
+				continue
+			}
+			alters = append(alters, action)
+		}
+	}
+
+	actions = append(actions, alters...)
+	// foreign keys in cycle could only be added when all tables created
+	actions = append(actions, fks...)
+
+	if dropMissing {
+		prevSorted, err := prev.TopoSorted()
+		if err != nil {
+			prevSorted = prevSorted[:0]
+			prev.Range(func(tab *Table, idx int) {
+				prevSorted = append(prevSorted, tab)
+			})
+		}
+
+		// referencing tables should be dropped before tables they referenced
+		for i := len(prevSorted) - 1; i >= 0; i-- {
+			if prevTable := prevSorted[i]; !kept[prevTable.Name] {
+				actions = append(actions, DiffAction{Kind: DiffActionDropTable, Target: prevTable.Name, Expr: dialect.DropTable(prevTable)})
+			}
+		}
+	}
+
+	return
+}
+
+// CreationOrder returns tables in order to create, sorted by TopoSorted.
+// When tables are in foreign key cycle, they keep the order of adding and cyclic is true,
+// then tables should be created WithoutForeignKeys and foreign keys added after all tables created.
+func (tables *Tables) CreationOrder() (sorted []*Table, cyclic bool) {
+	sorted, err := tables.TopoSorted()
+	if err == nil {
+		return sorted, false
+	}
+	sorted = make([]*Table, 0)
+	tables.Range(func(tab *Table, idx int) {
+		sorted = append(sorted, tab)
+	})
+	return sorted, true
+}
+
+// TopoSorted returns tables sorted by foreign key dependencies, referenced tables go first.
+// Tables without relationships keep the order of adding.
+func (tables *Tables) TopoSorted() ([]*Table, error) {
+	list := make([]*Table, 0)
+	tables.Range(func(tab *Table, idx int) {
+		list = append(list, tab)
+	})
+
+	deps := map[string][]string{}
+
+	for _, tab := range list {
+		tab.ForeignKeys.Range(func(fk *ForeignKey, idx int) {
+			if fk.RefTable == nil || fk.RefTable.Name == tab.Name || tables.Table(fk.RefTable.Name) == nil {
+				return
+			}
+			deps[tab.Name] = append(deps[tab.Name], fk.RefTable.Name)
+		})
+	}
+
+	sorted := make([]*Table, 0, len(list))
+	done := map[string]bool{}
+
+	for len(sorted) < len(list) {
+		progressed := false
+
+		for _, tab := range list {
+			if done[tab.Name] {
+				continue
+			}
+
+			ready := true
+			for _, dep := range deps[tab.Name] {
+				if !done[dep] {
+					ready = false
+					break
+				}
+			}
+
+			if ready {
+				done[tab.Name] = true
+				sorted = append(sorted, tab)
+				progressed = true
+				// restart from the beginning to keep the order of adding
+				break
+			}
+		}
+
+		if !progressed {
+			return nil, fmt.Errorf("foreign key cycle: %s", strings.Join(findCycle(deps, done), " -> "))
+		}
+	}
+
+	return sorted, nil
+}
+
+func findCycle(deps map[string][]string, done map[string]bool) []string {
+	visiting := map[string]int{}
+	path := make([]string, 0)
+
+	var walk func(name string) []string
+
+	walk = func(name string) []string {
+		if i, ok := visiting[name]; ok {
+			return append(path[i:], name)
+		}
+		visiting[name] = len(path)
+		path = append(path, name)
+
+		for _, dep := range deps[name] {
+			if done[dep] {
+				continue
+			}
+			if cycle := walk(dep); cycle != nil {
+				return cycle
+			}
+		}
+
+		path = path[:len(path)-1]
+		delete(visiting, name)
+		return nil
+	}
+
+	names := make([]string, 0, len(deps))
+	for name := range deps {
+		if !done[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if cycle := walk(name); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// nearestName returns the one of names with least edit distance to name,
+// empty when none is close enough to be a typo
+func nearestName(name string, names []string) string {
+	nearest, least := "", len(name)/2+1
+
+	for _, n := range names {
+		if d := editDistance(strings.ToLower(name), strings.ToLower(n)); d < least {
+			nearest, least = n, d
+		}
+	}
+
+	return nearest
+}
+
+func editDistance(a string, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = prev[j-1] + cost
+			if d := prev[j] + 1; d < curr[j] {
+				curr[j] = d
+			}
+			if d := curr[j-1] + 1; d < curr[j] {
+				curr[j] = d
+			}
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,75 @@
+package builder
+
+import (
+	"time"
+)
+
+const (
+	// HistoryTableSuffix is appended to name of versioned table as name of its history table
+	HistoryTableSuffix = "_history"
+	// ColumnValidFrom and ColumnValidTo are the period of rows in history table, which are maintained by triggers
+	ColumnValidFrom = "valid_from"
+	ColumnValidTo   = "valid_to"
+	// HistoryIndexName is index of history table on primary key columns and valid_from
+	HistoryIndexName = "i_history"
+)
+
+// HistoryTable returns the history table of versioned table, nil when not versioned.
+// Columns are derived from the table, which are nullable, without autoincrement, generation or on update,
+// for rows are copied by triggers, and rows before adding column keep NULL,
+// with valid_from and valid_to appended.
+func (t *Table) HistoryTable() *Table {
+	if !t.Versioned {
+		return nil
+	}
+
+	history := &Table{
+		Name:   t.Name + HistoryTableSuffix,
+		Schema: t.Schema,
+	}
+
+	if t.RenameFrom != "" {
+		history.RenameFrom = t.RenameFrom + HistoryTableSuffix
+	}
+
+	t.Columns.Range(func(col *Column, idx int) {
+		c := col.On(history)
+		if col.ColumnType != nil {
+			ct := *col.ColumnType
+			ct.Null = true
+			ct.AutoIncrement = false
+			ct.Version = false
+			ct.OnUpdate = nil
+			ct.Backfill = nil
+			ct.GeneratedExpr = ""
+			ct.GeneratedStorage = ""
+			c.ColumnType = &ct
+		}
+		history.Columns.Add(c)
+	})
+
+	history.AddCol(Col(ColumnValidFrom).Field("ValidFrom").Type(time.Time{}, ""))
+	history.AddCol(Col(ColumnValidTo).Field("ValidTo").Type(time.Time{}, ",null"))
+
+	if pk := t.PrimaryKey(); pk != nil {
+		names := append(pk.Columns.ColNames(), ColumnValidFrom)
+		history.AddKey(Index(HistoryIndexName, history.rebindCols(Cols(names...))))
+	}
+
+	return history
+}
+
+// WithHistoryTables returns tables with history tables of versioned tables following them,
+// history tables already added are kept as they are.
+func (tables *Tables) WithHistoryTables() *Tables {
+	next := &Tables{}
+
+	tables.Range(func(tab *Table, idx int) {
+		next.Add(tab)
+		if history := tab.HistoryTable(); history != nil && tables.Table(history.Name) == nil {
+			next.Add(history)
+		}
+	})
+
+	return next
+}
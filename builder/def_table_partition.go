@@ -0,0 +1,45 @@
+package builder
+
+const (
+	PartitionStrategyRange = "RANGE"
+	PartitionStrategyList  = "LIST"
+	PartitionStrategyHash  = "HASH"
+)
+
+// PartitionBy declares the table partitioned by columns, like PARTITION BY RANGE (f_created_at) of postgres
+type PartitionBy struct {
+	// Strategy is one of PartitionStrategy*
+	Strategy string
+	Columns  *Columns
+}
+
+// PartitionOf declares the table a partition of the partitioned table
+type PartitionOf struct {
+	Parent *Table
+	// Bound is bound of the partition, like FOR VALUES FROM ('2024-01-01') TO ('2025-01-01')
+	Bound string
+}
+
+// PartitionedBy declares the table partitioned by columns of colNames
+func (t *Table) PartitionedBy(strategy string, colNames ...string) *Table {
+	t.PartitionBy = &PartitionBy{Strategy: strategy, Columns: t.rebindCols(Cols(colNames...))}
+	return t
+}
+
+// IsPartition returns true when the table is partition of other table,
+// whose columns and indexes are inherited from the partitioned table, so they are not diffed.
+func (t *Table) IsPartition() bool {
+	return t.PartitionOf != nil && t.PartitionOf.Parent != nil
+}
+
+// Partition returns partition of the table named name, with columns of the table for building queries
+func (t *Table) Partition(name string, bound string) *Table {
+	partition := t.Clone()
+	partition.Name = name
+	partition.ModelName = ""
+	partition.RenameFrom = ""
+	partition.Versioned = false
+	partition.PartitionBy = nil
+	partition.PartitionOf = &PartitionOf{Parent: t, Bound: bound}
+	return partition
+}
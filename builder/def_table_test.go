@@ -1,6 +1,7 @@
 package builder_test
 
 import (
+	"context"
 	"testing"
 
 	. "github.com/go-courier/sqlx/v2/builder"
@@ -28,6 +29,39 @@ func TestTable_Expr(t *testing.T) {
 	t.Run("replace table col by field for function", func(t *testing.T) {
 		gomega.NewWithT(t).Expect(tUser.Expr("COUNT(#ID)")).To(buidertestingutils.BeExpr("COUNT(f_id)"))
 	})
+	t.Run("escape # by ##", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(tUser.Expr("#Name = '##Name' AND #ID > 1")).To(buidertestingutils.BeExpr("f_name = '#Name' AND f_id > 1"))
+	})
+	t.Run("lowercase initial field", func(t *testing.T) {
+		tLog := T("t_log",
+			Col("f_id").Field("id").Type(uint64(0), ",autoincrement"),
+			Col("f_data").Field("data").Type("", ""),
+		)
+		gomega.NewWithT(t).Expect(tLog.Expr("#id = 1 AND #data IS NOT NULL")).To(buidertestingutils.BeExpr("f_id = 1 AND f_data IS NOT NULL"))
+	})
+	t.Run("keep jsonb operators", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(tUser.Expr("#Name #> '{a}' AND #Name #>> '{b}' AND #Name #- '{c}' IS NOT NULL")).
+			To(buidertestingutils.BeExpr("f_name #> '{a}' AND f_name #>> '{b}' AND f_name #- '{c}' IS NOT NULL"))
+	})
+	t.Run("adjacent placeholders", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(tUser.Expr("#ID#Name")).To(buidertestingutils.BeExpr("f_idf_name"))
+	})
+	t.Run("placeholder followed by holder", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(tUser.Expr("#ID?", 1)).To(buidertestingutils.BeExpr("f_id?", 1))
+	})
+	t.Run("unknown field", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(func() {
+			tUser.Expr("#Unknown = 1")
+		}).To(gomega.Panic())
+	})
+	t.Run("strict", func(t *testing.T) {
+		e, err := tUser.ExprStrict("#ID = ?", 1)
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(e).To(buidertestingutils.BeExpr("f_id = ?", 1))
+
+		_, err = tUser.ExprStrict("#Unknown = 1 AND #ID = 1 AND #Nmae = ''")
+		gomega.NewWithT(t).Expect(err).To(gomega.MatchError("missing fields Unknown, Nmae of table t_user"))
+	})
 	t.Run("could handle context", func(t *testing.T) {
 		gomega.NewWithT(t).Expect(
 			Select(nil).
@@ -45,3 +79,331 @@ WHERE t_user.f_id > 1
 `))
 	})
 }
+
+func TestTable_AssignmentsByFieldValues(t *testing.T) {
+	tUser := T("t_user",
+		Col("f_id").Field("ID").Type(uint64(0), ",autoincrement"),
+		Col("f_name").Field("Name").Type("", ",size=128,default=''"),
+		Col("f_age").Field("Age").Type(0, ""),
+	)
+
+	fieldValues := FieldValues{"Name": "a", "Age": 1, "ID": 2}
+
+	for i := 0; i < 10; i++ {
+		gomega.NewWithT(t).Expect(
+			Update(tUser).Set(tUser.AssignmentsByFieldValues(fieldValues)...),
+		).To(buidertestingutils.BeExpr("UPDATE t_user SET f_age = ?, f_id = ?, f_name = ?", 1, 2, "a"))
+	}
+
+	t.Run("later field wins for aliases", func(t *testing.T) {
+		tUser.AddCol(Col("f_name").Field("Nickname").Type("", ",size=128,default=''"))
+
+		gomega.NewWithT(t).Expect(
+			Update(tUser).Set(tUser.AssignmentsByFieldValues(FieldValues{"Name": "a", "Nickname": "b"})...),
+		).To(buidertestingutils.BeExpr("UPDATE t_user SET f_name = ?", "b"))
+	})
+}
+
+func TestTable_ColumnsAndValuesByFieldValues(t *testing.T) {
+	tUser := T("t_user",
+		Col("f_id").Field("ID").Type(uint64(0), ",autoincrement"),
+		Col("f_name").Field("Name").Type("", ",size=128,default=''"),
+		Col("f_age").Field("Age").Type(0, ""),
+	)
+
+	insert := func() SqlExpr {
+		cols, values := tUser.ColumnsAndValuesByFieldValues(FieldValues{"Name": "a", "Age": 1, "ID": 2})
+		return Insert().Into(tUser).Values(cols, values...)
+	}
+
+	gomega.NewWithT(t).Expect(SortedFieldNames(FieldValues{"Name": "a", "Age": 1, "ID": 2})).To(gomega.Equal([]string{"Age", "ID", "Name"}))
+
+	for i := 0; i < 10; i++ {
+		gomega.NewWithT(t).Expect(ResolveExpr(insert()).Query()).To(gomega.Equal(ResolveExpr(insert()).Query()))
+		gomega.NewWithT(t).Expect(insert()).To(buidertestingutils.BeExpr("INSERT INTO t_user (f_age,f_id,f_name) VALUES (?,?,?)", 1, 2, "a"))
+	}
+}
+
+func TestTable_AddColsAndKeys(t *testing.T) {
+	tUser := T("t_user")
+
+	tUser.AddCols(
+		Col("f_id").Field("ID").Type(uint64(0), ",autoincrement"),
+		nil,
+		Col("f_name").Field("Name").Type("", ",size=128,default=''"),
+	)
+	tUser.AddKeys(
+		PrimaryKey(Cols("f_id")),
+		nil,
+		UniqueIndex("i_name", Cols("f_name")),
+	)
+
+	gomega.NewWithT(t).Expect(tUser.Columns.ColNames()).To(gomega.Equal([]string{"f_id", "f_name"}))
+	gomega.NewWithT(t).Expect(tUser.Col("f_name").Table).To(gomega.Equal(tUser))
+	gomega.NewWithT(t).Expect(tUser.Keys.Len()).To(gomega.Equal(2))
+	gomega.NewWithT(t).Expect(tUser.Key("i_name").Table).To(gomega.Equal(tUser))
+}
+
+func TestTable_Validate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		tUser := T("t_user",
+			Col("f_id").Field("ID").Type(uint64(0), ",autoincrement"),
+			Col("f_old_name").Field("OldName").Type("", ",deprecated=f_name"),
+			Col("f_name").Field("Name").Type("", ",size=128,default=''"),
+			PrimaryKey(Cols("f_id")),
+			UniqueIndex("i_name", Cols("f_name")),
+		)
+
+		gomega.NewWithT(t).Expect(tUser.Validate()).To(gomega.BeNil())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		tUser := T("t_user",
+			Col("f_id").Field("ID").Type(uint64(0), ",null"),
+			Col("f_old_name").Field("OldName").Type("", ",deprecated=f_nickname"),
+			Col("f_name").Field("Name").Type("", ",size=128,default=''"),
+			Col("f_name").Field("Name2").Type("", ",size=128,default=''"),
+			PrimaryKey(Cols("f_id")),
+			UniqueIndex("i_name", Cols("f_name", "f_missing")),
+		)
+		tUser.AddForeignKey(FK("fk_org", Cols("f_org_id")).References(T("t_org"), Cols("f_id")))
+
+		gomega.NewWithT(t).Expect(tUser.Validate()).To(gomega.MatchError("invalid table t_user: " +
+			"column f_name is duplicated; " +
+			"column f_old_name is renamed to undeclared column f_nickname; " +
+			"primary key primary uses nullable column f_id; " +
+			"key i_name uses undeclared column f_missing; " +
+			"foreign key fk_org uses undeclared column f_org_id",
+		))
+	})
+}
+
+func TestTable_WithSchema(t *testing.T) {
+	tUser := T("t_user",
+		Col("f_id").Field("ID").Type(uint64(0), ",autoincrement"),
+	)
+	tUser.Description = append(make([]string, 0, 4), "user")
+
+	tenantA := tUser.WithSchema("tenant_a")
+	tenantA.Description = append(tenantA.Description, "of tenant a")
+
+	tenantB := tUser.WithSchema("tenant_b")
+	tenantB.Description = append(tenantB.Description, "of tenant b")
+
+	gomega.NewWithT(t).Expect(tUser.Description).To(gomega.Equal([]string{"user"}))
+	gomega.NewWithT(t).Expect(tenantA.Description).To(gomega.Equal([]string{"user", "of tenant a"}))
+	gomega.NewWithT(t).Expect(tenantB.Description).To(gomega.Equal([]string{"user", "of tenant b"}))
+	gomega.NewWithT(t).Expect(tenantA.F("ID").T()).To(gomega.BeIdenticalTo(tenantA))
+}
+
+func TestTable_DefaultSchema(t *testing.T) {
+	tUser := T("t_user",
+		Col("f_id").Field("ID").Type(uint64(0), ",autoincrement"),
+	)
+
+	ctx := ContextWithDefaultSchema(context.Background(), "app")
+
+	gomega.NewWithT(t).Expect(ResolveExprContext(ctx, Select(nil).From(tUser, Where(tUser.F("ID").Eq(1)))).Query()).
+		To(gomega.Equal("SELECT * FROM app.t_user\nWHERE f_id = ?"))
+	gomega.NewWithT(t).Expect(ResolveExprContext(ctx, Select(nil).From(tUser.WithSchema("public"))).Query()).
+		To(gomega.Equal("SELECT * FROM public.t_user"))
+	gomega.NewWithT(t).Expect(ResolveExprContext(context.Background(), Select(nil).From(tUser)).Query()).
+		To(gomega.Equal("SELECT * FROM t_user"))
+}
+
+func TestTable_PrimaryKey(t *testing.T) {
+	t.Run("declared", func(t *testing.T) {
+		table := T("t",
+			Col("f_id"),
+			Col("f_name"),
+			UniqueIndex("i_name", Cols("f_name")),
+			PrimaryKey(Cols("f_id")),
+		)
+
+		gomega.NewWithT(t).Expect(table.PrimaryKey().Name).To(gomega.Equal("primary"))
+		gomega.NewWithT(t).Expect(table.PrimaryKey().Columns.ColNames()).To(gomega.Equal([]string{"f_id"}))
+	})
+
+	t.Run("not declared", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(T("t", Col("f_id")).PrimaryKey()).To(gomega.BeNil())
+	})
+
+	t.Run("duplicated", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(func() {
+			T("t",
+				Col("f_id"),
+				Col("f_code"),
+				PrimaryKey(Cols("f_id")),
+				UniqueIndex("t_pkey", Cols("f_code")),
+			)
+		}).To(gomega.Panic())
+	})
+}
+
+func TestTable_Clone(t *testing.T) {
+	tUser := T("t_user",
+		Col("f_id").Field("ID").Type(uint64(0), ",autoincrement"),
+		Col("f_name").Field("Name").Type("", ",size=128,default=''"),
+		UniqueIndex("i_name", Cols("f_name")),
+	)
+
+	cloned := tUser.Clone()
+
+	cloned.F("Name").Length = 255
+	cloned.F("Name").Description = []string{"name"}
+	cloned.AddCol(Col("f_age").Field("Age").Type(0, ""))
+
+	gomega.NewWithT(t).Expect(tUser.F("Name").Length).To(gomega.Equal(uint64(128)))
+	gomega.NewWithT(t).Expect(tUser.F("Name").Description).To(gomega.BeNil())
+	gomega.NewWithT(t).Expect(tUser.F("Age")).To(gomega.BeNil())
+
+	gomega.NewWithT(t).Expect(cloned.F("Name").T()).To(gomega.BeIdenticalTo(cloned))
+	gomega.NewWithT(t).Expect(cloned.Key("i_name").T()).To(gomega.BeIdenticalTo(cloned))
+	gomega.NewWithT(t).Expect(cloned.Key("i_name").Columns.Col("f_name")).To(gomega.BeIdenticalTo(cloned.F("Name")))
+	gomega.NewWithT(t).Expect(tUser.Key("i_name").T()).To(gomega.BeIdenticalTo(tUser))
+}
+
+func TestTable_CopyStructure(t *testing.T) {
+	tUser := T("t_user",
+		Col("f_id").Field("ID").Type(uint64(0), ",autoincrement"),
+		Col("f_name").Field("Name").Type("", ",size=128,default=''"),
+		PrimaryKey(Cols("f_id")),
+		UniqueIndex("i_name", Cols("f_name")),
+	)
+	tUser.RenameFrom = "t_user_old"
+
+	t.Run("with keys", func(t *testing.T) {
+		copied := tUser.CopyStructure("t_user_staging", true)
+
+		gomega.NewWithT(t).Expect(copied.Name).To(gomega.Equal("t_user_staging"))
+		gomega.NewWithT(t).Expect(copied.RenameFrom).To(gomega.BeEmpty())
+		gomega.NewWithT(t).Expect(copied.Columns.ColNames()).To(gomega.Equal([]string{"f_id", "f_name"}))
+		gomega.NewWithT(t).Expect(copied.F("Name").T()).To(gomega.BeIdenticalTo(copied))
+		gomega.NewWithT(t).Expect(copied.Key("i_name").T()).To(gomega.BeIdenticalTo(copied))
+		gomega.NewWithT(t).Expect(copied.Key("i_name").Columns.Col("f_name")).To(gomega.BeIdenticalTo(copied.F("Name")))
+		gomega.NewWithT(t).Expect(copied.PrimaryKey()).NotTo(gomega.BeNil())
+	})
+
+	t.Run("without keys", func(t *testing.T) {
+		copied := tUser.CopyStructure("t_user_backup", false)
+
+		gomega.NewWithT(t).Expect(copied.Columns.ColNames()).To(gomega.Equal([]string{"f_id", "f_name"}))
+		gomega.NewWithT(t).Expect(copied.Keys.Len()).To(gomega.Equal(0))
+		gomega.NewWithT(t).Expect(tUser.Keys.Len()).To(gomega.Equal(2))
+		gomega.NewWithT(t).Expect(tUser.Name).To(gomega.Equal("t_user"))
+	})
+
+	t.Run("model name dropped", func(t *testing.T) {
+		tModel := tUser.Clone()
+		tModel.ModelName = "User"
+
+		gomega.NewWithT(t).Expect(tModel.CopyStructure("t_user_staging", true).ModelName).To(gomega.BeEmpty())
+	})
+
+	t.Run("versioned dropped", func(t *testing.T) {
+		tVersioned := tUser.Clone()
+		tVersioned.Versioned = true
+
+		gomega.NewWithT(t).Expect(tVersioned.CopyStructure("t_user_staging", true).Versioned).To(gomega.BeFalse())
+	})
+
+	t.Run("partition of dropped", func(t *testing.T) {
+		partition := tUser.Partition("t_user_2021", "DEFAULT")
+
+		copied := partition.CopyStructure("t_user_staging", true)
+		gomega.NewWithT(t).Expect(copied.PartitionOf).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(partition.PartitionOf).NotTo(gomega.BeNil())
+	})
+}
+
+func TestTables_TopoSorted(t *testing.T) {
+	tUser := T("t_user", Col("f_id").Type(uint64(0), ""))
+	tOrg := T("t_org", Col("f_id").Type(uint64(0), ""))
+
+	tOrder := T("t_order",
+		Col("f_user_id").Type(uint64(0), ""),
+		FK("fk_user", Cols("f_user_id")).References(tUser, Cols("f_id")),
+	)
+
+	t.Run("sorted by foreign keys", func(t *testing.T) {
+		tables := Tables{}
+		tables.Add(tOrder, tOrg, tUser)
+
+		sorted, err := tables.TopoSorted()
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(sorted).To(gomega.Equal([]*Table{tOrg, tUser, tOrder}))
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		tA := T("t_a",
+			Col("f_b_id").Type(uint64(0), ""),
+			FK("fk_b", Cols("f_b_id")).References(T("t_b"), Cols("f_id")),
+		)
+		tB := T("t_b",
+			Col("f_a_id").Type(uint64(0), ""),
+			FK("fk_a", Cols("f_a_id")).References(tA, Cols("f_id")),
+		)
+
+		tables := Tables{}
+		tables.Add(tUser, tA, tB)
+
+		_, err := tables.TopoSorted()
+		gomega.NewWithT(t).Expect(err).To(gomega.MatchError("foreign key cycle: t_a -> t_b -> t_a"))
+
+		sorted, cyclic := tables.CreationOrder()
+		gomega.NewWithT(t).Expect(cyclic).To(gomega.BeTrue())
+		gomega.NewWithT(t).Expect(sorted).To(gomega.Equal([]*Table{tUser, tA, tB}))
+
+		created := tA.WithoutForeignKeys()
+		gomega.NewWithT(t).Expect(created.ForeignKeys.Len()).To(gomega.Equal(0))
+		gomega.NewWithT(t).Expect(tA.ForeignKeys.Len()).To(gomega.Equal(1))
+	})
+}
+
+func TestTables_MustTable(t *testing.T) {
+	tables := Tables{}
+	gomega.NewWithT(t).Expect(tables.Len()).To(gomega.Equal(0))
+	gomega.NewWithT(t).Expect(tables.Has("t_user")).To(gomega.BeFalse())
+
+	tUser := T("t_user", Col("f_id").Type(uint64(0), ""))
+	tables.Add(tUser, T("t_org"))
+
+	gomega.NewWithT(t).Expect(tables.Len()).To(gomega.Equal(2))
+	gomega.NewWithT(t).Expect(tables.Has("t_user")).To(gomega.BeTrue())
+	gomega.NewWithT(t).Expect(tables.MustTable("t_user")).To(gomega.Equal(tUser))
+
+	gomega.NewWithT(t).Expect(func() { tables.MustTable("t_usr") }).
+		To(gomega.PanicWith(gomega.MatchError("missing table t_usr, did you mean t_user? tables: t_user, t_org")))
+	gomega.NewWithT(t).Expect(func() { tables.MustTable("t_account") }).
+		To(gomega.PanicWith(gomega.MatchError("missing table t_account, tables: t_user, t_org")))
+}
+
+func TestForeignKeys_Remove(t *testing.T) {
+	tOrg := T("t_org", Col("f_id").Field("ID").Type(uint64(0), ""))
+
+	fks := &ForeignKeys{}
+	fks.Add(
+		FK("FK_org", Cols("f_org_id")).References(tOrg, Cols("f_id")),
+		FK("fk_parent", Cols("f_parent_id")).References(tOrg, Cols("f_id")),
+	)
+
+	fks.Remove("FK_Org")
+
+	gomega.NewWithT(t).Expect(fks.Len()).To(gomega.Equal(1))
+	gomega.NewWithT(t).Expect(fks.ForeignKey("fk_org")).To(gomega.BeNil())
+	gomega.NewWithT(t).Expect(fks.ForeignKey("fk_parent")).NotTo(gomega.BeNil())
+}
+
+func TestConstraints_Remove(t *testing.T) {
+	cs := &Constraints{}
+	cs.Add(
+		Check("C_Age", Expr("f_age >= 0")),
+		Check("c_name", Expr("f_name <> ''")),
+	)
+
+	cs.Remove("C_AGE")
+
+	gomega.NewWithT(t).Expect(cs.Len()).To(gomega.Equal(1))
+	gomega.NewWithT(t).Expect(cs.Constraint("c_age")).To(gomega.BeNil())
+	gomega.NewWithT(t).Expect(cs.Constraint("c_name")).NotTo(gomega.BeNil())
+}
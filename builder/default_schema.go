@@ -0,0 +1,24 @@
+package builder
+
+import (
+	"context"
+)
+
+type contextKeyForDefaultSchema int
+
+// ContextWithDefaultSchema sets schema qualifying tables without Schema when rendering,
+// so queries don't depend on search_path of the session. Schema set by WithSchema still wins.
+func ContextWithDefaultSchema(ctx context.Context, schema string) context.Context {
+	return context.WithValue(ctx, contextKeyForDefaultSchema(1), schema)
+}
+
+// DefaultSchemaFromContext returns schema set by ContextWithDefaultSchema, empty when not set
+func DefaultSchemaFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if schema, ok := ctx.Value(contextKeyForDefaultSchema(1)).(string); ok {
+		return schema
+	}
+	return ""
+}
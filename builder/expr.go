@@ -36,6 +36,20 @@ func Expr(query string, args ...interface{}) *Ex {
 	return &Ex{Buffer: bytes.NewBufferString(query), args: args}
 }
 
+// ExprWithErr returns expr failed to render with err, like statements not supported by the dialect,
+// executing it returns err instead of sending the statement.
+func ExprWithErr(expr SqlExpr, err error) SqlExpr {
+	return ExprBy(func(ctx context.Context) *Ex {
+		e := Expr("")
+		e.WriteExpr(expr)
+		e = e.Ex(ctx)
+		if e != nil && e.err == nil {
+			e.err = err
+		}
+		return e
+	})
+}
+
 func ResolveExpr(v interface{}) *Ex {
 	return ResolveExprContext(context.Background(), v)
 }
@@ -137,6 +151,7 @@ func (e Ex) Ex(ctx context.Context) *Ex {
 	index := 0
 	expr := Expr("")
 	expr.rendered = true
+	expr.err = e.err
 
 	query := e.Bytes()
 	n := len(e.args)
@@ -161,6 +176,9 @@ func (e Ex) Ex(ctx context.Context) *Ex {
 					if !IsNilExpr(subEx) {
 						expr.Write(subEx.Bytes())
 						expr.AppendArgs(subEx.Args()...)
+						if expr.err == nil {
+							expr.err = subEx.err
+						}
 					}
 				}
 
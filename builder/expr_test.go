@@ -27,6 +27,11 @@ func TestEx(t *testing.T) {
 			Expr(`#ID IN (?)`, []int{28, 29, 30}),
 		).To(BeExpr("#ID IN (?,?,?)", 28, 29, 30))
 	})
+	t.Run("flatten should skip for array", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(
+			Expr(`#ID = ANY(?)`, Array([]int{28, 29, 30})),
+		).To(BeExpr("#ID = ANY(?)", Array([]int{28, 29, 30})))
+	})
 	t.Run("flatten should skip for bytes", func(t *testing.T) {
 		gomega.NewWithT(t).Expect(
 			Expr(`#ID = (?)`, []byte("")),
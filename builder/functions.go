@@ -76,3 +76,90 @@ func (f *Function) Ex(ctx context.Context) *Ex {
 
 	return e.Ex(ctx)
 }
+
+func RowNumber() *WindowFunction {
+	return WindowFunc("ROW_NUMBER")
+}
+
+func Rank() *WindowFunction {
+	return WindowFunc("RANK")
+}
+
+func DenseRank() *WindowFunction {
+	return WindowFunc("DENSE_RANK")
+}
+
+// WindowFunc
+//
+// examples:
+// ROW_NUMBER() OVER (PARTITION BY f_a ORDER BY (f_b) DESC)
+func WindowFunc(name string, sqlExprs ...SqlExpr) *WindowFunction {
+	if name == "" {
+		return nil
+	}
+	return &WindowFunction{
+		name:  name,
+		exprs: sqlExprs,
+	}
+}
+
+type WindowFunction struct {
+	name        string
+	exprs       []SqlExpr
+	partitionBy []SqlExpr
+	orders      []*Order
+}
+
+func (f WindowFunction) PartitionBy(sqlExprs ...SqlExpr) *WindowFunction {
+	f.partitionBy = sqlExprs
+	return &f
+}
+
+func (f WindowFunction) OrderBy(orders ...*Order) *WindowFunction {
+	f.orders = orders
+	return &f
+}
+
+func (f *WindowFunction) IsNil() bool {
+	return f == nil || f.name == ""
+}
+
+func (f *WindowFunction) Ex(ctx context.Context) *Ex {
+	e := Expr(f.name)
+
+	e.WriteGroup(func(e *Ex) {
+		for i := range f.exprs {
+			if i > 0 {
+				e.WriteByte(',')
+			}
+			e.WriteExpr(f.exprs[i])
+		}
+	})
+
+	e.WriteString(" OVER ")
+
+	e.WriteGroup(func(e *Ex) {
+		partitioned := false
+
+		RangeNotNilExpr(f.partitionBy, func(expr SqlExpr, i int) {
+			if i > 0 {
+				e.WriteByte(',')
+			} else {
+				e.WriteString("PARTITION BY ")
+			}
+			e.WriteExpr(expr)
+			partitioned = true
+		})
+
+		orders := OrderBy(f.orders...)
+
+		if !orders.IsNil() {
+			if partitioned {
+				e.WriteByte(' ')
+			}
+			e.WriteExpr(orders)
+		}
+	})
+
+	return e.Ex(ctx)
+}
@@ -19,3 +19,20 @@ func TestFunc(t *testing.T) {
 		gomega.NewWithT(t).Expect(Avg()).To(BeExpr("AVG(*)"))
 	})
 }
+
+func TestWindowFunc(t *testing.T) {
+	t.Run("invalid", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(WindowFunc("")).To(BeExpr(""))
+	})
+	t.Run("empty window", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(RowNumber()).To(BeExpr("ROW_NUMBER() OVER ()"))
+	})
+	t.Run("partition by", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(Rank().PartitionBy(Col("f_a"), Col("f_b"))).To(BeExpr("RANK() OVER (PARTITION BY f_a,f_b)"))
+	})
+	t.Run("partition by and order by", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(
+			RowNumber().PartitionBy(Col("f_a")).OrderBy(DescOrder(Col("f_b"))),
+		).To(BeExpr("ROW_NUMBER() OVER (PARTITION BY f_a ORDER BY (f_b) DESC)"))
+	})
+}
@@ -23,6 +23,27 @@ type WithTableDescription interface {
 
 type Indexes map[string][]string
 
+// WithIndexesRenameFrom marks indexes renamed, which returns map of index name to its old name,
+// the index is renamed instead of rebuilt when its definition not changed
+type WithIndexesRenameFrom interface {
+	IndexesRenameFrom() map[string]string
+}
+
+// WithTableRenameFrom marks the table renamed from the old table name, for keeping data when migrating
+type WithTableRenameFrom interface {
+	TableRenameFrom() string
+}
+
+// WithTableStorageParams sets storage parameters of the table, like fillfactor of postgres
+type WithTableStorageParams interface {
+	TableStorageParams() map[string]string
+}
+
+// WithTableVersioned marks the table system-versioned, whose history table is migrated in lockstep
+type WithTableVersioned interface {
+	TableVersioned() bool
+}
+
 type WithPrimaryKey interface {
 	PrimaryKey() []string
 }
@@ -47,22 +68,90 @@ type WithColDescriptions interface {
 	ColDescriptions() map[string][]string
 }
 
+// EnumTypeDialect is implemented by dialects support enum type as schema object
+type EnumTypeDialect interface {
+	CreateEnumType(e *EnumType) SqlExpr
+	// AddEnumValue adds value before or after the existed value, appends when both empty
+	AddEnumValue(e *EnumType, value string, before string, after string) SqlExpr
+}
+
+// ReturningDialect is implemented by dialects tell whether RETURNING clause is supported,
+// dialects not implemented are treated as supported.
+type ReturningDialect interface {
+	SupportsReturning() bool
+}
+
+// ColumnRiskDialect is implemented by dialects tell whether modifying column rewrites the table,
+// ModifyColumn of dialects not implemented is treated as RiskTableRewrite.
+type ColumnRiskDialect interface {
+	ModifyColumnRisk(col *Column, prev *Column) Risk
+}
+
+// StorageParamsDialect is implemented by dialects support storage parameters of tables and indexes,
+// StorageParams are ignored by dialects not implemented.
+type StorageParamsDialect interface {
+	// SetTableStorageParams sets params and resets params named in reset of table
+	SetTableStorageParams(t *Table, params map[string]string, reset []string) SqlExpr
+	// SetIndexStorageParams sets params and resets params named in reset of index
+	SetIndexStorageParams(key *Key, params map[string]string, reset []string) SqlExpr
+}
+
+// PartitionDialect is implemented by dialects support declarative partitioning,
+// CreateTable of partitioned table renders PARTITION BY, and of partition renders PARTITION OF.
+type PartitionDialect interface {
+	// AttachPartition attaches existed table as partition of PartitionOf
+	AttachPartition(partition *Table) SqlExpr
+	// DetachPartition detaches partition from PartitionOf, which is kept as standalone table
+	DetachPartition(partition *Table) SqlExpr
+}
+
+// RebuildDialect is implemented by dialects apply some changes by rebuilding the table, like sqlite,
+// DiffActions replaces all changes of definition of the table by one RebuildTable when some of them rebuild.
+type RebuildDialect interface {
+	// RequiresRebuild returns true when the change is applied by rebuilding the table
+	RequiresRebuild(action DiffAction) bool
+	// RebuildTable rebuilds table from prev to t, rows of columns both in prev and t are kept
+	RebuildTable(t *Table, prev *Table) SqlExpr
+}
+
 type Dialect interface {
 	DriverName() string
+	// BindVar returns placeholder of the i-th (from 1) arg in query, like ? of mysql or $1 of postgres
+	BindVar(i int) string
+	// Quote quotes name of table or column, like "order" of postgres or `order` of mysql,
+	// names not NeedsQuote are kept as they are
+	Quote(ident string) string
 	PrimaryKeyName() string
+	IsReservedWord(name string) bool
 	IsErrorUnknownDatabase(err error) bool
 	IsErrorConflict(err error) bool
 	CreateDatabase(dbName string) SqlExpr
 	CreateSchema(schemaName string) SqlExpr
 	DropDatabase(dbName string) SqlExpr
 	CreateTableIsNotExists(t *Table) []SqlExpr
+	CreateTable(t *Table) SqlExpr
 	DropTable(t *Table) SqlExpr
+	RenameTable(from *Table, to *Table) SqlExpr
 	TruncateTable(t *Table) SqlExpr
+	// ModifyTableComment sets comment of table by CommentText, comment is removed when empty
+	ModifyTableComment(t *Table) SqlExpr
 	AddColumn(col *Column) SqlExpr
 	RenameColumn(col *Column, target *Column) SqlExpr
 	ModifyColumn(col *Column, prev *Column) SqlExpr
+	ModifyColumnComment(col *Column) SqlExpr
+	SetColumnDefault(col *Column) SqlExpr
+	DropColumnDefault(col *Column) SqlExpr
 	DropColumn(col *Column) SqlExpr
 	AddIndex(key *Key) SqlExpr
 	DropIndex(key *Key) SqlExpr
+	// AddPrimaryKey adds primary key to table, DropPrimaryKey drops the one of table loaded from database,
+	// which are separated from indexes for some dialects could only rebuild table to change primary key
+	AddPrimaryKey(key *Key) SqlExpr
+	DropPrimaryKey(key *Key) SqlExpr
+	RenameIndex(key *Key, target *Key) SqlExpr
+	AddForeignKey(fk *ForeignKey) SqlExpr
+	DropForeignKey(fk *ForeignKey) SqlExpr
+	AddConstraint(c *Constraint) SqlExpr
+	DropConstraint(c *Constraint) SqlExpr
 	DataType(columnType *ColumnType) SqlExpr
 }
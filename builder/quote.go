@@ -0,0 +1,48 @@
+package builder
+
+import (
+	"context"
+)
+
+type contextKeyForQuote int
+
+// ContextWithQuote sets Quote of dialect, by which names of tables and columns are quoted when rendering
+func ContextWithQuote(ctx context.Context, quote func(ident string) string) context.Context {
+	return context.WithValue(ctx, contextKeyForQuote(1), quote)
+}
+
+// QuoteIdent quotes ident by Quote of dialect from context, ident is kept as it is when not set
+func QuoteIdent(ctx context.Context, ident string) string {
+	if ctx == nil {
+		return ident
+	}
+	if quote, ok := ctx.Value(contextKeyForQuote(1)).(func(ident string) string); ok && quote != nil {
+		return quote(ident)
+	}
+	return ident
+}
+
+// NeedsQuote returns true when ident is reserved word, or starts with digit,
+// or has chars other than letters, digits and underscore.
+// Dialects quote these only, for quoting others changes nothing but readability,
+// and quoted names are case sensitive in postgres.
+func NeedsQuote(ident string, isReservedWord func(name string) bool) bool {
+	if ident == "" {
+		return false
+	}
+
+	for i := 0; i < len(ident); i++ {
+		c := ident[i]
+		switch {
+		case c == '_', 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case '0' <= c && c <= '9':
+			if i == 0 {
+				return true
+			}
+		default:
+			return true
+		}
+	}
+
+	return isReservedWord != nil && isReservedWord(ident)
+}
@@ -0,0 +1,106 @@
+package builder
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RenderMigration renders expressions of migration into sql for human review, NOT for executing.
+// Holders are replaced by literal of args, and statements are separated by ";\n".
+func RenderMigration(exprList []SqlExpr, dialect Dialect) string {
+	b := bytes.NewBufferString("-- migration of " + dialect.DriverName() + "\n")
+	writeStatements(b, exprList, dialect)
+	return b.String()
+}
+
+// RenderDiff renders migration diff of the table like RenderMigration, with comment of table name for review
+func (t *Table) RenderDiff(prevTable *Table, dialect Dialect) string {
+	b := bytes.NewBufferString("-- table " + t.Name + "\n")
+	writeStatements(b, t.Diff(prevTable, dialect), dialect)
+	return b.String()
+}
+
+func writeStatements(b *bytes.Buffer, exprList []SqlExpr, dialect Dialect) {
+	ctx := ContextWithQuote(context.Background(), dialect.Quote)
+
+	for _, expr := range exprList {
+		if IsNilExpr(expr) {
+			continue
+		}
+		e := ResolveExprContext(ctx, expr)
+		if e.IsNil() {
+			continue
+		}
+		b.WriteString(strings.TrimRight(strings.TrimSpace(interpolateHolders(e.Query(), e.Args())), ";"))
+		b.WriteString(";\n")
+	}
+}
+
+// interpolateHolders replaces holders out of quoted strings by literal of args
+func interpolateHolders(query string, args []interface{}) string {
+	if len(args) == 0 {
+		return query
+	}
+
+	b := strings.Builder{}
+	argIdx := 0
+	quoted := false
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'':
+			quoted = !quoted
+		case c == '?' && !quoted && argIdx < len(args):
+			b.WriteString(literal(args[argIdx]))
+			argIdx++
+			continue
+		}
+		b.WriteByte(c)
+	}
+
+	return b.String()
+}
+
+func literal(v interface{}) string {
+	if valuer, ok := v.(driver.Valuer); ok {
+		value, err := valuer.Value()
+		if err != nil {
+			return "'" + err.Error() + "'"
+		}
+		v = value
+	}
+
+	switch x := v.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if x {
+			return "TRUE"
+		}
+		return "FALSE"
+	case string:
+		return quoteLiteral(x)
+	case []byte:
+		return quoteLiteral(string(x))
+	case time.Time:
+		return quoteLiteral(x.Format(time.RFC3339Nano))
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", x)
+	case float32:
+		return strconv.FormatFloat(float64(x), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	}
+
+	return quoteLiteral(fmt.Sprintf("%v", v))
+}
+
+func quoteLiteral(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
@@ -2,6 +2,7 @@ package builder
 
 import (
 	"context"
+	"fmt"
 )
 
 func Insert(modifiers ...string) *StmtInsert {
@@ -82,12 +83,19 @@ func OnDuplicateKeyUpdate(assignments ...*Assignment) *OtherAddition {
 	return AsAddition(e)
 }
 
+// Returning projects columns of rows changed, like Returning(table.Cols("F_id")), all columns when expr is nil.
+// Rendering fails when ToggleReturningUnsupported, for dialects like mysql could not return rows.
 func Returning(expr SqlExpr) *OtherAddition {
-	e := Expr("RETURNING ")
-	if expr == nil || expr.IsNil() {
-		e.WriteByte('*')
-	} else {
-		e.WriteExpr(expr)
-	}
-	return AsAddition(e)
+	return AsAddition(ExprBy(func(ctx context.Context) *Ex {
+		e := Expr("RETURNING ")
+		if expr == nil || expr.IsNil() {
+			e.WriteByte('*')
+		} else {
+			e.WriteExpr(expr)
+		}
+		if TogglesFromContext(ctx).Is(ToggleReturningUnsupported) {
+			e.err = fmt.Errorf("RETURNING is not supported by the dialect")
+		}
+		return e.Ex(ctx)
+	}))
 }
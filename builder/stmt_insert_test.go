@@ -1,6 +1,7 @@
 package builder_test
 
 import (
+	"context"
 	"testing"
 
 	. "github.com/go-courier/sqlx/v2/builder"
@@ -20,6 +21,28 @@ func TestStmtInsert(t *testing.T) {
 			1, 2))
 	})
 
+	t.Run("insert on conflict do nothing", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(
+			Insert().
+				Into(table, OnConflict(Cols("f_a")).DoNothing()).
+				Values(Cols("f_a", "f_b"), 1, 2),
+		).To(BeExpr(`
+INSERT INTO T (f_a,f_b) VALUES (?,?)
+ON CONFLICT (f_a) DO NOTHING
+`, 1, 2))
+	})
+
+	t.Run("insert on conflict without target do nothing", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(
+			Insert().
+				Into(table, OnConflict(nil).DoNothing()).
+				Values(Cols("f_a", "f_b"), 1, 2),
+		).To(BeExpr(`
+INSERT INTO T (f_a,f_b) VALUES (?,?)
+ON CONFLICT DO NOTHING
+`, 1, 2))
+	})
+
 	t.Run("insert simple", func(t *testing.T) {
 		gomega.NewWithT(t).Expect(
 			Insert().
@@ -49,4 +72,29 @@ INSERT INTO T (f_a,f_b) SELECT f_a,f_b FROM T
 WHERE f_a = ?
 `, 1))
 	})
+
+	t.Run("insert returning", func(t *testing.T) {
+		table := T("T", Col("f_id").Field("ID"), Col("f_a").Field("A"), Col("f_b").Field("B"))
+
+		gomega.NewWithT(t).Expect(
+			Insert().
+				Into(table, Returning(table.Columns.Pick("ID", "A"))).
+				Values(table.Columns.Pick("A", "B"), 1, 2),
+		).To(BeExpr(`
+INSERT INTO T (f_a,f_b) VALUES (?,?)
+RETURNING f_id,f_a
+`, 1, 2))
+	})
+
+	t.Run("insert returning unsupported", func(t *testing.T) {
+		ctx := ContextWithToggles(context.Background(), Toggles{
+			ToggleReturningUnsupported: true,
+		})
+
+		e := ResolveExprContext(ctx, Insert().
+			Into(table, Returning(nil)).
+			Values(Cols("f_a", "f_b"), 1, 2))
+
+		gomega.NewWithT(t).Expect(e.Err()).To(gomega.MatchError("RETURNING is not supported by the dialect"))
+	})
 }
@@ -73,3 +73,32 @@ func (w *WithStmt) Ex(ctx context.Context) *Ex {
 
 	return e.Ex(ctx)
 }
+
+// DedupBy keeps the first row of each partition ordered by orders, powered by ROW_NUMBER()
+//
+// examples:
+// WITH t_dedup(f_a,f_b,rn) AS (
+// SELECT f_a,f_b,ROW_NUMBER() OVER (PARTITION BY f_a ORDER BY (f_b) DESC) FROM t
+// )
+// SELECT f_a,f_b FROM t_dedup
+// WHERE rn = 1
+//
+// additions are applied to the inner select, for filtering rows before dedup.
+func DedupBy(table *Table, partitionBy *Columns, orders []*Order, additions ...Addition) *WithStmt {
+	dedupTable := T(table.Name + "_dedup")
+
+	table.Columns.Range(func(col *Column, idx int) {
+		dedupTable.AddCol(col)
+	})
+
+	cols := dedupTable.Columns.Clone()
+
+	dedupTable.AddCol(Col("rn"))
+
+	return With(dedupTable, func(t *Table) SqlExpr {
+		return Select(MultiWith(",", &table.Columns, RowNumber().PartitionBy(partitionBy).OrderBy(orders...))).
+			From(table, additions...)
+	}).Exec(func(tables ...*Table) SqlExpr {
+		return Select(cols).From(tables[0], Where(tables[0].Col("rn").Eq(1)))
+	})
+}
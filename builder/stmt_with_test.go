@@ -103,6 +103,20 @@ WHERE (t_group_with_parent.f_group_id <> t_group_with_parent_and_children.f_grou
 SELECT * FROM t_group_with_parent_and_children
 `, 1201375536060956676))
 	})
+	t.Run("DedupBy", func(t *testing.T) {
+		gr := (&GroupRelation{}).T()
+
+		gomega.NewWithT(t).Expect(
+			DedupBy(gr, gr.MustFields("GroupID"), []*Order{DescOrder(gr.F("ParentGroupID"))}, Where(gr.F("GroupID").Gt(0))),
+		).To(buidertestingutils.BeExpr(`
+WITH t_group_relation_dedup(f_group_id,f_parent_group_id,rn) AS (
+SELECT f_group_id,f_parent_group_id,ROW_NUMBER() OVER (PARTITION BY f_group_id ORDER BY (f_parent_group_id) DESC) FROM t_group_relation
+WHERE f_group_id > ?
+)
+SELECT f_group_id,f_parent_group_id FROM t_group_relation_dedup
+WHERE rn = ?
+`, 0, 1))
+	})
 }
 
 var tableGroup = TableFromModel(&Group{})
@@ -8,6 +8,8 @@ var (
 	ToggleMultiTable    = "MultiTable"
 	ToggleNeedAutoAlias = "NeedAlias"
 	ToggleUseValues     = "UseValues"
+	// ToggleReturningUnsupported is set when rendering for dialect without RETURNING clause
+	ToggleReturningUnsupported = "ReturningUnsupported"
 )
 
 type Toggles map[string]bool
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"go/ast"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/go-courier/reflectx"
@@ -12,6 +13,17 @@ import (
 
 type FieldValues map[string]interface{}
 
+// SortedFieldNames returns field names of fieldValues in ascending order,
+// which is the order of columns and assignments built from FieldValues.
+func SortedFieldNames(fieldValues FieldValues) []string {
+	fieldNames := make([]string, 0, len(fieldValues))
+	for fieldName := range fieldValues {
+		fieldNames = append(fieldNames, fieldName)
+	}
+	sort.Strings(fieldNames)
+	return fieldNames
+}
+
 type StructField struct {
 	Value      reflect.Value
 	Field      reflect.StructField
@@ -178,6 +190,10 @@ func FieldValuesFromStructByNonZero(structValue interface{}, excludes ...string)
 	return
 }
 
+// TableFromModel builds table from struct tags of model, which should be a pointer of struct.
+// Each exported field tagged `db:"f_name,size=255,null,default='0'"` is a column,
+// fields of exported embedded structs without db tag are flattened into the table.
+// Keys are declared by hooks of model, like WithPrimaryKey, WithUniqueIndexes and WithIndexes.
 func TableFromModel(model Model) *Table {
 	tpe := reflect.TypeOf(model)
 	if tpe.Kind() != reflect.Ptr {
@@ -213,6 +229,18 @@ func ScanDefToTable(rv reflect.Value, table *Table) {
 				table.Description = desc
 			}
 
+			if withTableRenameFrom, ok := i.(WithTableRenameFrom); ok {
+				table.RenameFrom = withTableRenameFrom.TableRenameFrom()
+			}
+
+			if withTableVersioned, ok := i.(WithTableVersioned); ok {
+				table.Versioned = withTableVersioned.TableVersioned()
+			}
+
+			if withTableStorageParams, ok := i.(WithTableStorageParams); ok {
+				table.StorageParams = withTableStorageParams.TableStorageParams()
+			}
+
 			if withComments, ok := i.(WithComments); ok {
 				for fieldName, comment := range withComments.Comments() {
 					field := table.F(fieldName)
@@ -269,6 +297,14 @@ func ScanDefToTable(rv reflect.Value, table *Table) {
 					table.AddKey(Index(indexName, cols).Using(method))
 				}
 			}
+
+			if withIndexesRenameFrom, ok := i.(WithIndexesRenameFrom); ok {
+				for indexName, renameFrom := range withIndexesRenameFrom.IndexesRenameFrom() {
+					if key := table.Key(indexName); key != nil {
+						key.RenameFrom = strings.ToLower(renameFrom)
+					}
+				}
+			}
 		}
 	}
 }
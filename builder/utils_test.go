@@ -47,3 +47,43 @@ func TestValueMap(t *testing.T) {
 		gomega.NewWithT(t).Expect(GetColumnName("Text", "f_text2,default=''")).To(gomega.Equal("f_text2"))
 	})
 }
+
+type OperationTimes struct {
+	CreatedAt int64 `db:"f_created_at,default='0'"`
+	UpdatedAt int64 `db:"f_updated_at,default='0'"`
+}
+
+type modelUser struct {
+	ID       uint64 `db:"f_id,autoincrement"`
+	Name     string `db:"f_name,size=128"`
+	Nickname string `db:"f_nickname,null"`
+	Ignored  string `db:"-"`
+	OperationTimes
+}
+
+func (modelUser) TableName() string {
+	return "t_user"
+}
+
+func (modelUser) PrimaryKey() []string {
+	return []string{"ID"}
+}
+
+func (modelUser) UniqueIndexes() Indexes {
+	return Indexes{"i_name": {"Name"}}
+}
+
+func TestTableFromModel(t *testing.T) {
+	table := TableFromModel(&modelUser{})
+
+	gomega.NewWithT(t).Expect(table.Name).To(gomega.Equal("t_user"))
+	gomega.NewWithT(t).Expect(table.ModelName).To(gomega.Equal("modelUser"))
+	gomega.NewWithT(t).Expect(table.Columns.FieldNames()).To(gomega.Equal([]string{"ID", "Name", "Nickname", "CreatedAt", "UpdatedAt"}))
+
+	gomega.NewWithT(t).Expect(table.F("ID").AutoIncrement).To(gomega.BeTrue())
+	gomega.NewWithT(t).Expect(table.F("Name").Length).To(gomega.Equal(uint64(128)))
+	gomega.NewWithT(t).Expect(table.F("Nickname").Null).To(gomega.BeTrue())
+
+	gomega.NewWithT(t).Expect(table.PrimaryKey().Columns.ColNames()).To(gomega.Equal([]string{"f_id"}))
+	gomega.NewWithT(t).Expect(table.Key("i_name").IsUnique).To(gomega.BeTrue())
+}
@@ -0,0 +1,61 @@
+package sqlx
+
+import (
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+var pkgPath = reflect.TypeOf(DB{}).PkgPath()
+
+// SetCallerComment toggles prepending the source location of the caller as sql comment, like /* user.go:123 */.
+// Disabled by default, for the cost of runtime.Callers.
+func (d *DB) SetCallerComment(enabled bool) {
+	d.callerComment = enabled
+}
+
+func (d *DB) withCallerComment(query string) string {
+	if !d.callerComment {
+		return query
+	}
+	if loc := callerLocation(); loc != "" {
+		return "/* " + loc + " */ " + query
+	}
+	return query
+}
+
+// callerLocation returns file:line of the first frame out of sqlx and its sub packages
+func callerLocation() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	for {
+		frame, more := frames.Next()
+		if !isInternalFrame(frame.Function) {
+			return escapeComment(filepath.Base(frame.File) + ":" + strconv.Itoa(frame.Line))
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+func isInternalFrame(fn string) bool {
+	return strings.HasPrefix(fn, pkgPath+".") || strings.HasPrefix(fn, pkgPath+"/")
+}
+
+// escapeComment keeps safe chars only, to avoid closing comment or breaking value holders
+func escapeComment(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r == '.' || r == '_' || r == '-' || r == ':':
+			return r
+		}
+		return '_'
+	}, s)
+}
@@ -0,0 +1,31 @@
+package sqlx_test
+
+import (
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/go-courier/sqlx/v2"
+	"github.com/go-courier/sqlx/v2/builder"
+	. "github.com/onsi/gomega"
+)
+
+func TestCallerComment(t *testing.T) {
+	queries := make([]string, 0)
+
+	db := sqlx.NewDatabase("test").OpenDB(&fakeConnector{name: "db", queries: &queries})
+
+	_, err := db.ExecExpr(builder.Expr("DELETE FROM t"))
+	NewWithT(t).Expect(err).To(BeNil())
+
+	db.SetCallerComment(true)
+
+	_, _, line, _ := runtime.Caller(0)
+	_, err = db.ExecExpr(builder.Expr("DELETE FROM t"))
+	NewWithT(t).Expect(err).To(BeNil())
+
+	NewWithT(t).Expect(queries).To(Equal([]string{
+		"db: DELETE FROM t",
+		"db: /* caller_comment_test.go:" + strconv.Itoa(line+1) + " */ DELETE FROM t",
+	}))
+}
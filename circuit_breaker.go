@@ -0,0 +1,161 @@
+package sqlx
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-courier/logr"
+	"github.com/pkg/errors"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "closed"
+}
+
+// NewCircuitBreaker creates breaker which opens after maxFailures consecutive failures,
+// and fast-fails with ErrCircuitOpen during coolDown.
+// After coolDown, it turns half-open, and only one attempt is allowed to probe.
+func NewCircuitBreaker(maxFailures int, coolDown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		MaxFailures: maxFailures,
+		CoolDown:    coolDown,
+	}
+}
+
+type CircuitBreaker struct {
+	MaxFailures int
+	CoolDown    time.Duration
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Do calls fn when allowed, and records its result
+func (b *CircuitBreaker) Do(ctx context.Context, fn func() error) error {
+	t, err := b.allow()
+	t.log(ctx)
+	if err != nil {
+		return err
+	}
+
+	recorded := false
+	defer func() {
+		// panicked or canceled by caller, nothing about the database, release the probe for others
+		if !recorded {
+			b.mu.Lock()
+			b.probing = false
+			b.mu.Unlock()
+		}
+	}()
+
+	err = fn()
+
+	if err != nil && ctx.Err() != nil {
+		return err
+	}
+
+	recorded = true
+	b.done(err).log(ctx)
+	return err
+}
+
+func (b *CircuitBreaker) allow() (*circuitTransition, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		if time.Since(b.openedAt) < b.CoolDown {
+			return nil, ErrCircuitOpen
+		}
+		b.probing = true
+		return b.transit(CircuitHalfOpen), nil
+	case CircuitHalfOpen:
+		if b.probing {
+			return nil, ErrCircuitOpen
+		}
+		b.probing = true
+	}
+
+	return nil, nil
+}
+
+func (b *CircuitBreaker) done(err error) *circuitTransition {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+
+	if err == nil {
+		b.failures = 0
+		if b.state != CircuitClosed {
+			return b.transit(CircuitClosed)
+		}
+		return nil
+	}
+
+	b.failures++
+
+	if b.state == CircuitHalfOpen || b.failures >= b.MaxFailures {
+		b.openedAt = time.Now()
+		if b.state != CircuitOpen {
+			return b.transit(CircuitOpen)
+		}
+	}
+
+	return nil
+}
+
+// transit should be called under lock, the returned transition is logged after unlocking
+func (b *CircuitBreaker) transit(state CircuitState) *circuitTransition {
+	t := &circuitTransition{from: b.state, to: state, failures: b.failures}
+	b.state = state
+	return t
+}
+
+type circuitTransition struct {
+	from     CircuitState
+	to       CircuitState
+	failures int
+}
+
+func (t *circuitTransition) log(ctx context.Context) {
+	if t == nil {
+		return
+	}
+
+	logger := logr.FromContext(ctx)
+
+	if t.to == CircuitOpen {
+		logger.Warn(fmt.Errorf("circuit breaker %s -> %s after %d consecutive failures", t.from, t.to, t.failures))
+	} else {
+		logger.Info("circuit breaker %s -> %s", t.from, t.to)
+	}
+}
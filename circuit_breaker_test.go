@@ -0,0 +1,104 @@
+package sqlx_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-courier/logr"
+	"github.com/go-courier/sqlx/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+	errDown := errors.New("down")
+
+	b := sqlx.NewCircuitBreaker(2, 20*time.Millisecond)
+
+	calls := 0
+
+	fail := func() error {
+		calls++
+		return errDown
+	}
+
+	NewWithT(t).Expect(b.Do(ctx, fail)).To(Equal(errDown))
+	NewWithT(t).Expect(b.State()).To(Equal(sqlx.CircuitClosed))
+	NewWithT(t).Expect(b.Do(ctx, fail)).To(Equal(errDown))
+	NewWithT(t).Expect(b.State()).To(Equal(sqlx.CircuitOpen))
+
+	t.Run("fast fail when open", func(t *testing.T) {
+		NewWithT(t).Expect(b.Do(ctx, fail)).To(Equal(sqlx.ErrCircuitOpen))
+		NewWithT(t).Expect(calls).To(Equal(2))
+	})
+
+	t.Run("reopen when probe failed", func(t *testing.T) {
+		time.Sleep(30 * time.Millisecond)
+
+		NewWithT(t).Expect(b.Do(ctx, fail)).To(Equal(errDown))
+		NewWithT(t).Expect(calls).To(Equal(3))
+		NewWithT(t).Expect(b.State()).To(Equal(sqlx.CircuitOpen))
+	})
+
+	t.Run("single probe when half-open, and close when probe succeed", func(t *testing.T) {
+		time.Sleep(30 * time.Millisecond)
+
+		err := b.Do(ctx, func() error {
+			NewWithT(t).Expect(b.State()).To(Equal(sqlx.CircuitHalfOpen))
+			NewWithT(t).Expect(b.Do(ctx, fail)).To(Equal(sqlx.ErrCircuitOpen))
+			return nil
+		})
+
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(b.State()).To(Equal(sqlx.CircuitClosed))
+	})
+}
+
+// stateLogger reads state of breaker when logging, which deadlocks when logged under lock
+type stateLogger struct {
+	logr.Logger
+	b      *sqlx.CircuitBreaker
+	states *[]sqlx.CircuitState
+}
+
+func (l *stateLogger) Info(msg string, args ...interface{}) {
+	*l.states = append(*l.states, l.b.State())
+}
+
+func (l *stateLogger) Warn(err error) {
+	*l.states = append(*l.states, l.b.State())
+}
+
+func TestCircuitBreaker_Transition(t *testing.T) {
+	errDown := errors.New("down")
+
+	b := sqlx.NewCircuitBreaker(1, 20*time.Millisecond)
+
+	states := make([]sqlx.CircuitState, 0)
+	ctx := logr.WithLogger(context.Background(), &stateLogger{Logger: logr.Discard(), b: b, states: &states})
+
+	t.Run("logged after unlocking", func(t *testing.T) {
+		NewWithT(t).Expect(b.Do(ctx, func() error { return errDown })).To(Equal(errDown))
+		NewWithT(t).Expect(states).To(Equal([]sqlx.CircuitState{sqlx.CircuitOpen}))
+	})
+
+	t.Run("probe released when panicked", func(t *testing.T) {
+		time.Sleep(30 * time.Millisecond)
+
+		func() {
+			defer func() {
+				NewWithT(t).Expect(recover()).To(Equal("boom"))
+			}()
+			_ = b.Do(ctx, func() error {
+				panic("boom")
+			})
+		}()
+
+		NewWithT(t).Expect(b.State()).To(Equal(sqlx.CircuitHalfOpen))
+		NewWithT(t).Expect(b.Do(ctx, func() error { return nil })).To(BeNil())
+		NewWithT(t).Expect(b.State()).To(Equal(sqlx.CircuitClosed))
+		NewWithT(t).Expect(states).To(Equal([]sqlx.CircuitState{sqlx.CircuitOpen, sqlx.CircuitHalfOpen, sqlx.CircuitClosed}))
+	})
+}
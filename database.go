@@ -5,6 +5,7 @@ import (
 	"database/sql/driver"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/go-courier/sqlx/v2/builder"
 )
@@ -27,6 +28,22 @@ type Database struct {
 	Name   string
 	Schema string
 	Tables builder.Tables
+	// EnumTypes are created and evolved before tables when migrating, if dialect supports
+	EnumTypes builder.EnumTypes
+
+	reservedWordsDialect builder.Dialect
+}
+
+// WithReservedWordsCheck checks tables added and registered by reserved words of the dialect,
+// and panics on collision, which surfaces the problem when constructing rather than at first query.
+func (database Database) WithReservedWordsCheck(dialect builder.Dialect) *Database {
+	database.reservedWordsDialect = dialect
+
+	if err := database.CheckReservedWords(dialect); err != nil {
+		panic(err)
+	}
+
+	return &database
 }
 
 func (database Database) WithSchema(schema string) *Database {
@@ -40,6 +57,14 @@ func (database Database) WithSchema(schema string) *Database {
 
 	database.Tables = tables
 
+	enumTypes := builder.EnumTypes{}
+
+	database.EnumTypes.Range(func(e *builder.EnumType, idx int) {
+		enumTypes.Add(e.WithSchema(database.Schema))
+	})
+
+	database.EnumTypes = enumTypes
+
 	return &database
 }
 
@@ -62,10 +87,36 @@ func (database *Database) OpenDB(connector driver.Connector) *DB {
 	}
 }
 
+// CheckReservedWords checks all tables by the dialect,
+// should be called after all models registered to surface reserved words collision before first query.
+func (database *Database) CheckReservedWords(dialect builder.Dialect) error {
+	msgs := make([]string, 0)
+
+	database.Tables.Range(func(tab *builder.Table, idx int) {
+		if err := tab.CheckReservedWords(dialect); err != nil {
+			msgs = append(msgs, err.Error())
+		}
+	})
+
+	if len(msgs) > 0 {
+		return fmt.Errorf("%s", strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
 func (database *Database) AddTable(table *builder.Table) {
+	if database.reservedWordsDialect != nil {
+		if err := table.CheckReservedWords(database.reservedWordsDialect); err != nil {
+			panic(err)
+		}
+	}
 	database.Tables.Add(table)
 }
 
+func (database *Database) AddEnumType(e *builder.EnumType) {
+	database.EnumTypes.Add(e.WithSchema(database.Schema))
+}
+
 func (database *Database) Register(model builder.Model) *builder.Table {
 	table := builder.TableFromModel(model)
 	table.Schema = database.Schema
@@ -1,6 +1,7 @@
 package sqlx_test
 
 import (
+	"bytes"
 	"context"
 	"database/sql/driver"
 	"os"
@@ -192,6 +193,86 @@ func TestMigrate(t *testing.T) {
 	}
 }
 
+type Org struct {
+	ID   uint64 `db:"f_id"`
+	Name string `db:"f_name,size=255,default=''"`
+}
+
+func (Org) TableName() string {
+	return "t_org"
+}
+
+func (Org) PrimaryKey() []string {
+	return []string{"ID"}
+}
+
+type Member struct {
+	ID    uint64 `db:"f_id"`
+	OrgID uint64 `db:"f_org_id"`
+}
+
+func (Member) TableName() string {
+	return "t_member"
+}
+
+func (Member) PrimaryKey() []string {
+	return []string{"ID"}
+}
+
+func TestMigrate_ForeignKey(t *testing.T) {
+	dbTest := sqlx.NewDatabase("test_for_migrate_fk")
+
+	orgTable := dbTest.Register(&Org{})
+	memberTable := dbTest.Register(&Member{})
+	memberTable.AddForeignKey(builder.FK("fk_org", memberTable.MustFields("OrgID")).References(orgTable, orgTable.MustFields("ID")).OnDeleteAction(builder.ForeignKeyActionCascade))
+
+	for _, connector := range []driver.Connector{
+		mysqlConnector,
+		postgresConnector,
+	} {
+		t.Run("", func(t *testing.T) {
+			db := dbTest.OpenDB(connector)
+
+			err := migration.Migrate(db, nil)
+			NewWithT(t).Expect(err).To(BeNil())
+
+			output := bytes.NewBuffer(nil)
+			err = migration.Migrate(db, output)
+			NewWithT(t).Expect(err).To(BeNil())
+			NewWithT(t).Expect(output.String()).NotTo(ContainSubstring("FOREIGN KEY"))
+
+			_, _ = db.ExecExpr(db.Dialect().DropTable(memberTable))
+			_, _ = db.ExecExpr(db.Dialect().DropTable(orgTable))
+		})
+	}
+}
+
+func TestMigrate_Constraint(t *testing.T) {
+	dbTest := sqlx.NewDatabase("test_for_migrate_constraint")
+
+	orgTable := dbTest.Register(&Org{})
+	orgTable.AddConstraint(builder.Check("c_name", builder.Expr("f_name <> 'admin'")))
+
+	for _, connector := range []driver.Connector{
+		mysqlConnector,
+		postgresConnector,
+	} {
+		t.Run("", func(t *testing.T) {
+			db := dbTest.OpenDB(connector)
+
+			err := migration.Migrate(db, nil)
+			NewWithT(t).Expect(err).To(BeNil())
+
+			output := bytes.NewBuffer(nil)
+			err = migration.Migrate(db, output)
+			NewWithT(t).Expect(err).To(BeNil())
+			NewWithT(t).Expect(output.String()).NotTo(ContainSubstring("CHECK"))
+
+			_, _ = db.ExecExpr(db.Dialect().DropTable(orgTable))
+		})
+	}
+}
+
 func TestCRUD(t *testing.T) {
 	dbTest := sqlx.NewDatabase("test_crud")
 
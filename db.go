@@ -3,6 +3,7 @@ package sqlx
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"time"
 
 	"github.com/pkg/errors"
@@ -58,7 +59,8 @@ type DB struct {
 	dialect builder.Dialect
 	*Database
 	SqlExecutor
-	ctx context.Context
+	ctx           context.Context
+	callerComment bool
 }
 
 func (d *DB) WithContext(ctx context.Context) DBExecutor {
@@ -95,15 +97,26 @@ func (d *DB) D() *Database {
 	return d.Database
 }
 
+// exprContext returns context for rendering expr, with quote of the dialect and toggles of features unsupported by the dialect
+func (d *DB) exprContext() context.Context {
+	ctx := builder.ContextWithQuote(d.Context(), d.dialect.Quote)
+	if rd, ok := d.dialect.(builder.ReturningDialect); ok && !rd.SupportsReturning() {
+		ctx = builder.ContextWithToggles(ctx, builder.Toggles{
+			builder.ToggleReturningUnsupported: true,
+		})
+	}
+	return ctx
+}
+
 func (d *DB) ExecExpr(expr builder.SqlExpr) (sql.Result, error) {
-	e := builder.ResolveExprContext(d.Context(), expr)
+	e := builder.ResolveExprContext(d.exprContext(), expr)
 	if builder.IsNilExpr(e) {
 		return nil, nil
 	}
 	if err := e.Err(); err != nil {
 		return nil, err
 	}
-	result, err := d.ExecContext(d.Context(), e.Query(), e.Args()...)
+	result, err := d.ExecContext(d.Context(), d.withCallerComment(e.Query()), e.Args()...)
 	if err != nil {
 		if d.dialect.IsErrorConflict(err) {
 			return nil, NewSqlError(sqlErrTypeConflict, err.Error())
@@ -114,14 +127,14 @@ func (d *DB) ExecExpr(expr builder.SqlExpr) (sql.Result, error) {
 }
 
 func (d *DB) QueryExpr(expr builder.SqlExpr) (*sql.Rows, error) {
-	e := builder.ResolveExprContext(d.Context(), expr)
+	e := builder.ResolveExprContext(d.exprContext(), expr)
 	if builder.IsNilExpr(e) {
 		return nil, nil
 	}
 	if err := e.Err(); err != nil {
 		return nil, err
 	}
-	return d.QueryContext(d.Context(), e.Query(), e.Args()...)
+	return d.QueryContext(d.Context(), d.withCallerComment(e.Query()), e.Args()...)
 }
 
 func (d *DB) QueryExprAndScan(expr builder.SqlExpr, v interface{}) error {
@@ -150,13 +163,29 @@ func (d *DB) BeginTx(opt *sql.TxOptions) (DBExecutor, error) {
 		return nil, err
 	}
 	return &DB{
-		Database:    d.Database,
-		dialect:     d.dialect,
-		SqlExecutor: db,
-		ctx:         d.Context(),
+		Database:      d.Database,
+		dialect:       d.dialect,
+		SqlExecutor:   db,
+		ctx:           d.Context(),
+		callerComment: d.callerComment,
 	}, nil
 }
 
+// BeginReadOnly begins read-only transaction, which is routed to replicas by MultiConnector,
+// for reading consistent snapshot by multiple queries without loading primary.
+func (d *DB) BeginReadOnly() (DBExecutor, error) {
+	return d.BeginTx(&sql.TxOptions{ReadOnly: true})
+}
+
+// DescribeTxOptions describes isolation level and access mode of transaction, like `Serializable, read only`, for logging
+func DescribeTxOptions(opts driver.TxOptions) string {
+	mode := "read write"
+	if opts.ReadOnly {
+		mode = "read only"
+	}
+	return sql.IsolationLevel(opts.Isolation).String() + ", " + mode
+}
+
 func (d *DB) Commit() error {
 	if !d.IsTx() {
 		return ErrNotTx
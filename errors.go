@@ -26,6 +26,7 @@ var (
 	sqlErrTypeInvalidScanTarget sqlErrType = "InvalidScanTarget"
 	sqlErrTypeNotFound          sqlErrType = "NotFound"
 	sqlErrTypeConflict          sqlErrType = "Conflict"
+	sqlErrTypeStaleVersion      sqlErrType = "StaleVersion"
 )
 
 var DuplicateEntryErrNumber uint16 = 1062
@@ -73,6 +74,13 @@ func (r *dbErr) IsConflict() bool {
 	return false
 }
 
+func (r *dbErr) IsStaleVersion() bool {
+	if sqlErr, ok := UnwrapAll(r.err).(*SqlError); ok {
+		return sqlErr.Type == sqlErrTypeStaleVersion
+	}
+	return false
+}
+
 func (r *dbErr) Err() error {
 	if r.err == nil {
 		return nil
@@ -1,6 +1,9 @@
 package sqlx
 
 import (
+	"fmt"
+	"reflect"
+
 	"github.com/go-courier/sqlx/v2/builder"
 )
 
@@ -10,6 +13,41 @@ func InsertToDB(db DBExecutor, model builder.Model, zeroFields []string, additio
 	return builder.Insert().Into(table, additions...).Values(cols, vals...)
 }
 
+// InsertIgnoreToDB builds insert statement which skips the row when conflicted,
+// renders as `INSERT IGNORE` for mysql and `ON CONFLICT DO NOTHING` for postgres.
+// conflictFields are the conflict target for postgres, could be empty to match any constraint.
+func InsertIgnoreToDB(db DBExecutor, model builder.Model, conflictFields []string, zeroFields []string, additions ...builder.Addition) builder.SqlExpr {
+	table := db.T(model)
+	cols, vals := table.ColumnsAndValuesByFieldValues(FieldValuesFromModel(table, model, zeroFields...))
+
+	switch db.Dialect().DriverName() {
+	case "mysql":
+		return builder.Insert("IGNORE").Into(table, additions...).Values(cols, vals...)
+	default:
+		var conflictCols *builder.Columns
+		if len(conflictFields) > 0 {
+			conflictCols = table.MustFields(conflictFields...)
+		}
+		// copied to keep additions of caller untouched
+		finalAdditions := append(make([]builder.Addition, 0, len(additions)+1), additions...)
+		finalAdditions = append(finalAdditions, builder.OnConflict(conflictCols).DoNothing())
+		return builder.Insert().Into(table, finalAdditions...).Values(cols, vals...)
+	}
+}
+
+// InsertIgnore inserts model if absent, and returns whether a row was actually inserted
+func InsertIgnore(db DBExecutor, model builder.Model, conflictFields []string, zeroFields []string, additions ...builder.Addition) (bool, error) {
+	result, err := db.ExecExpr(InsertIgnoreToDB(db, model, conflictFields, zeroFields, additions...))
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
 func AsAssignments(db DBExecutor, model builder.Model, zeroFields ...string) builder.Assignments {
 	table := db.T(model)
 	return table.AssignmentsByFieldValues(FieldValuesFromModel(table, model, zeroFields...))
@@ -22,3 +60,63 @@ func FieldValuesFromModel(table *builder.Table, model builder.Model, zeroFields
 	}
 	return fieldValues
 }
+
+// UpdateWithVersion updates rows matched by condition with optimistic locking.
+//
+// The version column (tagged by `db:"f_version,version"`) is increased by one,
+// and the version loaded in model is appended to condition.
+// When no rows affected, a StaleVersion SqlError returned,
+// otherwise the version field of model will be synced to the increased one.
+func UpdateWithVersion(db DBExecutor, model builder.Model, condition builder.SqlCondition, fieldValues builder.FieldValues, additions ...builder.Addition) error {
+	table := db.T(model)
+
+	versionCol := table.Version()
+	if versionCol == nil {
+		return fmt.Errorf("missing version column of table %s", table.Name)
+	}
+
+	versionField := reflect.Indirect(reflect.ValueOf(model)).FieldByName(versionCol.FieldName)
+	if !versionField.IsValid() {
+		return fmt.Errorf("missing version field %s of model %T", versionCol.FieldName, model)
+	}
+
+	loadedVersion := versionField.Interface()
+
+	finalFieldValues := builder.FieldValues{}
+	for fieldName, value := range fieldValues {
+		if fieldName != versionCol.FieldName {
+			finalFieldValues[fieldName] = value
+		}
+	}
+
+	assignments := append(table.AssignmentsByFieldValues(finalFieldValues), versionCol.ValueBy(versionCol.Incr(1)))
+
+	result, err := db.ExecExpr(
+		builder.Update(table).
+			Set(assignments...).
+			Where(builder.And(condition, versionCol.Eq(loadedVersion)), additions...),
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return NewSqlError(sqlErrTypeStaleVersion, fmt.Sprintf("stale version %v of table %s", loadedVersion, table.Name))
+	}
+
+	if versionField.CanSet() {
+		switch versionField.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			versionField.SetInt(versionField.Int() + 1)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			versionField.SetUint(versionField.Uint() + 1)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package sqlx_test
+
+import (
+	"testing"
+
+	"github.com/go-courier/sqlx/v2"
+	"github.com/go-courier/sqlx/v2/builder"
+	. "github.com/onsi/gomega"
+)
+
+type fakeDialect struct {
+	builder.Dialect
+	driverName string
+}
+
+func (d *fakeDialect) DriverName() string {
+	return d.driverName
+}
+
+func (d *fakeDialect) Quote(ident string) string {
+	return ident
+}
+
+type VersionedUser struct {
+	ID      uint64 `db:"f_id,autoincrement"`
+	Name    string `db:"f_name"`
+	Version uint64 `db:"f_version,version"`
+}
+
+func (VersionedUser) TableName() string {
+	return "t_versioned_user"
+}
+
+type UnversionedUser struct {
+	ID   uint64 `db:"f_id,autoincrement"`
+	Name string `db:"f_name"`
+}
+
+func (UnversionedUser) TableName() string {
+	return "t_unversioned_user"
+}
+
+func TestUpdateWithVersion(t *testing.T) {
+	d := sqlx.NewDatabase("test")
+	table := d.Register(&VersionedUser{})
+	d.Register(&UnversionedUser{})
+
+	t.Run("updated", func(t *testing.T) {
+		queries := make([]string, 0)
+		db := d.OpenDB(&fakeConnector{Dialect: &fakeDialect{driverName: "postgres"}, name: "db", queries: &queries})
+
+		user := &VersionedUser{ID: 1, Version: 3}
+		err := sqlx.UpdateWithVersion(db, user, table.F("ID").Eq(1), builder.FieldValues{"Name": "name", "Version": 10})
+
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(user.Version).To(Equal(uint64(4)))
+		NewWithT(t).Expect(queries).To(Equal([]string{
+			"db: UPDATE t_versioned_user SET f_name = ?, f_version = f_version + ?\nWHERE (f_id = ?) AND (f_version = ?)",
+		}))
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		queries := make([]string, 0)
+		db := d.OpenDB(&fakeConnector{Dialect: &fakeDialect{driverName: "postgres"}, name: "db", queries: &queries, noRowsAffected: true})
+
+		user := &VersionedUser{ID: 1, Version: 3}
+		err := sqlx.UpdateWithVersion(db, user, table.F("ID").Eq(1), builder.FieldValues{"Name": "name"})
+
+		NewWithT(t).Expect(sqlx.DBErr(err).IsStaleVersion()).To(BeTrue())
+		NewWithT(t).Expect(err).To(MatchError("Sqlx [StaleVersion] stale version 3 of table t_versioned_user"))
+		NewWithT(t).Expect(user.Version).To(Equal(uint64(3)))
+	})
+
+	t.Run("missing version column", func(t *testing.T) {
+		queries := make([]string, 0)
+		db := d.OpenDB(&fakeConnector{Dialect: &fakeDialect{driverName: "postgres"}, name: "db", queries: &queries})
+
+		err := sqlx.UpdateWithVersion(db, &UnversionedUser{ID: 1}, builder.EmptyCond(), builder.FieldValues{"Name": "name"})
+
+		NewWithT(t).Expect(err).To(MatchError("missing version column of table t_unversioned_user"))
+		NewWithT(t).Expect(queries).To(BeEmpty())
+	})
+}
+
+func TestInsertIgnoreToDB(t *testing.T) {
+	d := sqlx.NewDatabase("test")
+	d.Register(&VersionedUser{})
+
+	user := &VersionedUser{Name: "name"}
+
+	t.Run("mysql", func(t *testing.T) {
+		db := d.OpenDB(&fakeConnector{Dialect: &fakeDialect{driverName: "mysql"}, name: "db", queries: &[]string{}})
+
+		e := builder.ResolveExpr(sqlx.InsertIgnoreToDB(db, user, nil, []string{"Version"}))
+		NewWithT(t).Expect(e.Query()).To(Equal("INSERT IGNORE INTO t_versioned_user (f_name,f_version) VALUES (?,?)"))
+	})
+
+	t.Run("postgres", func(t *testing.T) {
+		db := d.OpenDB(&fakeConnector{Dialect: &fakeDialect{driverName: "postgres"}, name: "db", queries: &[]string{}})
+
+		additions := make([]builder.Addition, 0, 2)
+		additions = append(additions, builder.Comment("insert"))
+
+		e := builder.ResolveExpr(sqlx.InsertIgnoreToDB(db, user, []string{"Name"}, []string{"Version"}, additions...))
+		NewWithT(t).Expect(e.Query()).To(Equal("INSERT INTO t_versioned_user (f_name,f_version) VALUES (?,?)\nON CONFLICT (f_name) DO NOTHING\n/* insert */"))
+		NewWithT(t).Expect(additions[:cap(additions)][1]).To(BeNil())
+	})
+}
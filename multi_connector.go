@@ -0,0 +1,327 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/go-courier/sqlx/v2/builder"
+)
+
+var _ interface {
+	driver.Connector
+	builder.Dialect
+	DBNameBinder
+	Migrator
+} = (*MultiConnector)(nil)
+
+type ReplicaBalance int
+
+const (
+	ReplicaBalanceRoundRobin ReplicaBalance = iota
+	ReplicaBalanceLeastConns
+)
+
+// NewMultiConnector creates connector which routes writes to primary and reads to replicas.
+// primary should implement builder.Dialect, which is used as dialect of the connector.
+func NewMultiConnector(primary driver.Connector, replicas ...driver.Connector) *MultiConnector {
+	dialect, ok := primary.(builder.Dialect)
+	if !ok {
+		panic(fmt.Errorf("primary connector should implement builder.Dialect"))
+	}
+	return &MultiConnector{
+		Dialect:  dialect,
+		Primary:  primary,
+		Replicas: replicas,
+		conns:    make([]int64, len(replicas)),
+	}
+}
+
+// MultiConnector holds one primary and N replicas.
+// Each connection of it connects primary and one of replicas lazily.
+// Queries are routed by ResolveAccessMode, and all queries in tx go to the conn began the tx,
+// which is primary, or replica for read-only tx unless ContextWithReadPrimary.
+// Reads fall back to primary when all replicas are down.
+type MultiConnector struct {
+	builder.Dialect
+	Primary  driver.Connector
+	Replicas []driver.Connector
+	Balance  ReplicaBalance
+
+	next  uint32
+	conns []int64
+}
+
+func (c MultiConnector) WithDBName(dbName string) driver.Connector {
+	if dbNameBinder, ok := c.Primary.(DBNameBinder); ok {
+		c.Primary = dbNameBinder.WithDBName(dbName)
+	}
+
+	replicas := make([]driver.Connector, len(c.Replicas))
+	for i := range c.Replicas {
+		replicas[i] = c.Replicas[i]
+		if dbNameBinder, ok := replicas[i].(DBNameBinder); ok {
+			replicas[i] = dbNameBinder.WithDBName(dbName)
+		}
+	}
+
+	mc := NewMultiConnector(c.Primary, replicas...)
+	mc.Balance = c.Balance
+	return mc
+}
+
+func (c *MultiConnector) Migrate(ctx context.Context, db DBExecutor) error {
+	if migrator, ok := c.Primary.(Migrator); ok {
+		return migrator.Migrate(ctx, db)
+	}
+	return nil
+}
+
+func (c *MultiConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &multiConn{c: c, replicaIdx: -1}, nil
+}
+
+func (c *MultiConnector) Driver() driver.Driver {
+	return c.Primary.Driver()
+}
+
+// replicaCandidates returns indexes of replicas in the order to try
+func (c *MultiConnector) replicaCandidates() []int {
+	n := len(c.Replicas)
+	idxes := make([]int, 0, n)
+
+	if n == 0 {
+		return idxes
+	}
+
+	start := 0
+
+	switch c.Balance {
+	case ReplicaBalanceLeastConns:
+		least := atomic.LoadInt64(&c.conns[0])
+		for i := 1; i < n; i++ {
+			if conns := atomic.LoadInt64(&c.conns[i]); conns < least {
+				least = conns
+				start = i
+			}
+		}
+	default:
+		start = int((atomic.AddUint32(&c.next, 1) - 1) % uint32(n))
+	}
+
+	for i := 0; i < n; i++ {
+		idxes = append(idxes, (start+i)%n)
+	}
+
+	return idxes
+}
+
+type contextKeyReadPrimary int
+
+// ContextWithReadPrimary forces reads to primary, for read-after-write consistency.
+func ContextWithReadPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKeyReadPrimary(1), true)
+}
+
+func IsReadPrimary(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	readPrimary, _ := ctx.Value(contextKeyReadPrimary(1)).(bool)
+	return readPrimary
+}
+
+type multiConn struct {
+	c          *MultiConnector
+	primary    driver.Conn
+	replica    driver.Conn
+	replicaIdx int
+	// txConn is the conn of current tx, nil when not in tx
+	txConn driver.Conn
+}
+
+func (mc *multiConn) primaryConn(ctx context.Context) (driver.Conn, error) {
+	if mc.primary == nil {
+		conn, err := mc.c.Primary.Connect(ctx)
+		if err != nil {
+			return nil, err
+		}
+		mc.primary = conn
+	}
+	return mc.primary, nil
+}
+
+func (mc *multiConn) replicaConn(ctx context.Context) (driver.Conn, error) {
+	if mc.replica != nil {
+		return mc.replica, nil
+	}
+
+	for _, idx := range mc.c.replicaCandidates() {
+		conn, err := mc.c.Replicas[idx].Connect(ctx)
+		if err != nil {
+			continue
+		}
+		atomic.AddInt64(&mc.c.conns[idx], 1)
+		mc.replica = conn
+		mc.replicaIdx = idx
+		return conn, nil
+	}
+
+	// all replicas are down
+	return mc.primaryConn(ctx)
+}
+
+func (mc *multiConn) closeReplica() error {
+	if mc.replica == nil {
+		return nil
+	}
+	err := mc.replica.Close()
+	if mc.replicaIdx >= 0 {
+		atomic.AddInt64(&mc.c.conns[mc.replicaIdx], -1)
+	}
+	mc.replica = nil
+	mc.replicaIdx = -1
+	return err
+}
+
+func (mc *multiConn) conn(ctx context.Context, query string) (driver.Conn, error) {
+	if mc.txConn != nil {
+		return mc.txConn, nil
+	}
+	if IsReadPrimary(ctx) || ResolveAccessMode(ctx, query) != AccessModeRead {
+		return mc.primaryConn(ctx)
+	}
+	return mc.replicaConn(ctx)
+}
+
+func (mc *multiConn) release(conn driver.Conn, err error) {
+	// drop broken replica, next read will pick another one
+	if err == driver.ErrBadConn && conn == mc.replica {
+		_ = mc.closeReplica()
+	}
+}
+
+func (mc *multiConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	conn, err := mc.conn(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	queryer, ok := conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	rows, err := queryer.QueryContext(ctx, query, args)
+	mc.release(conn, err)
+	return rows, err
+}
+
+func (mc *multiConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	conn, err := mc.conn(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	execer, ok := conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	result, err := execer.ExecContext(ctx, query, args)
+	mc.release(conn, err)
+	return result, err
+}
+
+func (mc *multiConn) Prepare(query string) (driver.Stmt, error) {
+	return mc.PrepareContext(context.Background(), query)
+}
+
+func (mc *multiConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	conn, err := mc.conn(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if preparer, ok := conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+	return conn.Prepare(query)
+}
+
+func (mc *multiConn) Begin() (driver.Tx, error) {
+	return mc.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (mc *multiConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	var conn driver.Conn
+	var err error
+
+	if opts.ReadOnly && !IsReadPrimary(ctx) {
+		conn, err = mc.replicaConn(ctx)
+	} else {
+		conn, err = mc.primaryConn(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var tx driver.Tx
+
+	if beginner, ok := conn.(driver.ConnBeginTx); ok {
+		tx, err = beginner.BeginTx(ctx, opts)
+	} else {
+		tx, err = conn.Begin()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	mc.txConn = conn
+	return &multiTx{Tx: tx, mc: mc}, nil
+}
+
+func (mc *multiConn) Ping(ctx context.Context) error {
+	conn, err := mc.primaryConn(ctx)
+	if err != nil {
+		return err
+	}
+	if pinger, ok := conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (mc *multiConn) ResetSession(ctx context.Context) error {
+	for _, conn := range []driver.Conn{mc.primary, mc.replica} {
+		if resetter, ok := conn.(driver.SessionResetter); ok {
+			if err := resetter.ResetSession(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (mc *multiConn) Close() error {
+	err := mc.closeReplica()
+	if mc.primary != nil {
+		if e := mc.primary.Close(); e != nil && err == nil {
+			err = e
+		}
+		mc.primary = nil
+	}
+	return err
+}
+
+type multiTx struct {
+	driver.Tx
+	mc *multiConn
+}
+
+func (tx *multiTx) Commit() error {
+	tx.mc.txConn = nil
+	return tx.Tx.Commit()
+}
+
+func (tx *multiTx) Rollback() error {
+	tx.mc.txConn = nil
+	return tx.Tx.Rollback()
+}
@@ -0,0 +1,168 @@
+package sqlx_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/go-courier/sqlx/v2"
+	"github.com/go-courier/sqlx/v2/builder"
+	. "github.com/onsi/gomega"
+)
+
+type fakeConnector struct {
+	builder.Dialect
+	name    string
+	down    bool
+	queries *[]string
+	// noRowsAffected makes exec affect no rows
+	noRowsAffected bool
+}
+
+func (c *fakeConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	if c.down {
+		return nil, errors.New(c.name + " is down")
+	}
+	return &fakeConn{c: c}, nil
+}
+
+func (c *fakeConnector) Driver() driver.Driver {
+	return nil
+}
+
+type fakeConn struct {
+	driver.Conn
+	c *fakeConnector
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	*c.c.queries = append(*c.c.queries, c.c.name+": "+query)
+	return &fakeRows{}, nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	*c.c.queries = append(*c.c.queries, c.c.name+": "+query)
+	if c.c.noRowsAffected {
+		return driver.RowsAffected(0), nil
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error   { return nil }
+func (c *fakeConn) Rollback() error { return nil }
+func (c *fakeConn) Close() error    { return nil }
+
+type fakeRows struct{}
+
+func (fakeRows) Columns() []string              { return nil }
+func (fakeRows) Close() error                   { return nil }
+func (fakeRows) Next(dest []driver.Value) error { return io.EOF }
+
+func TestMultiConnector(t *testing.T) {
+	queries := make([]string, 0)
+
+	primary := &fakeConnector{name: "primary", queries: &queries}
+	replica0 := &fakeConnector{name: "replica0", queries: &queries}
+	replica1 := &fakeConnector{name: "replica1", queries: &queries}
+
+	exec := func(db *sql.DB, ctx context.Context, query string) {
+		rows, err := db.QueryContext(ctx, query)
+		NewWithT(t).Expect(err).To(BeNil())
+		_ = rows.Close()
+	}
+
+	t.Run("route by access mode", func(t *testing.T) {
+		queries = queries[0:0]
+		db := sql.OpenDB(sqlx.NewMultiConnector(primary, replica0, replica1))
+		db.SetMaxOpenConns(1)
+
+		exec(db, context.Background(), "SELECT 1")
+		exec(db, context.Background(), "UPDATE t SET f_a = 1")
+		exec(db, sqlx.ContextWithReadPrimary(context.Background()), "SELECT 2")
+
+		tx, err := db.Begin()
+		NewWithT(t).Expect(err).To(BeNil())
+		_, err = tx.Query("SELECT 3")
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(tx.Commit()).To(BeNil())
+
+		NewWithT(t).Expect(queries).To(Equal([]string{
+			"replica0: SELECT 1",
+			"primary: UPDATE t SET f_a = 1",
+			"primary: SELECT 2",
+			"primary: SELECT 3",
+		}))
+	})
+
+	t.Run("read-only tx to replica", func(t *testing.T) {
+		queries = queries[0:0]
+		db := sql.OpenDB(sqlx.NewMultiConnector(primary, replica0))
+		db.SetMaxOpenConns(1)
+
+		tx, err := db.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: true})
+		NewWithT(t).Expect(err).To(BeNil())
+		_, err = tx.Query("SELECT 1")
+		NewWithT(t).Expect(err).To(BeNil())
+		_, err = tx.Query("SELECT 2 FOR UPDATE")
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(tx.Commit()).To(BeNil())
+
+		tx, err = db.BeginTx(sqlx.ContextWithReadPrimary(context.Background()), &sql.TxOptions{ReadOnly: true})
+		NewWithT(t).Expect(err).To(BeNil())
+		_, err = tx.Query("SELECT 3")
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(tx.Commit()).To(BeNil())
+
+		NewWithT(t).Expect(queries).To(Equal([]string{
+			"replica0: SELECT 1",
+			"replica0: SELECT 2 FOR UPDATE",
+			"primary: SELECT 3",
+		}))
+	})
+
+	t.Run("fallback to primary when replicas down", func(t *testing.T) {
+		queries = queries[0:0]
+		replica0.down, replica1.down = true, true
+		defer func() {
+			replica0.down, replica1.down = false, false
+		}()
+
+		db := sql.OpenDB(sqlx.NewMultiConnector(primary, replica0, replica1))
+
+		exec(db, context.Background(), "SELECT 1")
+
+		NewWithT(t).Expect(queries).To(Equal([]string{
+			"primary: SELECT 1",
+		}))
+	})
+
+	t.Run("round robin", func(t *testing.T) {
+		queries = queries[0:0]
+		c := sqlx.NewMultiConnector(primary, replica0, replica1)
+
+		for i := 0; i < 2; i++ {
+			conn, err := c.Connect(context.Background())
+			NewWithT(t).Expect(err).To(BeNil())
+			_, err = conn.(driver.QueryerContext).QueryContext(context.Background(), "SELECT 1", nil)
+			NewWithT(t).Expect(err).To(BeNil())
+		}
+
+		NewWithT(t).Expect(queries).To(Equal([]string{
+			"replica0: SELECT 1",
+			"replica1: SELECT 1",
+		}))
+	})
+}
+
+func TestDescribeTxOptions(t *testing.T) {
+	NewWithT(t).Expect(sqlx.DescribeTxOptions(driver.TxOptions{})).To(Equal("Default, read write"))
+	NewWithT(t).Expect(sqlx.DescribeTxOptions(driver.TxOptions{Isolation: driver.IsolationLevel(sql.LevelSerializable), ReadOnly: true})).
+		To(Equal("Serializable, read only"))
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"database/sql/driver"
 	"fmt"
+	"io"
+	"reflect"
 	"strings"
 	"time"
 
 	"github.com/go-courier/sqlx/v2"
+	"github.com/go-courier/sqlx/v2/builder"
 
 	"github.com/go-courier/logr"
 	"github.com/pkg/errors"
@@ -26,13 +29,22 @@ func (l *logger) Print(args ...interface{}) {
 
 var _ interface {
 	driver.Driver
+	driver.DriverContext
 } = (*MySqlLoggingDriver)(nil)
 
 type MySqlLoggingDriver struct {
 	driver mysql.MySQLDriver
+	// MaxLoggedQueryLength limits the length of interpolated query in logs, 0 means no limit.
+	// only the log output is truncated, the executed query keeps as it is.
+	MaxLoggedQueryLength int
 }
 
 func (d *MySqlLoggingDriver) Open(dsn string) (driver.Conn, error) {
+	return d.open(context.Background(), dsn)
+}
+
+// open keeps logger of ctx on the conn, which is used to log when the conn closed
+func (d *MySqlLoggingDriver) open(ctx context.Context, dsn string) (driver.Conn, error) {
 	cfg, err := mysql.ParseDSN(dsn)
 	if err != nil {
 		return nil, err
@@ -43,13 +55,39 @@ func (d *MySqlLoggingDriver) Open(dsn string) (driver.Conn, error) {
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to open connection: %s", cfg.FormatDSN())
 	}
-	return &loggerConn{Conn: conn, cfg: cfg}, nil
+	return &loggerConn{
+		Conn:                 conn,
+		cfg:                  cfg,
+		logger:               logr.FromContext(ctx),
+		maxLoggedQueryLength: d.MaxLoggedQueryLength,
+		connectedAt:          time.Now(),
+	}, nil
+}
+
+func (d *MySqlLoggingDriver) OpenConnector(dsn string) (driver.Connector, error) {
+	if _, err := mysql.ParseDSN(dsn); err != nil {
+		return nil, err
+	}
+	return &loggingConnector{dsn: dsn, driver: d}, nil
 }
 
 func (d *MySqlLoggingDriver) Driver() driver.Driver {
 	return d
 }
 
+type loggingConnector struct {
+	dsn    string
+	driver *MySqlLoggingDriver
+}
+
+func (c *loggingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.driver.open(ctx, c.dsn)
+}
+
+func (c *loggingConnector) Driver() driver.Driver {
+	return c.driver
+}
+
 var _ interface {
 	driver.ConnBeginTx
 	driver.ExecerContext
@@ -58,13 +96,33 @@ var _ interface {
 
 type loggerConn struct {
 	cfg *mysql.Config
+	// logger of the context the conn opened with, connections are closed by pool without context
+	logger               logr.Logger
+	maxLoggedQueryLength int
+	// connectedAt and queries are logged when closed, for tuning lifetime of conns in pool
+	connectedAt time.Time
+	queries     int
 	driver.Conn
 }
 
+func (c *loggerConn) Close() error {
+	if err := c.Conn.Close(); err != nil {
+		return err
+	}
+	logger := c.logger
+	if logger == nil {
+		logger = logr.Discard()
+	}
+	logger.
+		WithValues("db.conn.age", time.Since(c.connectedAt).String(), "db.conn.queries", c.queries).
+		Debug("connection closed")
+	return nil
+}
+
 func (c *loggerConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
 	logger := logr.FromContext(ctx)
 
-	logger.Debug("=========== Beginning Transaction ===========")
+	logger.Debug("=========== Beginning Transaction (%s) ===========", sqlx.DescribeTxOptions(opts))
 	tx, err := c.Conn.(driver.ConnBeginTx).BeginTx(ctx, opts)
 	if err != nil {
 		logger.Error(errors.Wrap(err, "failed to begin transaction"))
@@ -74,27 +132,28 @@ func (c *loggerConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver
 }
 
 func (c *loggerConn) Prepare(query string) (driver.Stmt, error) {
-	panic(fmt.Errorf("don't use Prepare"))
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *loggerConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	query = bindVars(query)
+
+	stmt, err := c.Conn.Prepare(query)
+	if err != nil {
+		logr.FromContext(ctx).Error(errors.Wrapf(err, "prepare failed: %s", query))
+		return nil, err
+	}
+	return &loggingStmt{Stmt: stmt, conn: c, query: query}, nil
 }
 
 func (c *loggerConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (rows driver.Rows, err error) {
+	c.queries++
+	query = bindVars(query)
 	cost := startTimer()
 	newCtx, logger := logr.Start(ctx, "Query")
 
 	defer func() {
-		q := c.interpolateParams(query, args)
-
-		if err != nil {
-			if mysqlErr, ok := sqlx.UnwrapAll(err).(*mysql.MySQLError); !ok {
-				logger.Error(errors.Wrapf(err, "query failed: %s", q))
-			} else {
-				logger.Warn(errors.Wrapf(mysqlErr, "query failed: %s", q))
-			}
-		} else {
-			logger.WithValues("cost", cost().String()).Debug(q.String())
-		}
-
-		logger.End()
+		rows = c.queryDone(logger, query, args, cost(), rows, err)
 	}()
 
 	rows, err = c.Conn.(driver.QueryerContext).QueryContext(newCtx, query, args)
@@ -102,33 +161,80 @@ func (c *loggerConn) QueryContext(ctx context.Context, query string, args []driv
 }
 
 func (c *loggerConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (result driver.Result, err error) {
+	c.queries++
+	query = bindVars(query)
 	cost := startTimer()
-	newCtx, logger := logr.Start(ctx, "Query")
+	newCtx, logger := logr.Start(ctx, "Exec")
 
 	defer func() {
-		q := c.interpolateParams(query, args)
-
-		if err != nil {
-			if mysqlErr, ok := sqlx.UnwrapAll(err).(*mysql.MySQLError); !ok {
-				logger.Error(errors.Wrapf(mysqlErr, "exec failed: %s", q))
-			} else if mysqlErr.Number == DuplicateEntryErrNumber {
-				logger.Error(errors.Wrapf(mysqlErr, "exec failed: %s", q))
-			} else {
-				logger.Warn(errors.Wrapf(mysqlErr, "exec failed: %s", q))
-			}
-		} else {
-			logger.WithValues("cost", cost().String()).Debug(q.String())
-		}
-
-		logger.End()
+		c.execDone(logger, query, args, cost(), result, err)
 	}()
 
 	result, err = c.Conn.(driver.ExecerContext).ExecContext(newCtx, query, args)
 	return
 }
 
+// queryDone logs failed query at once, or logs succeed query with rows count when rows closed
+func (c *loggerConn) queryDone(logger logr.Logger, query string, args []driver.NamedValue, cost time.Duration, rows driver.Rows, err error) driver.Rows {
+	if err != nil {
+		c.logQuery(logger, query, args, cost, err)
+		return rows
+	}
+
+	return &loggingRows{
+		Rows: rows,
+		done: func(count int) {
+			// cost of query only, time spent on reading rows by caller is excluded
+			c.logQuery(logger.WithValues("db.rows", count), query, args, cost, nil)
+		},
+	}
+}
+
+func (c *loggerConn) execDone(logger logr.Logger, query string, args []driver.NamedValue, cost time.Duration, result driver.Result, err error) {
+	if err == nil {
+		if rowsAffected, e := result.RowsAffected(); e == nil {
+			logger = logger.WithValues("db.rows_affected", rowsAffected)
+		}
+	}
+	c.logExec(logger, query, args, cost, err)
+}
+
+func (c *loggerConn) logQuery(logger logr.Logger, query string, args []driver.NamedValue, cost time.Duration, err error) {
+	q := c.interpolateParams(query, args)
+
+	if err != nil {
+		if mysqlErr, ok := sqlx.UnwrapAll(err).(*mysql.MySQLError); !ok {
+			logger.Error(errors.Wrapf(err, "query failed: %s", q))
+		} else {
+			logger.Warn(errors.Wrapf(mysqlErr, "query failed: %s", q))
+		}
+	} else {
+		logger.WithValues("cost", cost.String()).Debug(q.String())
+	}
+
+	logger.End()
+}
+
+func (c *loggerConn) logExec(logger logr.Logger, query string, args []driver.NamedValue, cost time.Duration, err error) {
+	q := c.interpolateParams(query, args)
+
+	if err != nil {
+		if mysqlErr, ok := sqlx.UnwrapAll(err).(*mysql.MySQLError); !ok {
+			logger.Error(errors.Wrapf(err, "exec failed: %s", q))
+		} else if mysqlErr.Number == DuplicateEntryErrNumber {
+			logger.Warn(errors.Wrapf(mysqlErr, "exec failed: %s", q))
+		} else {
+			logger.Error(errors.Wrapf(mysqlErr, "exec failed: %s", q))
+		}
+	} else {
+		logger.WithValues("cost", cost.String()).Debug(q.String())
+	}
+
+	logger.End()
+}
+
 func (c *loggerConn) interpolateParams(query string, args []driver.NamedValue) fmt.Stringer {
-	return &SqlPrinter{query, args, c.cfg}
+	return sqlx.TruncateQuery(&SqlPrinter{query, args, c.cfg}, c.maxLoggedQueryLength)
 }
 
 type SqlPrinter struct {
@@ -155,6 +261,11 @@ func (p *SqlPrinter) String() string {
 
 var DuplicateEntryErrNumber uint16 = 1062
 
+// bindVars replaces value holders of query by bind vars of mysql
+func bindVars(query string) string {
+	return builder.BindVars(query, MysqlConnector{}.BindVar)
+}
+
 func startTimer() func() time.Duration {
 	startTime := time.Now()
 	return func() time.Duration {
@@ -162,6 +273,122 @@ func startTimer() func() time.Duration {
 	}
 }
 
+var _ interface {
+	driver.StmtExecContext
+	driver.StmtQueryContext
+} = (*loggingStmt)(nil)
+
+type loggingStmt struct {
+	driver.Stmt
+	conn  *loggerConn
+	query string
+}
+
+func (stmt *loggingStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (result driver.Result, err error) {
+	stmt.conn.queries++
+	cost := startTimer()
+	newCtx, logger := logr.Start(ctx, "Exec")
+
+	defer func() {
+		stmt.conn.execDone(logger, stmt.query, args, cost(), result, err)
+	}()
+
+	if execer, ok := stmt.Stmt.(driver.StmtExecContext); ok {
+		return execer.ExecContext(newCtx, args)
+	}
+
+	values, err := namedValueToValue(args)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.Stmt.Exec(values)
+}
+
+func (stmt *loggingStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (rows driver.Rows, err error) {
+	stmt.conn.queries++
+	cost := startTimer()
+	newCtx, logger := logr.Start(ctx, "Query")
+
+	defer func() {
+		rows = stmt.conn.queryDone(logger, stmt.query, args, cost(), rows, err)
+	}()
+
+	if queryer, ok := stmt.Stmt.(driver.StmtQueryContext); ok {
+		return queryer.QueryContext(newCtx, args)
+	}
+
+	values, err := namedValueToValue(args)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.Stmt.Query(values)
+}
+
+type loggingRows struct {
+	driver.Rows
+	count int
+	done  func(count int)
+}
+
+func (r *loggingRows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	if err == nil {
+		r.count++
+	}
+	return err
+}
+
+func (r *loggingRows) Close() error {
+	err := r.Rows.Close()
+	if r.done != nil {
+		r.done(r.count)
+		r.done = nil
+	}
+	return err
+}
+
+func (r *loggingRows) HasNextResultSet() bool {
+	if rows, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return rows.HasNextResultSet()
+	}
+	return false
+}
+
+func (r *loggingRows) NextResultSet() error {
+	if rows, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return rows.NextResultSet()
+	}
+	return io.EOF
+}
+
+func (r *loggingRows) ColumnTypeScanType(index int) reflect.Type {
+	if rows, ok := r.Rows.(driver.RowsColumnTypeScanType); ok {
+		return rows.ColumnTypeScanType(index)
+	}
+	return reflect.TypeOf(new(interface{})).Elem()
+}
+
+func (r *loggingRows) ColumnTypeDatabaseTypeName(index int) string {
+	if rows, ok := r.Rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return rows.ColumnTypeDatabaseTypeName(index)
+	}
+	return ""
+}
+
+func (r *loggingRows) ColumnTypeNullable(index int) (nullable, ok bool) {
+	if rows, ok := r.Rows.(driver.RowsColumnTypeNullable); ok {
+		return rows.ColumnTypeNullable(index)
+	}
+	return false, false
+}
+
+func (r *loggingRows) ColumnTypePrecisionScale(index int) (precision, scale int64, ok bool) {
+	if rows, ok := r.Rows.(driver.RowsColumnTypePrecisionScale); ok {
+		return rows.ColumnTypePrecisionScale(index)
+	}
+	return 0, 0, false
+}
+
 type loggingTx struct {
 	logger logr.Logger
 	driver.Tx
@@ -0,0 +1,297 @@
+package mysqlconnector
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/go-courier/logr"
+	"github.com/go-sql-driver/mysql"
+	"github.com/onsi/gomega"
+)
+
+type recordingLogger struct {
+	logr.Logger
+	values *[]interface{}
+	warns  *[]error
+	errors *[]error
+}
+
+func newRecordingLogger() *recordingLogger {
+	return &recordingLogger{
+		Logger: logr.Discard(),
+		values: &[]interface{}{},
+		warns:  &[]error{},
+		errors: &[]error{},
+	}
+}
+
+func (l *recordingLogger) WithValues(keyAndValues ...interface{}) logr.Logger {
+	*l.values = append(*l.values, keyAndValues...)
+	return l
+}
+
+func (l *recordingLogger) Warn(err error) {
+	*l.warns = append(*l.warns, err)
+}
+
+func (l *recordingLogger) Error(err error) {
+	*l.errors = append(*l.errors, err)
+}
+
+type fakeRows struct {
+	n int
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"f_id"}
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.n == 0 {
+		return io.EOF
+	}
+	r.n--
+	dest[0] = int64(r.n)
+	return nil
+}
+
+type multiResultSetRows struct {
+	fakeRows
+	sets int
+}
+
+func (r *multiResultSetRows) HasNextResultSet() bool {
+	return r.sets > 0
+}
+
+func (r *multiResultSetRows) NextResultSet() error {
+	if r.sets == 0 {
+		return io.EOF
+	}
+	r.sets--
+	r.n = 1
+	return nil
+}
+
+func TestLoggingRows(t *testing.T) {
+	t.Run("rows counted and done once", func(t *testing.T) {
+		counts := make([]int, 0)
+
+		rows := &loggingRows{
+			Rows: &fakeRows{n: 3},
+			done: func(count int) {
+				counts = append(counts, count)
+			},
+		}
+
+		dest := make([]driver.Value, 1)
+		for rows.Next(dest) == nil {
+		}
+
+		gomega.NewWithT(t).Expect(rows.Close()).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(rows.Close()).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(counts).To(gomega.Equal([]int{3}))
+	})
+
+	t.Run("next result set forwarded", func(t *testing.T) {
+		counts := make([]int, 0)
+
+		rows := &loggingRows{
+			Rows: &multiResultSetRows{fakeRows: fakeRows{n: 1}, sets: 1},
+			done: func(count int) {
+				counts = append(counts, count)
+			},
+		}
+
+		dest := make([]driver.Value, 1)
+		for rows.Next(dest) == nil {
+		}
+
+		gomega.NewWithT(t).Expect(rows.HasNextResultSet()).To(gomega.BeTrue())
+		gomega.NewWithT(t).Expect(rows.NextResultSet()).To(gomega.BeNil())
+
+		for rows.Next(dest) == nil {
+		}
+
+		gomega.NewWithT(t).Expect(rows.HasNextResultSet()).To(gomega.BeFalse())
+		gomega.NewWithT(t).Expect(rows.NextResultSet()).To(gomega.Equal(io.EOF))
+
+		gomega.NewWithT(t).Expect(rows.Close()).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(counts).To(gomega.Equal([]int{2}))
+	})
+
+	t.Run("single result set", func(t *testing.T) {
+		rows := &loggingRows{Rows: &fakeRows{}}
+
+		gomega.NewWithT(t).Expect(rows.HasNextResultSet()).To(gomega.BeFalse())
+		gomega.NewWithT(t).Expect(rows.NextResultSet()).To(gomega.Equal(io.EOF))
+	})
+}
+
+func TestLoggerConn_ExecDone(t *testing.T) {
+	c := &loggerConn{cfg: mysql.NewConfig()}
+
+	t.Run("rows affected logged", func(t *testing.T) {
+		l := newRecordingLogger()
+
+		c.execDone(l, "DELETE FROM t", nil, 0, driver.RowsAffected(2), nil)
+
+		gomega.NewWithT(t).Expect(*l.values).To(gomega.ContainElements("db.rows_affected", int64(2)))
+		gomega.NewWithT(t).Expect(*l.errors).To(gomega.BeEmpty())
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		l := newRecordingLogger()
+
+		c.execDone(l, "DELETE FROM t", nil, 0, nil, io.ErrUnexpectedEOF)
+
+		gomega.NewWithT(t).Expect(*l.values).NotTo(gomega.ContainElement("db.rows_affected"))
+		gomega.NewWithT(t).Expect(*l.errors).To(gomega.HaveLen(1))
+		gomega.NewWithT(t).Expect((*l.errors)[0]).To(gomega.MatchError("exec failed: DELETE FROM t: unexpected EOF"))
+	})
+}
+
+func TestMySqlLoggingDriver_OpenConnector(t *testing.T) {
+	d := &MySqlLoggingDriver{}
+
+	t.Run("invalid dsn", func(t *testing.T) {
+		_, err := d.OpenConnector("root@localhost/db")
+		gomega.NewWithT(t).Expect(err).NotTo(gomega.BeNil())
+	})
+
+	t.Run("connect", func(t *testing.T) {
+		connector, err := d.OpenConnector("root:secret@tcp(127.0.0.1:1)/db?timeout=1s")
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(connector.Driver()).To(gomega.BeIdenticalTo(d))
+
+		_, err = connector.Connect(context.Background())
+		gomega.NewWithT(t).Expect(err).To(gomega.MatchError(gomega.ContainSubstring("failed to open connection: root:******@tcp(127.0.0.1:1)/db")))
+		gomega.NewWithT(t).Expect(err.Error()).NotTo(gomega.ContainSubstring("secret"))
+	})
+}
+
+func TestLoggerConn_LogExecFailed(t *testing.T) {
+	c := &loggerConn{cfg: mysql.NewConfig()}
+
+	cases := map[string]struct {
+		err   error
+		warns int
+	}{
+		"duplicate entry": {&mysql.MySQLError{Number: DuplicateEntryErrNumber, Message: "Duplicate entry"}, 1},
+		"mysql error":     {&mysql.MySQLError{Number: 1146, Message: "Table doesn't exist"}, 0},
+		"other error":     {io.ErrUnexpectedEOF, 0},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			l := newRecordingLogger()
+
+			c.logExec(l, "INSERT INTO t (f_id) VALUES (1)", nil, 0, tc.err)
+
+			gomega.NewWithT(t).Expect(*l.warns).To(gomega.HaveLen(tc.warns))
+			gomega.NewWithT(t).Expect(*l.errors).To(gomega.HaveLen(1 - tc.warns))
+		})
+	}
+
+	t.Run("query failed by mysql error", func(t *testing.T) {
+		l := newRecordingLogger()
+
+		c.logQuery(l, "SELECT * FROM t", nil, 0, &mysql.MySQLError{Number: 1146, Message: "Table doesn't exist"})
+
+		gomega.NewWithT(t).Expect(*l.warns).To(gomega.HaveLen(1))
+		gomega.NewWithT(t).Expect(*l.errors).To(gomega.BeEmpty())
+	})
+}
+
+func TestLoggerConn_InterpolateParams(t *testing.T) {
+	query := "SELECT * FROM t WHERE f_name = ?"
+	args := []driver.NamedValue{{Ordinal: 1, Value: "名字"}}
+
+	t.Run("no limit", func(t *testing.T) {
+		c := &loggerConn{cfg: mysql.NewConfig()}
+		gomega.NewWithT(t).Expect(c.interpolateParams(query, args).String()).To(gomega.Equal("SELECT * FROM t WHERE f_name = '名字'"))
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		c := &loggerConn{cfg: mysql.NewConfig(), maxLoggedQueryLength: 35}
+		gomega.NewWithT(t).Expect(c.interpolateParams(query, args).String()).To(gomega.Equal("SELECT * FROM t WHERE f_name = '名...(truncated, 4 bytes)"))
+	})
+}
+
+type fakeConn struct {
+	queries *[]string
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{query: query, queries: c.queries}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, driver.ErrSkip
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	*c.queries = append(*c.queries, query)
+	return driver.RowsAffected(0), nil
+}
+
+type fakeStmt struct {
+	query   string
+	queries *[]string
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	*s.queries = append(*s.queries, s.query)
+	return driver.RowsAffected(0), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	*s.queries = append(*s.queries, s.query)
+	return &fakeRows{}, nil
+}
+
+func TestLoggerConn_CountQueries(t *testing.T) {
+	queries := make([]string, 0)
+	l := newRecordingLogger()
+
+	c := &loggerConn{Conn: &fakeConn{queries: &queries}, cfg: mysql.NewConfig(), logger: l, connectedAt: time.Now()}
+
+	_, err := c.ExecContext(context.Background(), "UPDATE t SET f_a = 1", nil)
+	gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+
+	stmt, err := c.PrepareContext(context.Background(), "UPDATE t SET f_a = ?")
+	gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+
+	_, err = stmt.(driver.StmtExecContext).ExecContext(context.Background(), []driver.NamedValue{{Ordinal: 1, Value: "a"}})
+	gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+
+	rows, err := stmt.(driver.StmtQueryContext).QueryContext(context.Background(), []driver.NamedValue{{Ordinal: 1, Value: "a"}})
+	gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+	gomega.NewWithT(t).Expect(rows.Close()).To(gomega.BeNil())
+
+	gomega.NewWithT(t).Expect(queries).To(gomega.HaveLen(3))
+	gomega.NewWithT(t).Expect(c.queries).To(gomega.Equal(3))
+
+	// logged by logger of the context the conn opened with
+	gomega.NewWithT(t).Expect(c.Close()).To(gomega.BeNil())
+	gomega.NewWithT(t).Expect(*l.values).To(gomega.ContainElements("db.conn.queries", 3))
+}
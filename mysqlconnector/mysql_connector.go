@@ -27,6 +27,13 @@ type MysqlConnector struct {
 	Extra   string
 	Engine  string
 	Charset string
+	// MaxLoggedQueryLength limits the length of interpolated query in logs, 0 means no limit
+	MaxLoggedQueryLength int
+	// CircuitBreaker fast-fails connecting during database outages, optional
+	CircuitBreaker *sqlx.CircuitBreaker
+	// IfExists guards creating tables by IF NOT EXISTS,
+	// columns and indexes are not guarded, which mysql could not do
+	IfExists bool
 }
 
 func dsn(host string, dbName string, extra string) string {
@@ -59,7 +66,7 @@ func (c *MysqlConnector) Migrate(ctx context.Context, db sqlx.DBExecutor) error
 		}
 
 		if output != nil {
-			_, _ = io.WriteString(output, builder.ResolveExpr(expr).Query())
+			_, _ = io.WriteString(output, builder.ResolveExprContext(builder.ContextWithQuote(ctx, dialect.Quote), expr).Query())
 			_, _ = io.WriteString(output, "\n")
 			return nil
 		}
@@ -78,11 +85,37 @@ func (c *MysqlConnector) Migrate(ctx context.Context, db sqlx.DBExecutor) error
 		}
 	}
 
-	for _, name := range d.Tables.TableNames() {
-		table := d.Tables.Table(name)
-		prevTable := prevDB.Table(name)
+	tables := d.Tables.WithHistoryTables()
+
+	sorted, cyclic := tables.CreationOrder()
+	fks := make([]builder.SqlExpr, 0)
+
+	for _, table := range sorted {
+		prevTable := prevDB.Table(table.Name)
+
+		if prevTable == nil && table.RenameFrom != "" {
+			if renamedFrom := prevDB.Table(table.RenameFrom); renamedFrom != nil {
+				if err := exec(dialect.RenameTable(renamedFrom, table)); err != nil {
+					return err
+				}
+				prevTable = renamedFrom.Clone()
+				prevTable.Name = table.Name
+			}
+		}
 
 		if prevTable == nil {
+			if cyclic {
+				for _, expr := range dialect.CreateTableIsNotExists(table.WithoutForeignKeys()) {
+					if err := exec(expr); err != nil {
+						return err
+					}
+				}
+				table.ForeignKeys.Range(func(fk *builder.ForeignKey, idx int) {
+					fks = append(fks, dialect.AddForeignKey(fk))
+				})
+				continue
+			}
+
 			for _, expr := range dialect.CreateTableIsNotExists(table) {
 				if err := exec(expr); err != nil {
 					return err
@@ -91,19 +124,43 @@ func (c *MysqlConnector) Migrate(ctx context.Context, db sqlx.DBExecutor) error
 			continue
 		}
 
-		exprList := table.Diff(prevTable, dialect)
-
-		for _, expr := range exprList {
-			if err := exec(expr); err != nil {
+		for _, action := range table.DiffActions(prevTable, dialect) {
+			if cyclic && action.Kind == builder.DiffActionAddForeignKey {
+				fks = append(fks, action.Expr)
+				continue
+			}
+			if err := exec(action.Expr); err != nil {
 				return err
 			}
 		}
 	}
 
+	// foreign keys in cycle could only be added when all tables created
+	for _, expr := range fks {
+		if err := exec(expr); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
 func (c *MysqlConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	if c.CircuitBreaker == nil {
+		return c.connect(ctx)
+	}
+
+	var conn driver.Conn
+
+	err := c.CircuitBreaker.Do(ctx, func() (err error) {
+		conn, err = c.connect(ctx)
+		return err
+	})
+
+	return conn, err
+}
+
+func (c *MysqlConnector) connect(ctx context.Context) (driver.Conn, error) {
 	d := c.Driver()
 
 	conn, err := d.Open(dsn(c.Host, c.DBName, c.Extra))
@@ -119,7 +176,7 @@ func (c *MysqlConnector) Connect(ctx context.Context) (driver.Conn, error) {
 			if err := conn.Close(); err != nil {
 				return nil, err
 			}
-			return c.Connect(ctx)
+			return c.connect(ctx)
 		}
 		return nil, err
 	}
@@ -127,17 +184,37 @@ func (c *MysqlConnector) Connect(ctx context.Context) (driver.Conn, error) {
 }
 
 func (c MysqlConnector) Driver() driver.Driver {
-	return (&MySqlLoggingDriver{}).Driver()
+	return (&MySqlLoggingDriver{MaxLoggedQueryLength: c.MaxLoggedQueryLength}).Driver()
 }
 
 func (MysqlConnector) DriverName() string {
 	return "mysql"
 }
 
+// Quote quotes ident by backticks when NeedsQuote
+func (c MysqlConnector) Quote(ident string) string {
+	if !builder.NeedsQuote(ident, c.IsReservedWord) {
+		return ident
+	}
+	return "`" + strings.Replace(ident, "`", "``", -1) + "`"
+}
+
+func (MysqlConnector) BindVar(i int) string {
+	return "?"
+}
+
+func (MysqlConnector) SupportsReturning() bool {
+	return false
+}
+
 func (MysqlConnector) PrimaryKeyName() string {
 	return "primary"
 }
 
+func (MysqlConnector) IsReservedWord(name string) bool {
+	return reservedWords[strings.ToLower(name)]
+}
+
 func (c MysqlConnector) IsErrorUnknownDatabase(err error) bool {
 	if mysqlErr, ok := sqlx.UnwrapAll(err).(*mysql.MySQLError); ok && mysqlErr.Number == 1049 {
 		return true
@@ -173,16 +250,28 @@ func (c *MysqlConnector) DropDatabase(dbName string) builder.SqlExpr {
 	return e
 }
 
+func (c *MysqlConnector) AddPrimaryKey(key *builder.Key) builder.SqlExpr {
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(key.Table)
+	e.WriteString(" ADD PRIMARY KEY ")
+	e.WriteGroup(func(e *builder.Ex) {
+		e.WriteExpr(key.Columns)
+	})
+	e.WriteEnd()
+	return e
+}
+
+func (c *MysqlConnector) DropPrimaryKey(key *builder.Key) builder.SqlExpr {
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(key.Table)
+	e.WriteString(" DROP PRIMARY KEY")
+	e.WriteEnd()
+	return e
+}
+
 func (c *MysqlConnector) AddIndex(key *builder.Key) builder.SqlExpr {
 	if key.IsPrimary() {
-		e := builder.Expr("ALTER TABLE ")
-		e.WriteExpr(key.Table)
-		e.WriteString(" ADD PRIMARY KEY ")
-		e.WriteGroup(func(e *builder.Ex) {
-			e.WriteExpr(key.Columns)
-		})
-		e.WriteEnd()
-		return e
+		return c.AddPrimaryKey(key)
 	}
 
 	e := builder.Expr("CREATE ")
@@ -193,13 +282,13 @@ func (c *MysqlConnector) AddIndex(key *builder.Key) builder.SqlExpr {
 	}
 	e.WriteString("INDEX ")
 
-	e.WriteString(key.Name)
+	e.WriteString(c.Quote(key.Name))
 
 	e.WriteString(" ON ")
 	e.WriteExpr(key.Table)
 	e.WriteByte(' ')
 	e.WriteGroup(func(e *builder.Ex) {
-		e.WriteExpr(key.Columns)
+		c.writeKeyParts(e, key)
 	})
 
 	if key.Method == "BTREE" || key.Method == "HASH" {
@@ -208,21 +297,45 @@ func (c *MysqlConnector) AddIndex(key *builder.Key) builder.SqlExpr {
 	}
 
 	e.WriteEnd()
+
+	if key.IsPartial() {
+		return builder.ExprWithErr(e, fmt.Errorf("partial index %s of table %s is not supported by mysql", key.Name, key.Table.Name))
+	}
+
+	if key.HasNullsOrders() {
+		return builder.ExprWithErr(e, fmt.Errorf("nulls ordering of index %s of table %s is not supported by mysql", key.Name, key.Table.Name))
+	}
+
 	return e
 }
 
+// writeKeyParts writes columns of key, then its expressions as functional key parts which are wrapped with parentheses,
+// functional key parts are supported since mysql 8.0.13
+func (c *MysqlConnector) writeKeyParts(e *builder.Ex, key *builder.Key) {
+	if !key.Columns.IsNil() {
+		cols := *key
+		cols.Exprs = nil
+		e.WriteExpr(cols.Members())
+	}
+
+	for i, expr := range key.Exprs {
+		if i > 0 || !key.Columns.IsNil() {
+			e.WriteByte(',')
+		}
+		e.WriteGroup(func(e *builder.Ex) {
+			e.WriteString(expr)
+		})
+	}
+}
+
 func (c *MysqlConnector) DropIndex(key *builder.Key) builder.SqlExpr {
 	if key.IsPrimary() {
-		e := builder.Expr("ALTER TABLE ")
-		e.WriteExpr(key.Table)
-		e.WriteString(" DROP PRIMARY KEY")
-		e.WriteEnd()
-		return e
+		return c.DropPrimaryKey(key)
 	}
 	e := builder.Expr("DROP ")
 
 	e.WriteString("INDEX ")
-	e.WriteString(key.Name)
+	e.WriteString(c.Quote(key.Name))
 
 	e.WriteString(" ON ")
 	e.WriteExpr(key.Table)
@@ -231,8 +344,96 @@ func (c *MysqlConnector) DropIndex(key *builder.Key) builder.SqlExpr {
 	return e
 }
 
+func (c *MysqlConnector) RenameIndex(key *builder.Key, target *builder.Key) builder.SqlExpr {
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(key.Table)
+	e.WriteString(" RENAME INDEX ")
+	e.WriteString(c.Quote(key.Name))
+	e.WriteString(" TO ")
+	e.WriteString(c.Quote(target.Name))
+	e.WriteEnd()
+	return e
+}
+
+func (c *MysqlConnector) AddForeignKey(fk *builder.ForeignKey) builder.SqlExpr {
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(fk.Table)
+	e.WriteString(" ADD CONSTRAINT ")
+	e.WriteString(c.Quote(fk.Table.Name + "_" + fk.Name))
+	e.WriteString(" FOREIGN KEY ")
+	e.WriteGroup(func(e *builder.Ex) {
+		e.WriteExpr(fk.Columns)
+	})
+	e.WriteString(" REFERENCES ")
+	e.WriteExpr(fk.RefTable)
+	e.WriteByte(' ')
+	e.WriteGroup(func(e *builder.Ex) {
+		e.WriteExpr(fk.RefColumns)
+	})
+
+	if fk.OnDelete != "" {
+		e.WriteString(" ON DELETE ")
+		e.WriteString(fk.OnDelete)
+	}
+
+	if fk.OnUpdate != "" {
+		e.WriteString(" ON UPDATE ")
+		e.WriteString(fk.OnUpdate)
+	}
+
+	e.WriteEnd()
+	return e
+}
+
+func (c *MysqlConnector) DropForeignKey(fk *builder.ForeignKey) builder.SqlExpr {
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(fk.Table)
+	e.WriteString(" DROP FOREIGN KEY ")
+	e.WriteString(c.Quote(fk.Table.Name + "_" + fk.Name))
+	e.WriteEnd()
+	return e
+}
+
+func (c *MysqlConnector) AddConstraint(constraint *builder.Constraint) builder.SqlExpr {
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(constraint.Table)
+	e.WriteString(" ADD ")
+	c.writeCheckConstraint(e, constraint)
+	e.WriteEnd()
+	return e
+}
+
+func (c *MysqlConnector) DropConstraint(constraint *builder.Constraint) builder.SqlExpr {
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(constraint.Table)
+	e.WriteString(" DROP CHECK ")
+	e.WriteString(c.Quote(constraint.Table.Name + "_" + constraint.Name))
+	e.WriteEnd()
+	return e
+}
+
+func (c *MysqlConnector) writeCheckConstraint(e *builder.Ex, constraint *builder.Constraint) {
+	e.WriteString("CONSTRAINT ")
+	e.WriteString(c.Quote(constraint.Table.Name + "_" + constraint.Name))
+	e.WriteString(" CHECK ")
+	e.WriteGroup(func(e *builder.Ex) {
+		e.WriteString(constraint.Def())
+	})
+}
+
 func (c *MysqlConnector) CreateTableIsNotExists(table *builder.Table) (exprs []builder.SqlExpr) {
-	expr := builder.Expr("CREATE TABLE IF NOT EXISTS ")
+	return c.createTable(table, true)
+}
+
+func (c *MysqlConnector) CreateTable(table *builder.Table) builder.SqlExpr {
+	return builder.MultiWith("\n", c.createTable(table, c.IfExists)...)
+}
+
+func (c *MysqlConnector) createTable(table *builder.Table, ifNotExists bool) (exprs []builder.SqlExpr) {
+	expr := builder.Expr("CREATE TABLE ")
+	if ifNotExists {
+		expr.WriteString("IF NOT EXISTS ")
+	}
 	expr.WriteExpr(table)
 	expr.WriteByte(' ')
 	expr.WriteGroup(func(e *builder.Ex) {
@@ -253,7 +454,7 @@ func (c *MysqlConnector) CreateTableIsNotExists(table *builder.Table) (exprs []b
 
 			e.WriteExpr(col)
 			e.WriteByte(' ')
-			e.WriteExpr(c.DataType(col.ColumnType))
+			e.WriteExpr(c.columnDef(col))
 		})
 
 		table.Keys.Range(func(key *builder.Key, idx int) {
@@ -268,6 +469,13 @@ func (c *MysqlConnector) CreateTableIsNotExists(table *builder.Table) (exprs []b
 			}
 		})
 
+		table.Constraints.Range(func(constraint *builder.Constraint, idx int) {
+			e.WriteByte(',')
+			e.WriteByte('\n')
+			e.WriteByte('\t')
+			c.writeCheckConstraint(e, constraint)
+		})
+
 		expr.WriteByte('\n')
 	})
 
@@ -287,6 +495,11 @@ func (c *MysqlConnector) CreateTableIsNotExists(table *builder.Table) (exprs []b
 		expr.WriteString(c.Charset)
 	}
 
+	if comment := table.CommentText(); comment != "" {
+		expr.WriteString(" COMMENT=")
+		expr.WriteString(quoteLiteral(comment))
+	}
+
 	expr.WriteEnd()
 	exprs = append(exprs, expr)
 
@@ -296,19 +509,32 @@ func (c *MysqlConnector) CreateTableIsNotExists(table *builder.Table) (exprs []b
 		}
 	})
 
+	table.ForeignKeys.Range(func(fk *builder.ForeignKey, idx int) {
+		exprs = append(exprs, c.AddForeignKey(fk))
+	})
+
 	return
 }
 
 func (c *MysqlConnector) DropTable(t *builder.Table) builder.SqlExpr {
 	e := builder.Expr("DROP TABLE IF EXISTS ")
-	e.WriteString(t.Name)
+	e.WriteString(c.Quote(t.Name))
+	e.WriteEnd()
+	return e
+}
+
+func (c *MysqlConnector) RenameTable(from *builder.Table, to *builder.Table) builder.SqlExpr {
+	e := builder.Expr("RENAME TABLE ")
+	e.WriteExpr(from)
+	e.WriteString(" TO ")
+	e.WriteExpr(to)
 	e.WriteEnd()
 	return e
 }
 
 func (c *MysqlConnector) TruncateTable(t *builder.Table) builder.SqlExpr {
 	e := builder.Expr("TRUNCATE TABLE ")
-	e.WriteString(t.Name)
+	e.WriteString(c.Quote(t.Name))
 	e.WriteEnd()
 	return e
 }
@@ -319,11 +545,54 @@ func (c *MysqlConnector) AddColumn(col *builder.Column) builder.SqlExpr {
 	e.WriteString(" ADD COLUMN ")
 	e.WriteExpr(col)
 	e.WriteByte(' ')
-	e.WriteExpr(c.DataType(col.ColumnType))
+	e.WriteExpr(c.columnDef(col))
+
+	switch col.After {
+	case "":
+	case builder.ColumnPositionFirst:
+		e.WriteString(" FIRST")
+	default:
+		e.WriteString(" AFTER ")
+		e.WriteString(col.After)
+	}
+
 	e.WriteEnd()
 	return e
 }
 
+// columnDef returns full definition of column, which is data type with generation expression of generated column
+// and comment, for mysql resets attributes not restated when column changed by MODIFY COLUMN or CHANGE.
+func (c *MysqlConnector) columnDef(col *builder.Column) builder.SqlExpr {
+	e := builder.Expr("")
+
+	if col.IsGenerated() {
+		storage := col.GeneratedStorage
+		if storage == "" {
+			storage = builder.GeneratedStorageStored
+		}
+
+		e.WriteString(c.dataType(col.ColumnType.Type, col.ColumnType))
+		e.WriteString(" GENERATED ALWAYS AS ")
+		e.WriteGroup(func(e *builder.Ex) {
+			e.WriteString(col.GeneratedExpr)
+		})
+		e.WriteByte(' ')
+		e.WriteString(storage)
+		if !col.Null {
+			e.WriteString(" NOT NULL")
+		}
+	} else {
+		e.WriteExpr(c.DataType(col.ColumnType))
+	}
+
+	if comment := col.CommentText(); comment != "" {
+		e.WriteString(" COMMENT ")
+		e.WriteString(quoteLiteral(comment))
+	}
+
+	return e
+}
+
 func (c *MysqlConnector) RenameColumn(col *builder.Column, target *builder.Column) builder.SqlExpr {
 	e := builder.Expr("ALTER TABLE ")
 	e.WriteExpr(col.Table)
@@ -332,7 +601,7 @@ func (c *MysqlConnector) RenameColumn(col *builder.Column, target *builder.Colum
 	e.WriteByte(' ')
 	e.WriteExpr(target)
 	e.WriteByte(' ')
-	e.WriteExpr(c.DataType(target.ColumnType))
+	e.WriteExpr(c.columnDef(target))
 	e.WriteEnd()
 	return e
 }
@@ -343,9 +612,9 @@ func (c *MysqlConnector) ModifyColumn(col *builder.Column, prev *builder.Column)
 	e.WriteString(" MODIFY COLUMN ")
 	e.WriteExpr(col)
 	e.WriteByte(' ')
-	e.WriteExpr(c.DataType(col.ColumnType))
+	e.WriteExpr(c.columnDef(col))
 
-	e.WriteString(" /* FROM")
+	e.WriteString(" /* FROM ")
 	e.WriteExpr(c.DataType(prev.ColumnType))
 	e.WriteString(" */")
 
@@ -353,11 +622,138 @@ func (c *MysqlConnector) ModifyColumn(col *builder.Column, prev *builder.Column)
 	return e
 }
 
+// ModifyColumnRisk tells whether ModifyColumn is done in place.
+// Only widening varchar is done in place, when bytes of its length prefix are not changed,
+// https://dev.mysql.com/doc/refman/8.0/en/innodb-online-ddl-operations.html#online-ddl-column-operations
+// other changes (even int to bigint) copy the table.
+func (c *MysqlConnector) ModifyColumnRisk(col *builder.Column, prev *builder.Column) builder.Risk {
+	if !c.isVarchar(col.ColumnType) || !c.isVarchar(prev.ColumnType) {
+		return builder.RiskTableRewrite
+	}
+
+	// only length changed
+	columnType := *col.ColumnType
+	columnType.Length = prev.ColumnType.Length
+	if c.DataType(&columnType).Ex(context.Background()).Query() != c.DataType(prev.ColumnType).Ex(context.Background()).Query() {
+		return builder.RiskTableRewrite
+	}
+
+	length, prevLength := varcharLength(col.ColumnType), varcharLength(prev.ColumnType)
+	if length < prevLength {
+		return builder.RiskTableRewrite
+	}
+
+	// length prefix of varchar is 1 byte when max bytes less than 256, or 2 bytes
+	maxBytes := charsetMaxBytes(col.Charset)
+	if (length*maxBytes < 256) != (prevLength*maxBytes < 256) {
+		return builder.RiskTableRewrite
+	}
+
+	return builder.RiskSafe
+}
+
+func (c *MysqlConnector) isVarchar(columnType *builder.ColumnType) bool {
+	return strings.ToLower(dealias(c.dbDataType(columnType.Type, columnType))) == "varchar"
+}
+
+func varcharLength(columnType *builder.ColumnType) uint64 {
+	if columnType.Length == 0 {
+		return 255
+	}
+	return columnType.Length
+}
+
+// charsetMaxBytes returns max bytes of one char in charset, utf8mb4 as default
+func charsetMaxBytes(charset string) uint64 {
+	switch strings.ToLower(charset) {
+	case "latin1", "ascii", "binary":
+		return 1
+	case "utf8", "utf8mb3":
+		return 3
+	}
+	return 4
+}
+
+// SetColumnDefault alters default of literal value only,
+// expression like CURRENT_TIMESTAMP could not be set by ALTER COLUMN before mysql 8.0.13, the column is modified instead.
+func (c *MysqlConnector) SetColumnDefault(col *builder.Column) builder.SqlExpr {
+	if !isLiteral(*col.Default) {
+		e := builder.Expr("ALTER TABLE ")
+		e.WriteExpr(col.Table)
+		e.WriteString(" MODIFY COLUMN ")
+		e.WriteExpr(col)
+		e.WriteByte(' ')
+		e.WriteExpr(c.columnDef(col))
+		e.WriteEnd()
+		return e
+	}
+
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(col.Table)
+	e.WriteString(" ALTER COLUMN ")
+	e.WriteExpr(col)
+	e.WriteString(" SET DEFAULT ")
+	e.WriteString(*col.Default)
+	e.WriteEnd()
+	return e
+}
+
+func isLiteral(v string) bool {
+	if v == "" {
+		return false
+	}
+	if v[0] == '\'' {
+		return true
+	}
+	_, err := strconv.ParseFloat(v, 64)
+	return err == nil
+}
+
+func (c *MysqlConnector) DropColumnDefault(col *builder.Column) builder.SqlExpr {
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(col.Table)
+	e.WriteString(" ALTER COLUMN ")
+	e.WriteExpr(col)
+	e.WriteString(" DROP DEFAULT")
+	e.WriteEnd()
+	return e
+}
+
+func (c *MysqlConnector) ModifyTableComment(t *builder.Table) builder.SqlExpr {
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(t)
+	e.WriteString(" COMMENT=")
+	e.WriteString(quoteLiteral(t.CommentText()))
+	e.WriteEnd()
+	return e
+}
+
+// ModifyColumnComment modifies column with its definition, mysql could not change comment only
+func (c *MysqlConnector) ModifyColumnComment(col *builder.Column) builder.SqlExpr {
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(col.Table)
+	e.WriteString(" MODIFY COLUMN ")
+	e.WriteExpr(col)
+	e.WriteByte(' ')
+	e.WriteExpr(c.columnDef(col))
+	if col.CommentText() == "" {
+		// comment removed
+		e.WriteString(" COMMENT ''")
+	}
+	e.WriteEnd()
+	return e
+}
+
+func quoteLiteral(s string) string {
+	s = strings.Replace(s, "\\", "\\\\", -1)
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
 func (c *MysqlConnector) DropColumn(col *builder.Column) builder.SqlExpr {
 	e := builder.Expr("ALTER TABLE ")
 	e.WriteExpr(col.Table)
 	e.WriteString(" DROP COLUMN ")
-	e.WriteString(col.Name)
+	e.WriteString(c.Quote(col.Name))
 	e.WriteEnd()
 	return e
 }
@@ -429,6 +825,15 @@ func (c *MysqlConnector) dbDataType(typ reflect.Type, columnType *builder.Column
 func (c *MysqlConnector) dataTypeModify(columnType *builder.ColumnType) string {
 	buf := bytes.NewBuffer(nil)
 
+	if columnType.Charset != "" {
+		buf.WriteString(" CHARACTER SET ")
+		buf.WriteString(columnType.Charset)
+	}
+	if columnType.Collation != "" {
+		buf.WriteString(" COLLATE ")
+		buf.WriteString(columnType.Collation)
+	}
+
 	if !columnType.Null {
 		buf.WriteString(" NOT NULL")
 	}
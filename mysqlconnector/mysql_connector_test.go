@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/go-courier/sqlx/v2"
 	"github.com/go-courier/sqlx/v2/builder"
 	"github.com/go-courier/sqlx/v2/builder/buidertestingutils"
 	"github.com/onsi/gomega"
@@ -30,6 +31,10 @@ func TestMysqlConnector(t *testing.T) {
 		gomega.NewWithT(t).Expect(c.CreateDatabase("db")).
 			To(buidertestingutils.BeExpr( /* language=MySQL */ `CREATE DATABASE db;`))
 	})
+	t.Run("RenameIndex", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(c.RenameIndex(table.Key("I_name"), builder.UniqueIndex("i_nickname", builder.Cols("F_name")).On(table))).
+			To(buidertestingutils.BeExpr( /* language=MySQL */ `ALTER TABLE t RENAME INDEX i_name TO i_nickname;`))
+	})
 	t.Run("DropDatabase", func(t *testing.T) {
 		gomega.NewWithT(t).Expect(c.DropDatabase("db")).
 			To(buidertestingutils.BeExpr( /* language=MySQL */ `DROP DATABASE db;`))
@@ -86,6 +91,16 @@ func TestMysqlConnector(t *testing.T) {
 			c.AddColumn(table.Col("F_name"))).
 			To(buidertestingutils.BeExpr( /* language=MySQL */ "ALTER TABLE t ADD COLUMN f_name varchar(128) NOT NULL DEFAULT '';"))
 	})
+	t.Run("AddColumnAfter", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(
+			c.AddColumn(builder.Col("f_nickname").Type("", ",size=64,default='',after=F_name").On(table))).
+			To(buidertestingutils.BeExpr( /* language=MySQL */ "ALTER TABLE t ADD COLUMN f_nickname varchar(64) NOT NULL DEFAULT '' AFTER f_name;"))
+	})
+	t.Run("AddColumnFirst", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(
+			c.AddColumn(builder.Col("f_nickname").Type("", ",size=64,default='',first").On(table))).
+			To(buidertestingutils.BeExpr( /* language=MySQL */ "ALTER TABLE t ADD COLUMN f_nickname varchar(64) NOT NULL DEFAULT '' FIRST;"))
+	})
 	t.Run("DropColumn", func(t *testing.T) {
 		gomega.NewWithT(t).Expect(
 			c.DropColumn(table.Col("F_name")),
@@ -93,6 +108,181 @@ func TestMysqlConnector(t *testing.T) {
 	})
 }
 
+func TestMysqlConnector_DiffColumnCollation(t *testing.T) {
+	c := &MysqlConnector{}
+
+	prevTable := builder.T("t",
+		builder.Col("f_name").Type("", ",size=128,default='',charset=utf8mb4,collate=utf8mb4_general_ci"),
+		builder.Col("f_desc").Type("", ",size=128,default='',charset=utf8mb4,collate=utf8mb4_general_ci"),
+	)
+
+	table := builder.T("t",
+		builder.Col("f_name").Type("", ",size=128,default='',charset=utf8mb4,collate=utf8mb4_bin"),
+		builder.Col("f_desc").Type("", ",size=128,default=''"),
+	)
+
+	gomega.NewWithT(t).Expect(builder.RenderMigration(table.Diff(prevTable, c), c)).To(gomega.Equal(`-- migration of mysql
+ALTER TABLE t MODIFY COLUMN f_name varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL DEFAULT '' /* FROM varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL DEFAULT '' */;
+`))
+}
+
+func TestMysqlConnector_ColumnDefinitionKept(t *testing.T) {
+	c := &MysqlConnector{}
+
+	described := func(col *builder.Column, description string) *builder.Column {
+		col.Description = []string{description}
+		return col
+	}
+
+	table := builder.T("t",
+		described(builder.Col("f_name").Type("", ",size=64,charset=latin1,default=CURRENT_USER()"), "name"),
+		described(builder.Col("f_desc").Type("", ",size=128,charset=latin1"), "desc"),
+		described(builder.Col("f_total").Type(int64(0), "").GeneratedAs("f_a + f_b", "virtual"), "total"),
+	)
+
+	t.Run("SetColumnDefault of expression", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(c.SetColumnDefault(table.Col("f_name"))).To(buidertestingutils.BeExpr(
+			"ALTER TABLE t MODIFY COLUMN f_name varchar(64) CHARACTER SET latin1 NOT NULL DEFAULT CURRENT_USER() COMMENT 'name';",
+		))
+	})
+
+	t.Run("ModifyColumnComment", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(c.ModifyColumnComment(table.Col("f_desc"))).To(buidertestingutils.BeExpr(
+			"ALTER TABLE t MODIFY COLUMN f_desc varchar(128) CHARACTER SET latin1 NOT NULL COMMENT 'desc';",
+		))
+		gomega.NewWithT(t).Expect(c.ModifyColumnComment(table.Col("f_total"))).To(buidertestingutils.BeExpr(
+			"ALTER TABLE t MODIFY COLUMN f_total bigint GENERATED ALWAYS AS (f_a + f_b) VIRTUAL NOT NULL COMMENT 'total';",
+		))
+	})
+
+	t.Run("ModifyColumnComment removed", func(t *testing.T) {
+		table := builder.T("t", builder.Col("f_desc").Type("", ",size=128,charset=latin1"))
+
+		gomega.NewWithT(t).Expect(c.ModifyColumnComment(table.Col("f_desc"))).To(buidertestingutils.BeExpr(
+			"ALTER TABLE t MODIFY COLUMN f_desc varchar(128) CHARACTER SET latin1 NOT NULL COMMENT '';",
+		))
+	})
+}
+
+func TestMysqlConnector_ModifyColumnRisk(t *testing.T) {
+	c := &MysqlConnector{}
+
+	cases := map[string]struct {
+		prev   string
+		next   string
+		expect builder.Risk
+	}{
+		"widen varchar":                   {",size=32", ",size=50", builder.RiskSafe},
+		"widen varchar over 255 bytes":    {",size=50", ",size=100", builder.RiskTableRewrite},
+		"widen varchar of latin1":         {",size=50,charset=latin1", ",size=100,charset=latin1", builder.RiskSafe},
+		"narrow varchar":                  {",size=50", ",size=32", builder.RiskTableRewrite},
+		"widen varchar and drop not null": {",size=32", ",size=50,null", builder.RiskTableRewrite},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actions := builder.T("t", builder.Col("f_name").Type("", tc.next)).DiffActions(builder.T("t", builder.Col("f_name").Type("", tc.prev)), c)
+
+			gomega.NewWithT(t).Expect(actions).To(gomega.HaveLen(1))
+			gomega.NewWithT(t).Expect(actions[0].Risk).To(gomega.Equal(tc.expect))
+		})
+	}
+
+	t.Run("int to bigint", func(t *testing.T) {
+		actions := builder.T("t", builder.Col("f_count").Type(int64(0), "")).DiffActions(builder.T("t", builder.Col("f_count").Type(int32(0), "")), c)
+
+		gomega.NewWithT(t).Expect(actions).To(gomega.HaveLen(1))
+		gomega.NewWithT(t).Expect(actions[0].Risk).To(gomega.Equal(builder.RiskTableRewrite))
+	})
+}
+
+func TestMysqlConnector_ExprIndex(t *testing.T) {
+	c := &MysqlConnector{}
+
+	table := builder.T("t",
+		builder.Col("f_org_id").Type(uint64(0), ""),
+		builder.Col("f_email").Type("", ",size=128"),
+		builder.ExprIndex("i_email", "lower(f_email)"),
+		builder.UniqueIndex("i_org_email", builder.Cols("f_org_id")).WithExprs("lower(f_email)"),
+	)
+
+	gomega.NewWithT(t).Expect(c.AddIndex(table.Key("i_email"))).To(buidertestingutils.BeExpr("CREATE INDEX i_email ON t ((lower(f_email)));"))
+	gomega.NewWithT(t).Expect(c.AddIndex(table.Key("i_org_email"))).To(buidertestingutils.BeExpr("CREATE UNIQUE INDEX i_org_email ON t (f_org_id,(lower(f_email)));"))
+
+	t.Run("partial index", func(t *testing.T) {
+		key := builder.Index("i_email_active", builder.Cols("f_email"))
+		key.Where = "f_deleted_at = 0"
+		table.AddKey(key)
+
+		e := builder.ResolveExpr(c.AddIndex(table.Key("i_email_active")))
+		gomega.NewWithT(t).Expect(e.Err()).To(gomega.MatchError("partial index i_email_active of table t is not supported by mysql"))
+	})
+}
+
+func TestMysqlConnector_IndexOrder(t *testing.T) {
+	c := &MysqlConnector{}
+
+	table := builder.T("t",
+		builder.Col("f_a").Type(0, ""),
+		builder.Col("f_b").Type(0, ""),
+		builder.Index("i_a_b", builder.Cols("f_a", "f_b")).WithOrder("F_a", builder.KeyColumnOrder{Desc: true}),
+		builder.Index("i_b", builder.Cols("f_b")).WithOrder("f_b", builder.KeyColumnOrder{Nulls: builder.NullsLast}),
+	)
+
+	gomega.NewWithT(t).Expect(c.AddIndex(table.Key("i_a_b"))).To(buidertestingutils.BeExpr("CREATE INDEX i_a_b ON t (f_a DESC,f_b);"))
+
+	e := builder.ResolveExpr(c.AddIndex(table.Key("i_b")))
+	gomega.NewWithT(t).Expect(e.Err()).To(gomega.MatchError("nulls ordering of index i_b of table t is not supported by mysql"))
+}
+
+func TestMysqlConnector_TableComment(t *testing.T) {
+	c := &MysqlConnector{}
+
+	table := builder.T("t", builder.Col("f_id").Type(uint64(0), ""))
+	table.Description = []string{"user", "it's unique"}
+
+	gomega.NewWithT(t).Expect(c.CreateTable(table)).To(buidertestingutils.BeExpr( /* language=MySQL */ `CREATE TABLE t (
+	f_id bigint unsigned NOT NULL
+) ENGINE=InnoDB CHARSET=utf8mb4 COMMENT='user
+it''s unique';`))
+
+	gomega.NewWithT(t).Expect(table.Diff(builder.T("t", builder.Col("f_id").Type(uint64(0), "")), c)).To(gomega.Equal([]builder.SqlExpr{
+		c.ModifyTableComment(table),
+	}))
+	gomega.NewWithT(t).Expect(c.ModifyTableComment(table)).To(buidertestingutils.BeExpr("ALTER TABLE t COMMENT='user\nit''s unique';"))
+}
+
+func TestMysqlConnector_IsReservedWord(t *testing.T) {
+	c := &MysqlConnector{}
+
+	gomega.NewWithT(t).Expect(c.IsReservedWord("key")).To(gomega.BeTrue())
+	gomega.NewWithT(t).Expect(c.IsReservedWord("f_name")).To(gomega.BeFalse())
+
+	gomega.NewWithT(t).Expect(builder.T("order",
+		builder.Col("key"),
+		builder.Col("f_name"),
+		builder.PrimaryKey(builder.Cols("f_name")),
+		builder.Index("index", builder.Cols("f_name")),
+	).CheckReservedWords(c)).
+		To(gomega.MatchError("table order uses reserved words of mysql: order, key, index"))
+
+	t.Run("checked when constructing database", func(t *testing.T) {
+		d := sqlx.NewDatabase("db").WithReservedWordsCheck(c)
+		d.AddTable(builder.T("t", builder.Col("f_name")))
+
+		gomega.NewWithT(t).Expect(func() {
+			d.AddTable(builder.T("t_user", builder.Col("f_name"), builder.Index("index", builder.Cols("f_name"))))
+		}).To(gomega.PanicWith(gomega.MatchError("table t_user uses reserved words of mysql: index")))
+		gomega.NewWithT(t).Expect(d.Tables.TableNames()).To(gomega.Equal([]string{"t"}))
+
+		gomega.NewWithT(t).Expect(func() {
+			d := sqlx.NewDatabase("db")
+			d.AddTable(builder.T("order"))
+			d.WithReservedWordsCheck(c)
+		}).To(gomega.PanicWith(gomega.MatchError("table order uses reserved words of mysql: order")))
+	})
+}
+
 type Point struct {
 	X float64
 	Y float64
@@ -109,3 +299,90 @@ func (Point) ValueEx() string {
 func (p Point) Value() (driver.Value, error) {
 	return fmt.Sprintf("POINT(%v %v)", p.X, p.Y), nil
 }
+
+func TestMysqlConnector_Quote(t *testing.T) {
+	c := MysqlConnector{}
+
+	gomega.NewWithT(t).Expect(c.Quote("order")).To(gomega.Equal("`order`"))
+	gomega.NewWithT(t).Expect(c.Quote("my`table")).To(gomega.Equal("`my``table`"))
+	gomega.NewWithT(t).Expect(c.Quote("f_name")).To(gomega.Equal("f_name"))
+}
+
+func TestAddForeignKeys(t *testing.T) {
+	c := &MysqlConnector{}
+
+	tOrg := builder.T("t_org", builder.Col("f_id").Type(uint64(0), ""))
+
+	cols := []builder.TableDefinition{
+		builder.Col("f_id").Type(uint64(0), ",autoincrement"),
+		builder.Col("f_org_id").Type(uint64(0), ""),
+	}
+
+	table := builder.T("t_user", cols...)
+	table.AddForeignKey(builder.FK("fk_org", builder.Cols("f_org_id")).References(tOrg, builder.Cols("f_id")).OnDeleteAction("cascade"))
+
+	d := sqlx.NewDatabase("db")
+	d.AddTable(builder.T("t_user", cols...))
+
+	// second migration loads foreign keys created by the first one
+	err := addForeignKeys(d, []ForeignKeySchema{
+		{TABLE_NAME: "t_user", CONSTRAINT_NAME: "t_user_fk_org", COLUMN_NAME: "f_org_id", REFERENCED_TABLE_NAME: "t_org", REFERENCED_COLUMN_NAME: "f_id", UPDATE_RULE: "NO ACTION", DELETE_RULE: "CASCADE"},
+	})
+	gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+
+	loaded := d.Table("t_user")
+
+	gomega.NewWithT(t).Expect(table.DiffActions(loaded, c)).To(gomega.BeEmpty())
+	gomega.NewWithT(t).Expect(isForeignKeyIndex(loaded, "t_user_fk_org")).To(gomega.BeTrue())
+	gomega.NewWithT(t).Expect(isForeignKeyIndex(loaded, "t_user_i_org")).To(gomega.BeFalse())
+}
+
+func TestMysqlConnector_QuotedNames(t *testing.T) {
+	c := &MysqlConnector{}
+
+	tOrg := builder.T("t_org", builder.Col("f_id").Type(uint64(0), ""))
+
+	table := builder.T("t_user",
+		builder.Col("f_age").Type(0, ""),
+		builder.Col("f_org_id").Type(uint64(0), ""),
+		builder.Index("i_age", builder.Cols("f_age")),
+		builder.Check("c-age", builder.Col("f_age").Expr("# >= ?", 0)),
+		builder.FK("fk-org", builder.Cols("f_org_id")).References(tOrg, builder.Cols("f_id")),
+	)
+
+	gomega.NewWithT(t).Expect(c.RenameIndex(table.Key("i_age"), builder.Index("key", builder.Cols("f_age")).On(table))).
+		To(buidertestingutils.BeExpr("ALTER TABLE t_user RENAME INDEX i_age TO `key`;"))
+	gomega.NewWithT(t).Expect(c.AddConstraint(table.Constraint("c-age"))).
+		To(buidertestingutils.BeExpr("ALTER TABLE t_user ADD CONSTRAINT `t_user_c-age` CHECK (f_age >= 0);"))
+	gomega.NewWithT(t).Expect(c.DropConstraint(table.Constraint("c-age"))).
+		To(buidertestingutils.BeExpr("ALTER TABLE t_user DROP CHECK `t_user_c-age`;"))
+	gomega.NewWithT(t).Expect(c.AddForeignKey(table.ForeignKey("fk-org"))).
+		To(buidertestingutils.BeExpr("ALTER TABLE t_user ADD CONSTRAINT `t_user_fk-org` FOREIGN KEY (f_org_id) REFERENCES t_org (f_id);"))
+	gomega.NewWithT(t).Expect(c.DropForeignKey(table.ForeignKey("fk-org"))).
+		To(buidertestingutils.BeExpr("ALTER TABLE t_user DROP FOREIGN KEY `t_user_fk-org`;"))
+}
+
+func TestAddConstraints(t *testing.T) {
+	c := &MysqlConnector{}
+
+	cols := []builder.TableDefinition{
+		builder.Col("f_age").Type(0, ""),
+		builder.Col("f_status").Type("", ",size=8"),
+	}
+
+	table := builder.T("t_user", append(cols,
+		builder.Check("c_age", builder.Col("f_age").Expr("# >= ?", 0)),
+		builder.Check("c_status", builder.Expr("f_status IN ('a', 'b')")),
+	)...)
+
+	d := sqlx.NewDatabase("db")
+	d.AddTable(builder.T("t_user", cols...))
+
+	// second migration loads constraints created by the first one
+	addConstraints(d, []ConstraintSchema{
+		{TABLE_NAME: "t_user", CONSTRAINT_NAME: "t_user_c_age", CHECK_CLAUSE: "(`f_age` >= 0)"},
+		{TABLE_NAME: "t_user", CONSTRAINT_NAME: "t_user_c_status", CHECK_CLAUSE: "(`f_status` in (_utf8mb4'a',_utf8mb4'b'))"},
+	})
+
+	gomega.NewWithT(t).Expect(table.DiffActions(d.Table("t_user"), c)).To(gomega.BeEmpty())
+}
@@ -2,6 +2,7 @@ package mysqlconnector
 
 import (
 	"database/sql"
+	"fmt"
 	"strings"
 
 	"github.com/go-courier/sqlx/v2"
@@ -18,7 +19,8 @@ func toInterfaces(list ...string) []interface{} {
 
 func dbFromInformationSchema(db sqlx.DBExecutor) (*sqlx.Database, error) {
 	d := db.D()
-	tableNames := d.Tables.TableNames()
+	// history tables are migrated along with tables
+	tableNames := d.Tables.WithHistoryTables().TableNamesWithRenameFrom()
 
 	database := sqlx.NewDatabase(d.Name)
 
@@ -51,6 +53,70 @@ func dbFromInformationSchema(db sqlx.DBExecutor) (*sqlx.Database, error) {
 		table.AddCol(colFromColumnSchema(&columnSchema))
 	}
 
+	if database.Tables.Len() != 0 {
+		tableTableSchema := SchemaDatabase.T(&TableSchema{})
+		tableSchemaList := make([]TableSchema, 0)
+
+		err = db.QueryExprAndScan(
+			builder.Select(tableTableSchema.Columns.Clone()).
+				From(tableTableSchema,
+					builder.Where(
+						builder.And(
+							tableTableSchema.F("TABLE_SCHEMA").Eq(database.Name),
+							tableTableSchema.F("TABLE_NAME").In(toInterfaces(tableNames...)...),
+						),
+					),
+				),
+			&tableSchemaList,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tableSchema := range tableSchemaList {
+			if table := database.Table(tableSchema.TABLE_NAME); table != nil && tableSchema.TABLE_COMMENT != "" {
+				table.Description = strings.Split(tableSchema.TABLE_COMMENT, "\n")
+			}
+		}
+	}
+
+	if database.Tables.Len() != 0 {
+		foreignKeyList := make([]ForeignKeySchema, 0)
+
+		err = db.QueryExprAndScan(
+			builder.Expr(`SELECT k.TABLE_NAME AS TABLE_NAME, k.CONSTRAINT_NAME AS CONSTRAINT_NAME, k.COLUMN_NAME AS COLUMN_NAME,
+k.REFERENCED_TABLE_NAME AS REFERENCED_TABLE_NAME, k.REFERENCED_COLUMN_NAME AS REFERENCED_COLUMN_NAME,
+r.UPDATE_RULE AS UPDATE_RULE, r.DELETE_RULE AS DELETE_RULE
+FROM INFORMATION_SCHEMA.KEY_COLUMN_USAGE k
+JOIN INFORMATION_SCHEMA.REFERENTIAL_CONSTRAINTS r ON r.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA AND r.TABLE_NAME = k.TABLE_NAME AND r.CONSTRAINT_NAME = k.CONSTRAINT_NAME
+WHERE k.TABLE_SCHEMA = ? AND k.REFERENCED_TABLE_NAME IS NOT NULL
+ORDER BY k.TABLE_NAME, k.CONSTRAINT_NAME, k.ORDINAL_POSITION`, database.Name),
+			&foreignKeyList,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := addForeignKeys(database, foreignKeyList); err != nil {
+			return nil, err
+		}
+
+		constraintList := make([]ConstraintSchema, 0)
+
+		err = db.QueryExprAndScan(
+			builder.Expr(`SELECT tc.TABLE_NAME AS TABLE_NAME, cc.CONSTRAINT_NAME AS CONSTRAINT_NAME, cc.CHECK_CLAUSE AS CHECK_CLAUSE
+FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS tc
+JOIN INFORMATION_SCHEMA.CHECK_CONSTRAINTS cc ON cc.CONSTRAINT_SCHEMA = tc.CONSTRAINT_SCHEMA AND cc.CONSTRAINT_NAME = tc.CONSTRAINT_NAME
+WHERE tc.TABLE_SCHEMA = ? AND tc.CONSTRAINT_TYPE = 'CHECK'`, database.Name),
+			&constraintList,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		addConstraints(database, constraintList)
+	}
+
 	if tableColumnSchema.Columns.Len() != 0 {
 		tableIndexSchema := SchemaDatabase.T(&IndexSchema{})
 
@@ -81,15 +147,30 @@ func dbFromInformationSchema(db sqlx.DBExecutor) (*sqlx.Database, error) {
 		for _, indexSchema := range indexList {
 			table := database.Table(indexSchema.TABLE_NAME)
 
-			if key := table.Keys.Key(indexSchema.INDEX_NAME); key != nil {
+			// index created implicitly for foreign key is kept along with the foreign key
+			if isForeignKeyIndex(table, indexSchema.INDEX_NAME) {
+				continue
+			}
+
+			key := table.Keys.Key(indexSchema.INDEX_NAME)
+			if key != nil {
 				key.Columns.Add(table.Col(indexSchema.COLUMN_NAME))
 			} else {
-				key := &builder.Key{}
+				key = &builder.Key{}
 				key.Name = indexSchema.INDEX_NAME
 				key.Method = indexSchema.INDEX_TYPE
 				key.IsUnique = indexSchema.NON_UNIQUE == 0
 				key.Columns, _ = table.Cols(indexSchema.COLUMN_NAME)
 				table.AddKey(key)
+				key = table.Keys.Key(indexSchema.INDEX_NAME)
+			}
+
+			// D of descending index column, A of ascending
+			if indexSchema.COLLATION == "D" {
+				if key.Orders == nil {
+					key.Orders = map[string]builder.KeyColumnOrder{}
+				}
+				key.Orders[indexSchema.COLUMN_NAME] = builder.KeyColumnOrder{Desc: true}
 			}
 		}
 	}
@@ -97,9 +178,59 @@ func dbFromInformationSchema(db sqlx.DBExecutor) (*sqlx.Database, error) {
 	return database, nil
 }
 
+// addForeignKeys adds foreign keys to loaded tables, rows of each foreign key should be ordered by positions of columns
+func addForeignKeys(database *sqlx.Database, foreignKeyList []ForeignKeySchema) error {
+	for _, foreignKeySchema := range foreignKeyList {
+		table := database.Table(foreignKeySchema.TABLE_NAME)
+		if table == nil {
+			continue
+		}
+
+		col := table.Col(foreignKeySchema.COLUMN_NAME)
+		if col == nil {
+			return fmt.Errorf("invalid columns of foreign key %s: unknown column %s", foreignKeySchema.CONSTRAINT_NAME, foreignKeySchema.COLUMN_NAME)
+		}
+
+		name := strings.TrimPrefix(foreignKeySchema.CONSTRAINT_NAME, table.Name+"_")
+
+		if fk := table.ForeignKey(name); fk != nil {
+			fk.Columns.Add(col)
+			fk.RefColumns.Add(builder.Col(foreignKeySchema.REFERENCED_COLUMN_NAME))
+			continue
+		}
+
+		cols := &builder.Columns{}
+		cols.Add(col)
+
+		table.AddForeignKey(
+			builder.FK(name, cols).
+				References(builder.T(foreignKeySchema.REFERENCED_TABLE_NAME), builder.Cols(foreignKeySchema.REFERENCED_COLUMN_NAME)).
+				OnDeleteAction(foreignKeySchema.DELETE_RULE).
+				OnUpdateAction(foreignKeySchema.UPDATE_RULE),
+		)
+	}
+	return nil
+}
+
+// addConstraints adds check constraints to loaded tables, expressions are compared after normalized by builder
+func addConstraints(database *sqlx.Database, constraintList []ConstraintSchema) {
+	for _, constraintSchema := range constraintList {
+		if table := database.Table(constraintSchema.TABLE_NAME); table != nil {
+			name := strings.TrimPrefix(constraintSchema.CONSTRAINT_NAME, table.Name+"_")
+			table.AddConstraint(builder.Check(name, builder.Expr(constraintSchema.CHECK_CLAUSE)))
+		}
+	}
+}
+
+func isForeignKeyIndex(table *builder.Table, indexName string) bool {
+	fk := table.ForeignKey(strings.TrimPrefix(indexName, table.Name+"_"))
+	return fk != nil && strings.EqualFold(table.Name+"_"+fk.Name, indexName)
+}
+
 var SchemaDatabase = sqlx.NewDatabase("INFORMATION_SCHEMA")
 
 func init() {
+	SchemaDatabase.Register(&TableSchema{})
 	SchemaDatabase.Register(&ColumnSchema{})
 	SchemaDatabase.Register(&IndexSchema{})
 }
@@ -143,6 +274,21 @@ func colFromColumnSchema(columnSchema *ColumnSchema) *builder.Column {
 		col.Null = true
 	}
 
+	if strings.Contains(columnSchema.EXTRA, "GENERATED") {
+		col.GeneratedExpr = strings.Replace(columnSchema.GENERATION_EXPRESSION, "`", "", -1)
+		col.GeneratedStorage = builder.GeneratedStorageVirtual
+		if strings.Contains(columnSchema.EXTRA, builder.GeneratedStorageStored) {
+			col.GeneratedStorage = builder.GeneratedStorageStored
+		}
+	}
+
+	col.Charset = columnSchema.CHARACTER_SET_NAME.String
+	col.Collation = columnSchema.COLLATION_NAME.String
+
+	if columnSchema.COLUMN_COMMENT != "" {
+		col.Description = strings.Split(columnSchema.COLUMN_COMMENT, "\n")
+	}
+
 	return col
 }
 
@@ -165,6 +311,16 @@ func normalizeDefaultValue(v string) string {
 	return quoteWith(v, '\'', false, false)
 }
 
+type TableSchema struct {
+	TABLE_SCHEMA  string `db:"TABLE_SCHEMA"`
+	TABLE_NAME    string `db:"TABLE_NAME"`
+	TABLE_COMMENT string `db:"TABLE_COMMENT"`
+}
+
+func (TableSchema) TableName() string {
+	return "INFORMATION_SCHEMA.TABLES"
+}
+
 type ColumnSchema struct {
 	TABLE_SCHEMA             string         `db:"TABLE_SCHEMA"`
 	TABLE_NAME               string         `db:"TABLE_NAME"`
@@ -177,6 +333,10 @@ type ColumnSchema struct {
 	CHARACTER_MAXIMUM_LENGTH uint64         `db:"CHARACTER_MAXIMUM_LENGTH"`
 	NUMERIC_PRECISION        uint64         `db:"NUMERIC_PRECISION"`
 	NUMERIC_SCALE            uint64         `db:"NUMERIC_SCALE"`
+	COLUMN_COMMENT           string         `db:"COLUMN_COMMENT"`
+	CHARACTER_SET_NAME       sql.NullString `db:"CHARACTER_SET_NAME"`
+	COLLATION_NAME           sql.NullString `db:"COLLATION_NAME"`
+	GENERATION_EXPRESSION    string         `db:"GENERATION_EXPRESSION"`
 }
 
 func (ColumnSchema) TableName() string {
@@ -191,8 +351,25 @@ type IndexSchema struct {
 	SEQ_IN_INDEX int32  `db:"SEQ_IN_INDEX"`
 	COLUMN_NAME  string `db:"COLUMN_NAME"`
 	INDEX_TYPE   string `db:"INDEX_TYPE"`
+	COLLATION    string `db:"COLLATION"`
 }
 
 func (IndexSchema) TableName() string {
 	return "INFORMATION_SCHEMA.STATISTICS"
 }
+
+type ForeignKeySchema struct {
+	TABLE_NAME             string `db:"TABLE_NAME"`
+	CONSTRAINT_NAME        string `db:"CONSTRAINT_NAME"`
+	COLUMN_NAME            string `db:"COLUMN_NAME"`
+	REFERENCED_TABLE_NAME  string `db:"REFERENCED_TABLE_NAME"`
+	REFERENCED_COLUMN_NAME string `db:"REFERENCED_COLUMN_NAME"`
+	UPDATE_RULE            string `db:"UPDATE_RULE"`
+	DELETE_RULE            string `db:"DELETE_RULE"`
+}
+
+type ConstraintSchema struct {
+	TABLE_NAME      string `db:"TABLE_NAME"`
+	CONSTRAINT_NAME string `db:"CONSTRAINT_NAME"`
+	CHECK_CLAUSE    string `db:"CHECK_CLAUSE"`
+}
@@ -0,0 +1,23 @@
+package sqlx
+
+import (
+	"time"
+)
+
+const (
+	ObserveOpQuery = "query"
+	ObserveOpExec  = "exec"
+)
+
+// Observer observes queries for metrics like latency and error counts, without coupling to logging.
+// op is ObserveOpQuery or ObserveOpExec.
+type Observer interface {
+	ObserveQuery(op string, dur time.Duration, err error)
+}
+
+// ObserverFunc makes func as Observer
+type ObserverFunc func(op string, dur time.Duration, err error)
+
+func (fn ObserverFunc) ObserveQuery(op string, dur time.Duration, err error) {
+	fn(op, dur, err)
+}
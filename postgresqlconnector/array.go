@@ -0,0 +1,59 @@
+package postgresqlconnector
+
+import (
+	"database/sql/driver"
+	"reflect"
+
+	"github.com/go-courier/sqlx/v2/builder"
+	"github.com/lib/pq"
+)
+
+var _ driver.NamedValueChecker = (*loggerConn)(nil)
+
+// CheckNamedValue keeps slice args as they are, which are rendered as ARRAY[...] in logs,
+// and bound as arrays by bindArrays before executed, other args are converted as default.
+func (c *loggerConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if a, ok := nv.Value.(*builder.ArrayArg); ok {
+		nv.Value = a.Values
+	}
+	if isArray(nv.Value) {
+		return nil
+	}
+	return driver.ErrSkip
+}
+
+// isArray returns true for slices except bytes, slices implemented driver.Valuer are converted by themselves
+func isArray(v interface{}) bool {
+	if v == nil {
+		return false
+	}
+	if _, ok := v.(driver.Valuer); ok {
+		return false
+	}
+	typ := reflect.TypeOf(v)
+	return typ.Kind() == reflect.Slice && typ.Elem().Kind() != reflect.Uint8
+}
+
+// bindArrays returns args with arrays encoded by pq.Array, args without arrays are returned as they are
+func bindArrays(args []driver.NamedValue) ([]driver.NamedValue, error) {
+	var bound []driver.NamedValue
+
+	for i, arg := range args {
+		if !isArray(arg.Value) {
+			continue
+		}
+		if bound == nil {
+			bound = append(make([]driver.NamedValue, 0, len(args)), args...)
+		}
+		v, err := pq.Array(arg.Value).Value()
+		if err != nil {
+			return nil, err
+		}
+		bound[i].Value = v
+	}
+
+	if bound == nil {
+		return args, nil
+	}
+	return bound, nil
+}
@@ -0,0 +1,69 @@
+package postgresqlconnector
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-courier/sqlx/v2"
+	"github.com/go-courier/sqlx/v2/builder"
+
+	"github.com/go-courier/logr"
+	"github.com/pkg/errors"
+)
+
+// ExecBatch executes statements without args in one round trip,
+// by joining them with semicolon and sending them by the simple query protocol of postgres.
+// Pass the tx executor to run inside the transaction of caller,
+// otherwise postgres runs the statements in an implicit transaction,
+// so the batch fails atomically either way.
+func (c *PostgreSQLConnector) ExecBatch(db sqlx.DBExecutor, exprList ...builder.SqlExpr) error {
+	query, n, err := batchQuery(builder.ContextWithQuote(db.Context(), c.Quote), exprList)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return nil
+	}
+
+	ctx, logger := logr.Start(db.Context(), "ExecBatch")
+	defer logger.End()
+
+	logger = logger.WithValues("db.statements", n)
+	cost := startTimer()
+
+	if _, err := db.WithContext(ctx).ExecExpr(builder.Expr(query)); err != nil {
+		logger.WithValues("cost", cost().String()).Error(errors.Wrap(err, "batch failed"))
+		return err
+	}
+
+	logger.WithValues("cost", cost().String()).Debug("batch of %d statements succeed", n)
+	return nil
+}
+
+// batchQuery joins statements of exprList rendered by ctx, nil exprs are skipped
+func batchQuery(ctx context.Context, exprList []builder.SqlExpr) (string, int, error) {
+	b := strings.Builder{}
+	n := 0
+
+	for i := range exprList {
+		if builder.IsNilExpr(exprList[i]) {
+			continue
+		}
+		e := builder.ResolveExprContext(ctx, exprList[i])
+		if e.Err() != nil {
+			return "", 0, e.Err()
+		}
+		if len(e.Args()) > 0 {
+			return "", 0, errors.Errorf("statement of batch should be without args: %s", e.Query())
+		}
+		q := strings.TrimRight(strings.TrimSpace(e.Query()), ";")
+		if q == "" {
+			continue
+		}
+		b.WriteString(q)
+		b.WriteString(";\n")
+		n++
+	}
+
+	return b.String(), n, nil
+}
@@ -0,0 +1,39 @@
+package postgresqlconnector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-courier/sqlx/v2/builder"
+	"github.com/onsi/gomega"
+)
+
+func TestBatchQuery(t *testing.T) {
+	t.Run("joined", func(t *testing.T) {
+		query, n, err := batchQuery(context.Background(), []builder.SqlExpr{
+			builder.Expr("ALTER TABLE t_user ADD COLUMN f_name varchar(255);"),
+			nil,
+			builder.Expr("CREATE INDEX t_user_i_name ON t_user (f_name)"),
+		})
+
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(n).To(gomega.Equal(2))
+		gomega.NewWithT(t).Expect(query).To(gomega.Equal("ALTER TABLE t_user ADD COLUMN f_name varchar(255);\nCREATE INDEX t_user_i_name ON t_user (f_name);\n"))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		query, n, err := batchQuery(context.Background(), nil)
+
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(n).To(gomega.Equal(0))
+		gomega.NewWithT(t).Expect(query).To(gomega.Equal(""))
+	})
+
+	t.Run("with args", func(t *testing.T) {
+		_, _, err := batchQuery(context.Background(), []builder.SqlExpr{
+			builder.Expr("DELETE FROM t_user WHERE f_id = ?", 1),
+		})
+
+		gomega.NewWithT(t).Expect(err).NotTo(gomega.BeNil())
+	})
+}
@@ -0,0 +1,79 @@
+package postgresqlconnector
+
+import (
+	"database/sql"
+	"io"
+
+	"github.com/go-courier/sqlx/v2"
+	"github.com/go-courier/sqlx/v2/builder"
+
+	"github.com/go-courier/logr"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// CopyIn loads rows into columns of table by `COPY table (cols) FROM STDIN`, which is much faster than INSERT for bulk loading.
+// db should be the tx executor, for COPY of pq works only in transaction, which is committed or rolled back by caller.
+// next returns values of next row in order of cols, and io.EOF when no more rows.
+// Values are converted like args of queries, so driver.Valuer of datatypes works as well.
+func (c *PostgreSQLConnector) CopyIn(db sqlx.DBExecutor, table *builder.Table, cols *builder.Columns, next func() ([]interface{}, error)) (n int64, err error) {
+	var tx *sql.Tx
+	if d, ok := db.(*sqlx.DB); ok {
+		tx, _ = d.SqlExecutor.(*sql.Tx)
+	}
+	if tx == nil {
+		return 0, ErrNotInTx
+	}
+
+	ctx, logger := logr.Start(db.Context(), "CopyIn")
+	defer logger.End()
+
+	logger = logger.WithValues("db.table", table.Name)
+	cost := startTimer()
+
+	defer func() {
+		logger := logger.WithValues("db.rows", n, "cost", cost().String())
+		if err != nil {
+			logger.Error(errors.Wrapf(err, "copy in %s failed", table.Name))
+			return
+		}
+		logger.Debug("copy in %s finished with %d rows", table.Name, n)
+	}()
+
+	query := pq.CopyIn(table.Name, cols.ColNames()...)
+	if table.Schema != "" {
+		query = pq.CopyInSchema(table.Schema, table.Name, cols.ColNames()...)
+	}
+
+	logger.Debug("copy in %s started", table.Name)
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	for {
+		values, e := next()
+		if e == io.EOF {
+			break
+		}
+		if e != nil {
+			return n, e
+		}
+		if len(values) != cols.Len() {
+			return n, errors.Errorf("row %d has %d values, but %d columns", n, len(values), cols.Len())
+		}
+		if _, err := stmt.ExecContext(ctx, values...); err != nil {
+			return n, err
+		}
+		n++
+	}
+
+	// exec without args flushes buffered rows
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return n, err
+	}
+
+	return n, nil
+}
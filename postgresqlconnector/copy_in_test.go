@@ -0,0 +1,106 @@
+package postgresqlconnector
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"testing"
+
+	"github.com/go-courier/sqlx/v2"
+	"github.com/go-courier/sqlx/v2/builder"
+	"github.com/onsi/gomega"
+)
+
+func TestPostgreSQLConnector_CopyIn(t *testing.T) {
+	queries := make([]string, 0)
+	rows := make([][]driver.Value, 0)
+
+	db := sqlx.NewDatabase("db").OpenDB(&copyInConnector{
+		PostgreSQLConnector: &PostgreSQLConnector{},
+		conn:                &copyInConn{fakeConn: fakeConn{queries: &queries}, rows: &rows},
+	})
+
+	table := builder.T("t_user",
+		builder.Col("f_id").Type(int64(0), ""),
+		builder.Col("f_name").Type("", ""),
+	).WithSchema("s")
+
+	c := &PostgreSQLConnector{}
+
+	t.Run("not in tx", func(t *testing.T) {
+		_, err := c.CopyIn(db, table, &table.Columns, nil)
+		gomega.NewWithT(t).Expect(err).To(gomega.Equal(ErrNotInTx))
+	})
+
+	t.Run("in tx", func(t *testing.T) {
+		tx, err := db.Begin()
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+
+		values := [][]interface{}{{int64(1), "a"}, {int64(2), "b"}}
+
+		n, err := c.CopyIn(tx, table, &table.Columns, func() ([]interface{}, error) {
+			if len(values) == 0 {
+				return nil, io.EOF
+			}
+			row := values[0]
+			values = values[1:]
+			return row, nil
+		})
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(n).To(gomega.Equal(int64(2)))
+		gomega.NewWithT(t).Expect(tx.(sqlx.MaybeTxExecutor).Commit()).To(gomega.BeNil())
+
+		gomega.NewWithT(t).Expect(queries).To(gomega.Equal([]string{
+			"BEGIN",
+			`COPY "s"."t_user" ("f_id", "f_name") FROM STDIN`,
+			"COMMIT",
+		}))
+		gomega.NewWithT(t).Expect(rows).To(gomega.Equal([][]driver.Value{{int64(1), "a"}, {int64(2), "b"}}))
+	})
+}
+
+type copyInConnector struct {
+	*PostgreSQLConnector
+	conn driver.Conn
+}
+
+func (c *copyInConnector) WithDBName(dbName string) driver.Connector {
+	return c
+}
+
+func (c *copyInConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &loggerConn{Conn: c.conn}, nil
+}
+
+type copyInConn struct {
+	fakeConn
+	rows *[][]driver.Value
+}
+
+func (c *copyInConn) Prepare(query string) (driver.Stmt, error) {
+	*c.queries = append(*c.queries, query)
+	return &copyInStmt{rows: c.rows}, nil
+}
+
+type copyInStmt struct {
+	rows *[][]driver.Value
+}
+
+func (s *copyInStmt) Close() error {
+	return nil
+}
+
+func (s *copyInStmt) NumInput() int {
+	return -1
+}
+
+func (s *copyInStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if len(args) > 0 {
+		*s.rows = append(*s.rows, args)
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (s *copyInStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, driver.ErrSkip
+}
@@ -5,11 +5,15 @@ import (
 	"context"
 	"database/sql/driver"
 	"fmt"
+	"io"
+	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-courier/sqlx/v2"
+	"github.com/go-courier/sqlx/v2/builder"
 
 	"github.com/go-courier/logr"
 	"github.com/lib/pq"
@@ -18,29 +22,221 @@ import (
 
 var _ interface {
 	driver.Driver
+	driver.DriverContext
 } = (*PostgreSQLLoggingDriver)(nil)
 
 type PostgreSQLLoggingDriver struct {
-	driver pq.Driver
+	// driver opens the underlying conns, pq.Driver when nil
+	driver driver.Driver
+	// MaxLoggedQueryLength limits the length of interpolated query in logs, 0 means no limit.
+	// only the log output is truncated, the executed query keeps as it is.
+	MaxLoggedQueryLength int
+	// ErrorLogLevels overrides DefaultErrorLogLevels
+	ErrorLogLevels ErrorLogLevels
+	// Observer observes queries for metrics, optional
+	Observer sqlx.Observer
+	// VerboseConnectLog logs full config of connection with password masked,
+	// otherwise only host, port, dbname, user and sslmode are logged.
+	VerboseConnectLog bool
+	// Interpolator renders queries in logs, inlines all args when nil
+	Interpolator Interpolator
+	// QueryRewriters rewrite queries in order before executed
+	QueryRewriters []QueryRewriter
+	// defaultOpts are options of the logging driver set by Options, overridden by the ones of dsn
+	defaultOpts map[string]string
+}
+
+// OpenConnector returns connector of dsn, by which sql.Open(name, dsn) connects with ctx of database/sql
+func (d *PostgreSQLLoggingDriver) OpenConnector(dsn string) (driver.Connector, error) {
+	if _, _, err := parseDSN(dsn, driverOptKeys...); err != nil {
+		return nil, err
+	}
+	return &loggingConnector{dsn: dsn, driver: d}, nil
+}
+
+type loggingConnector struct {
+	dsn    string
+	driver *PostgreSQLLoggingDriver
+}
+
+func (c *loggingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.driver.open(ctx, c.dsn)
+}
+
+func (c *loggingConnector) Driver() driver.Driver {
+	return c.driver
 }
 
 func (d *PostgreSQLLoggingDriver) Open(dsn string) (driver.Conn, error) {
-	config, err := pq.ParseURL(dsn)
+	return d.open(context.Background(), dsn)
+}
+
+// open opens conn, and sets up session by statement_timeout, search_path and ping_on_connect,
+// so every conn of the driver is set up, whether opened by PostgreSQLConnector or sql.Open.
+func (d *PostgreSQLLoggingDriver) open(ctx context.Context, dsn string) (driver.Conn, error) {
+	config, driverOpts, err := parseDSN(dsn, driverOptKeys...)
 	if err != nil {
 		return nil, err
 	}
 
+	for k, v := range d.defaultOpts {
+		if _, ok := driverOpts[k]; !ok {
+			driverOpts[k] = v
+		}
+	}
+
+	traceStatement := true
+	if v, ok := driverOpts[optTraceStatement]; ok {
+		traceStatement, err = strconv.ParseBool(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", optTraceStatement)
+		}
+	}
+
+	slowQueryThreshold := time.Duration(0)
+	if v, ok := driverOpts[optSlowQueryThreshold]; ok {
+		slowQueryThreshold, err = time.ParseDuration(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", optSlowQueryThreshold)
+		}
+	}
+
+	longTxThreshold := time.Duration(0)
+	if v, ok := driverOpts[optLongTxThreshold]; ok {
+		longTxThreshold, err = time.ParseDuration(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", optLongTxThreshold)
+		}
+	}
+
+	statementTimeout := time.Duration(0)
+	if v, ok := driverOpts[optStatementTimeout]; ok {
+		statementTimeout, err = time.ParseDuration(v)
+		if err == nil && statementTimeout < 0 {
+			err = errors.Errorf("negative duration %s", v)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", optStatementTimeout)
+		}
+	}
+
+	maxRetries := 0
+	if v, ok := driverOpts[optMaxRetries]; ok {
+		maxRetries, err = strconv.Atoi(v)
+		if err == nil && maxRetries < 0 {
+			err = errors.Errorf("negative retries %s", v)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", optMaxRetries)
+		}
+	}
+
+	searchPath := make([]string, 0)
+	if v, ok := driverOpts[optSearchPath]; ok {
+		for _, schema := range strings.Split(v, ",") {
+			if schema = strings.TrimSpace(schema); schema != "" {
+				searchPath = append(searchPath, schema)
+			}
+		}
+		if len(searchPath) == 0 {
+			return nil, errors.Errorf("invalid %s: empty", optSearchPath)
+		}
+	}
+
+	pingOnConnect := false
+	if v, ok := driverOpts[optPingOnConnect]; ok {
+		pingOnConnect, err = strconv.ParseBool(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", optPingOnConnect)
+		}
+	}
+
+	stmtCacheSize := 0
+	if v, ok := driverOpts[optStmtCacheSize]; ok {
+		stmtCacheSize, err = strconv.Atoi(v)
+		if err == nil && stmtCacheSize < 0 {
+			err = errors.Errorf("negative size %s", v)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", optStmtCacheSize)
+		}
+	}
+
+	explainSlow := false
+	if v, ok := driverOpts[optExplainSlow]; ok {
+		explainSlow, err = strconv.ParseBool(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", optExplainSlow)
+		}
+	}
+
+	config = withApplicationName(config, processName())
+
 	opts := FromConfigString(config)
-	if pass, ok := opts["password"]; ok {
-		opts["password"] = strings.Repeat("*", len(pass))
+	if d.VerboseConnectLog {
+		if pass, ok := opts["password"]; ok {
+			opts["password"] = strings.Repeat("*", len(pass))
+		}
+	} else {
+		opts = opts.Brief()
+	}
+
+	var underlying driver.Driver = pq.Driver{}
+	if d.driver != nil {
+		underlying = d.driver
 	}
 
-	conn, err := d.driver.Open(config)
+	conn, err := underlying.Open(config)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to open connection: %s", opts)
 	}
 
-	return &loggerConn{Conn: conn, cfg: opts}, nil
+	c := &loggerConn{
+		Conn:                 conn,
+		cfg:                  opts,
+		logger:               logr.FromContext(ctx),
+		maxLoggedQueryLength: d.MaxLoggedQueryLength,
+		slowQueryThreshold:   slowQueryThreshold,
+		explainSlow:          explainSlow,
+		longTxThreshold:      longTxThreshold,
+		traceStatement:       traceStatement,
+		statementTimeout:     statementTimeout,
+		searchPath:           searchPath,
+		maxRetries:           maxRetries,
+		pingOnConnect:        pingOnConnect,
+		errorLogLevels:       d.ErrorLogLevels,
+		observer:             d.Observer,
+		interpolator:         d.Interpolator,
+		queryRewriters:       d.QueryRewriters,
+		connectedAt:          time.Now(),
+	}
+
+	if stmtCacheSize > 0 {
+		c.stmtCache = newStmtCache(stmtCacheSize)
+	}
+
+	if err := c.setUpSession(ctx); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (c *loggerConn) setUpSession(ctx context.Context) error {
+	if err := c.setStatementTimeout(ctx); err != nil {
+		logr.FromContext(ctx).Error(errors.Wrap(err, "failed to set statement_timeout"))
+		return err
+	}
+	if err := c.setSearchPath(ctx); err != nil {
+		logr.FromContext(ctx).Error(errors.Wrap(err, "failed to set search_path"))
+		return err
+	}
+	if err := c.ping(ctx); err != nil {
+		logr.FromContext(ctx).Error(errors.Wrap(err, "failed to ping on connect"))
+		return err
+	}
+	return nil
 }
 
 var _ interface {
@@ -51,102 +247,388 @@ var _ interface {
 
 type loggerConn struct {
 	cfg PostgreSQLOpts
+	// logger of the context the conn opened with, connections are closed by pool without context
+	logger               logr.Logger
+	maxLoggedQueryLength int
+	// slowQueryThreshold logs queries cost more than it as Warn, 0 means disabled
+	slowQueryThreshold time.Duration
+	// explainSlow logs plan of slow queries
+	explainSlow bool
+	// longTxThreshold logs transactions kept open longer than it as Warn, 0 means disabled
+	longTxThreshold time.Duration
+	// traceStatement sets interpolated query as span attribute db.statement
+	traceStatement bool
+	// statementTimeout is set as statement_timeout of session when connected, 0 means disabled
+	statementTimeout time.Duration
+	// searchPath is set as search_path of session when connected, empty means default
+	searchPath []string
+	// maxRetries retries auto-commit statements failed by transient errors, 0 means disabled
+	maxRetries int
+	// pingOnConnect pings when connected, for failing fast on unusable conn
+	pingOnConnect  bool
+	errorLogLevels ErrorLogLevels
+	observer       sqlx.Observer
+	// interpolator renders queries in logs, inlines all args when nil
+	interpolator Interpolator
+	// queryRewriters rewrite queries in order before executed
+	queryRewriters []QueryRewriter
+	// stmtCache caches prepared statements of queries with args, nil means disabled
+	stmtCache *stmtCache
+	// tx is the current transaction, for savepoints
+	tx *loggingTx
+	// connectedAt and queries are logged when closed, for tuning lifetime of conns in pool
+	connectedAt time.Time
+	queries     int
 	driver.Conn
 }
 
 func (c *loggerConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
 	logger := logr.FromContext(ctx)
 
-	logger.Debug("=========== Beginning Transaction ===========")
+	logger.Debug("=========== Beginning Transaction (%s) ===========", sqlx.DescribeTxOptions(opts))
 	tx, err := c.Conn.(driver.ConnBeginTx).BeginTx(ctx, opts)
 	if err != nil {
 		logger.Error(errors.Wrap(err, "failed to begin transaction"))
 		return nil, err
 	}
-	return &loggingTx{tx: tx, logger: logger}, nil
+	c.tx = &loggingTx{ctx: ctx, tx: tx, logger: logger, conn: c, cost: startTimer()}
+	return c.tx, nil
+}
+
+// setStatementTimeout sets statement_timeout of session, so every query of the conn is bounded server-side
+func (c *loggerConn) setStatementTimeout(ctx context.Context) error {
+	if c.statementTimeout <= 0 {
+		return nil
+	}
+	_, err := c.Conn.(driver.ExecerContext).ExecContext(ctx, "SET statement_timeout = "+strconv.FormatInt(c.statementTimeout.Milliseconds(), 10), nil)
+	return err
+}
+
+// ping checks the conn is usable by empty query when pingOnConnect
+func (c *loggerConn) ping(ctx context.Context) (err error) {
+	if !c.pingOnConnect {
+		return nil
+	}
+
+	cost := startTimer()
+
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		err = pinger.Ping(ctx)
+	} else {
+		_, err = c.Conn.(driver.ExecerContext).ExecContext(ctx, "SELECT 1", nil)
+	}
+
+	if err == nil {
+		logr.FromContext(ctx).WithValues("cost", cost().String()).Debug("ping on connect")
+	}
+	return err
+}
+
+// setSearchPath sets search_path of session, so unqualified tables resolve in the schemas
+func (c *loggerConn) setSearchPath(ctx context.Context) error {
+	if len(c.searchPath) == 0 {
+		return nil
+	}
+	schemas := make([]string, len(c.searchPath))
+	for i, schema := range c.searchPath {
+		schemas[i] = pq.QuoteIdentifier(schema)
+	}
+	_, err := c.Conn.(driver.ExecerContext).ExecContext(ctx, "SET search_path TO "+strings.Join(schemas, ", "), nil)
+	return err
 }
 
 func (c *loggerConn) Close() error {
+	logger := c.logger
+	if logger == nil {
+		logger = logr.Discard()
+	}
+	if c.stmtCache != nil {
+		if err := c.stmtCache.closeAll(); err != nil {
+			logger.Warn(errors.Wrap(err, "failed to close prepared statements"))
+		}
+	}
 	if err := c.Conn.Close(); err != nil {
 		return err
 	}
+	logger.
+		WithValues("db.conn.age", time.Since(c.connectedAt).String(), "db.conn.queries", c.queries).
+		Debug("connection closed")
 	return nil
 }
 
 func (c *loggerConn) Prepare(query string) (driver.Stmt, error) {
-	panic(fmt.Errorf("don't use Prepare"))
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *loggerConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	query = c.rewrite(ctx, query)
+
+	stmt, err := c.Conn.Prepare(replaceValueHolder(query))
+	if err != nil {
+		logr.FromContext(ctx).Error(errors.Wrapf(err, "prepare failed: %s", query))
+		return nil, err
+	}
+	return &loggingStmt{Stmt: stmt, conn: c, query: query, copyIn: strings.HasPrefix(query, "COPY ")}, nil
 }
 
 func (c *loggerConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (rows driver.Rows, err error) {
-	newCtx, logger := logr.Start(ctx, "Query")
+	c.queries++
+	query = c.rewrite(ctx, query)
+	args = sortedByOrdinal(args)
+
+	newCtx, logger := c.start(ctx, "Query", query, args)
 	cost := startTimer()
 
 	defer func() {
-		q := interpolateParams(query, args)
+		rows = c.queryDone(newCtx, logger, query, args, cost(), rows, err)
+	}()
 
-		if err != nil {
-			if pgErr, ok := sqlx.UnwrapAll(err).(*pq.Error); !ok {
-				logger.Error(errors.Wrapf(pgErr, "query failed: %s", q))
-			} else {
-				logger.Warn(errors.Wrapf(pgErr, "query failed: %s", q))
+	bound, err := bindArrays(args)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.retry(logger, newCtx.Done(), func() (err error) {
+		if c.useStmtCache(args) {
+			stmt, err := c.stmtFor(newCtx, logger, replaceValueHolder(query))
+			if err != nil {
+				return err
+			}
+			if stmt != nil {
+				rows, err = c.queryStmt(newCtx, logger, stmt, replaceValueHolder(query), bound)
+				return err
 			}
-		} else {
-			logger.WithValues("cost", cost().String()).Debug("%s", q)
 		}
-
-		logger.End()
-	}()
-
-	rows, err = c.Conn.(driver.QueryerContext).QueryContext(newCtx, replaceValueHolder(query), args)
+		rows, err = c.Conn.(driver.QueryerContext).QueryContext(newCtx, replaceValueHolder(query), bound)
+		return err
+	})
 	return
 }
 
 func (c *loggerConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (result driver.Result, err error) {
+	c.queries++
+	query = c.rewrite(ctx, query)
+	args = sortedByOrdinal(args)
+
 	cost := startTimer()
-	newCtx, logger := logr.Start(ctx, "Exec")
+	newCtx, logger := c.start(ctx, "Exec", query, args)
 
 	defer func() {
-		q := interpolateParams(query, args)
+		c.execDone(newCtx, logger, query, args, cost(), result, err)
+	}()
 
-		if err != nil {
-			if pgError, ok := sqlx.UnwrapAll(err).(*pq.Error); !ok {
-				logger.Error(errors.Wrapf(pgError, "exec failed: %s", q))
-			} else if pgError.Code == "23505" {
-				logger.Warn(errors.Wrapf(pgError, "exec failed: %s", q))
-			} else {
-				logger.Error(errors.Wrapf(pgError, "exec failed: %s", q))
+	bound, err := bindArrays(args)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.retry(logger, newCtx.Done(), func() (err error) {
+		if c.useStmtCache(args) {
+			stmt, err := c.stmtFor(newCtx, logger, replaceValueHolder(query))
+			if err != nil {
+				return err
+			}
+			if stmt != nil {
+				result, err = c.execStmt(newCtx, logger, stmt, replaceValueHolder(query), bound)
+				return err
 			}
-			return
 		}
+		result, err = c.Conn.(driver.ExecerContext).ExecContext(newCtx, replaceValueHolder(query), bound)
+		return err
+	})
+	return
+}
 
-		logger.WithValues("cost", cost().String()).Debug(q.String())
+// start starts span with attributes of database semantic conventions
+func (c *loggerConn) start(ctx context.Context, name string, query string, args []driver.NamedValue) (context.Context, logr.Logger) {
+	newCtx, logger := logr.Start(ctx, name)
 
-		logger.End()
-	}()
+	logger = logger.WithValues("db.system", "postgresql", "db.name", c.cfg["dbname"])
 
-	result, err = c.Conn.(driver.ExecerContext).ExecContext(newCtx, replaceValueHolder(query), args)
-	return
+	if queryName := QueryNameFromContext(ctx); queryName != "" {
+		logger = logger.WithValues("db.query.name", queryName)
+	}
+
+	if c.traceStatement {
+		logger = logger.WithValues("db.statement", sqlx.TruncateQuery(c.interpolate(query, args), c.maxLoggedQueryLength))
+	}
+
+	return newCtx, logger
 }
 
-func replaceValueHolder(query string) string {
-	index := 0
-	data := []byte(query)
+// queryDone logs failed query at once, or logs succeed query with rows count when rows closed
+func (c *loggerConn) queryDone(ctx context.Context, logger logr.Logger, query string, args []driver.NamedValue, cost time.Duration, rows driver.Rows, err error) driver.Rows {
+	c.observe(sqlx.ObserveOpQuery, cost, err)
+
+	if err != nil {
+		c.logQuery(ctx, logger, query, args, cost, err)
+		return rows
+	}
+
+	return &loggingRows{
+		Rows: rows,
+		done: func(count int) {
+			// cost of query only, time spent on reading rows by caller is excluded
+			c.logQuery(ctx, logger.WithValues("db.rows", count), query, args, cost, nil)
+		},
+	}
+}
 
-	e := bytes.NewBufferString("")
+func (c *loggerConn) execDone(ctx context.Context, logger logr.Logger, query string, args []driver.NamedValue, cost time.Duration, result driver.Result, err error) {
+	c.observe(sqlx.ObserveOpExec, cost, err)
 
-	for i := range data {
-		c := data[i]
-		switch c {
-		case '?':
-			e.WriteByte('$')
-			e.WriteString(strconv.FormatInt(int64(index+1), 10))
-			index++
-		default:
-			e.WriteByte(c)
+	if err == nil {
+		if rowsAffected, e := result.RowsAffected(); e == nil {
+			logger = logger.WithValues("db.rows_affected", rowsAffected)
 		}
 	}
+	c.logExec(ctx, logger, query, args, cost, err)
+}
+
+func (c *loggerConn) observe(op string, cost time.Duration, err error) {
+	if c.observer != nil {
+		c.observer.ObserveQuery(op, cost, err)
+	}
+}
+
+func (c *loggerConn) logQuery(ctx context.Context, logger logr.Logger, query string, args []driver.NamedValue, cost time.Duration, err error) {
+	q := sqlx.TruncateQuery(c.interpolate(query, args), c.maxLoggedQueryLength)
+
+	if err != nil {
+		c.logFailed(logger, errors.Wrapf(err, "query failed: %s", q))
+	} else {
+		c.logSucceed(logger, q, cost)
+		c.explainIfSlow(ctx, logger, query, args, cost)
+	}
+
+	logger.End()
+}
+
+func (c *loggerConn) logExec(ctx context.Context, logger logr.Logger, query string, args []driver.NamedValue, cost time.Duration, err error) {
+	q := sqlx.TruncateQuery(c.interpolate(query, args), c.maxLoggedQueryLength)
+
+	if err != nil {
+		c.logFailed(logger, errors.Wrapf(err, "exec failed: %s", q))
+		return
+	}
+
+	c.logSucceed(logger, q, cost)
+	c.explainIfSlow(ctx, logger, query, args, cost)
+
+	logger.End()
+}
+
+func (c *loggerConn) interpolate(query string, args []driver.NamedValue) fmt.Stringer {
+	if c.interpolator != nil {
+		return c.interpolator(query, args)
+	}
+	return interpolateParams(query, args)
+}
+
+func (c *loggerConn) isSlow(cost time.Duration) bool {
+	return c.slowQueryThreshold > 0 && cost > c.slowQueryThreshold
+}
+
+func (c *loggerConn) logSucceed(logger logr.Logger, q fmt.Stringer, cost time.Duration) {
+	if c.isSlow(cost) {
+		logger.WithValues("cost", cost.String()).Warn(errors.Errorf("slow query: %s", q))
+		return
+	}
+	logger.WithValues("cost", cost.String()).Debug("%s", q)
+}
+
+// explainTimeout limits time of EXPLAIN for slow query
+var explainTimeout = 5 * time.Second
+
+// explainIfSlow logs plan of slow query when explain_slow enabled.
+// The plan is queried by another statement after the query done, so its result is not interfered,
+// and EXPLAIN without ANALYZE never executes the statement, writes are not applied twice.
+// Queries in transaction are not explained, for failed EXPLAIN aborts the transaction of caller,
+// and EXPLAIN is canceled with ctx of the query, or when it costs more than explainTimeout.
+func (c *loggerConn) explainIfSlow(ctx context.Context, logger logr.Logger, query string, args []driver.NamedValue, cost time.Duration) {
+	if !c.explainSlow || !c.isSlow(cost) || !isExplainable(query) {
+		return
+	}
+
+	if c.tx != nil || ctx.Err() != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, explainTimeout)
+	defer cancel()
+
+	plan, err := c.explain(ctx, query, args)
+	if err != nil {
+		logger.Warn(errors.Wrap(err, "failed to explain slow query"))
+		return
+	}
+
+	logger.Info("plan of slow query: %s", plan)
+}
+
+// explain queries by the underlying conn directly, so EXPLAIN is not logged or explained again
+func (c *loggerConn) explain(ctx context.Context, query string, args []driver.NamedValue) (string, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return "", driver.ErrSkip
+	}
+
+	args, err := bindArrays(args)
+	if err != nil {
+		return "", err
+	}
+
+	rows, err := queryer.QueryContext(ctx, "EXPLAIN (FORMAT JSON) "+replaceValueHolder(query), args)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	plan := bytes.NewBuffer(nil)
+	dest := make([]driver.Value, len(rows.Columns()))
+
+	for {
+		if err := rows.Next(dest); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return "", err
+		}
+		switch v := dest[0].(type) {
+		case []byte:
+			plan.Write(v)
+		case string:
+			plan.WriteString(v)
+		}
+	}
+
+	return plan.String(), nil
+}
 
-	return e.String()
+var reLeadingComments = regexp.MustCompile(`^(\s+|/\*[^*]*\*+([^/*][^*]*\*+)*/|--[^\n]*\n?)*`)
+
+// isExplainable returns true when query is SELECT, INSERT, UPDATE, DELETE, VALUES or WITH,
+// leading comments added by QueryRewriter are skipped, EXPLAIN itself is never explained.
+func isExplainable(query string) bool {
+	query = reLeadingComments.ReplaceAllString(query, "")
+
+	i := strings.IndexFunc(query, func(r rune) bool {
+		return !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z')
+	})
+	if i > -1 {
+		query = query[0:i]
+	}
+
+	switch strings.ToUpper(query) {
+	case "SELECT", "INSERT", "UPDATE", "DELETE", "VALUES", "WITH":
+		return true
+	}
+	return false
+}
+
+// replaceValueHolder replaces value holders `?` of query by bind vars of postgres
+func replaceValueHolder(query string) string {
+	return builder.BindVars(query, PostgreSQLConnector{}.BindVar)
 }
 
 func startTimer() func() time.Duration {
@@ -156,25 +638,207 @@ func startTimer() func() time.Duration {
 	}
 }
 
+var _ interface {
+	driver.StmtExecContext
+	driver.StmtQueryContext
+} = (*loggingStmt)(nil)
+
+type loggingStmt struct {
+	driver.Stmt
+	conn  *loggerConn
+	query string
+	// copyIn stmt is executed for each row, which is logged by CopyIn once
+	copyIn bool
+}
+
+func (stmt *loggingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return stmt.ExecContext(context.Background(), valueToNamedValue(args))
+}
+
+func (stmt *loggingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return stmt.QueryContext(context.Background(), valueToNamedValue(args))
+}
+
+func (stmt *loggingStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (result driver.Result, err error) {
+	args = sortedByOrdinal(args)
+
+	if stmt.copyIn {
+		values, err := namedValueToValue(args)
+		if err != nil {
+			return nil, err
+		}
+		return stmt.Stmt.Exec(values)
+	}
+
+	stmt.conn.queries++
+	cost := startTimer()
+	newCtx, logger := stmt.conn.start(ctx, "Exec", stmt.query, args)
+
+	defer func() {
+		stmt.conn.execDone(newCtx, logger, stmt.query, args, cost(), result, err)
+	}()
+
+	values, err := namedValueToValue(args)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err = stmt.Stmt.Exec(values)
+	return
+}
+
+func (stmt *loggingStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (rows driver.Rows, err error) {
+	args = sortedByOrdinal(args)
+
+	stmt.conn.queries++
+	cost := startTimer()
+	newCtx, logger := stmt.conn.start(ctx, "Query", stmt.query, args)
+
+	defer func() {
+		rows = stmt.conn.queryDone(newCtx, logger, stmt.query, args, cost(), rows, err)
+	}()
+
+	values, err := namedValueToValue(args)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = stmt.Stmt.Query(values)
+	return
+}
+
+// namedValueToValue returns values of args for driver.Stmt, arrays are encoded like bindArrays
+func namedValueToValue(named []driver.NamedValue) ([]driver.Value, error) {
+	named, err := bindArrays(named)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]driver.Value, len(named))
+	for n, param := range named {
+		if len(param.Name) > 0 {
+			return nil, errors.New("sql: driver does not support the use of Named Parameters")
+		}
+		args[n] = param.Value
+	}
+	return args, nil
+}
+
+func valueToNamedValue(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for n, v := range args {
+		named[n] = driver.NamedValue{Ordinal: n + 1, Value: v}
+	}
+	return named
+}
+
+type loggingRows struct {
+	driver.Rows
+	count int
+	done  func(count int)
+}
+
+func (r *loggingRows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	if err == nil {
+		r.count++
+	}
+	return err
+}
+
+func (r *loggingRows) Close() error {
+	err := r.Rows.Close()
+	if r.done != nil {
+		r.done(r.count)
+		r.done = nil
+	}
+	return err
+}
+
+func (r *loggingRows) HasNextResultSet() bool {
+	if rows, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return rows.HasNextResultSet()
+	}
+	return false
+}
+
+func (r *loggingRows) NextResultSet() error {
+	if rows, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return rows.NextResultSet()
+	}
+	return io.EOF
+}
+
+func (r *loggingRows) ColumnTypeScanType(index int) reflect.Type {
+	if rows, ok := r.Rows.(driver.RowsColumnTypeScanType); ok {
+		return rows.ColumnTypeScanType(index)
+	}
+	return reflect.TypeOf(new(interface{})).Elem()
+}
+
+func (r *loggingRows) ColumnTypeDatabaseTypeName(index int) string {
+	if rows, ok := r.Rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return rows.ColumnTypeDatabaseTypeName(index)
+	}
+	return ""
+}
+
+func (r *loggingRows) ColumnTypeLength(index int) (length int64, ok bool) {
+	if rows, ok := r.Rows.(driver.RowsColumnTypeLength); ok {
+		return rows.ColumnTypeLength(index)
+	}
+	return 0, false
+}
+
+func (r *loggingRows) ColumnTypePrecisionScale(index int) (precision, scale int64, ok bool) {
+	if rows, ok := r.Rows.(driver.RowsColumnTypePrecisionScale); ok {
+		return rows.ColumnTypePrecisionScale(index)
+	}
+	return 0, 0, false
+}
+
 type loggingTx struct {
+	// ctx is the context of BeginTx, which Commit and Rollback could not take
+	ctx    context.Context
 	logger logr.Logger
 	tx     driver.Tx
+	conn   *loggerConn
+	// cost is duration since the transaction began
+	cost func() time.Duration
+	// savepoints are names of active savepoints from outer to inner
+	savepoints []string
 }
 
 func (tx *loggingTx) Commit() error {
+	tx.conn.tx = nil
+	logger := tx.logger.WithValues("cost", tx.cost().String())
+	// only observed, for the transaction outlived its request is likely doomed, commit is still tried
+	if tx.ctx != nil && tx.ctx.Err() != nil {
+		logger.Warn(errors.Wrap(tx.ctx.Err(), "committing transaction after its context done"))
+	}
 	if err := tx.tx.Commit(); err != nil {
-		tx.logger.Debug("failed to commit transaction: %s", err)
+		logger.Error(errors.Wrap(err, "failed to commit transaction"))
 		return err
 	}
-	tx.logger.Debug("=========== Committed Transaction ===========")
+	tx.done(logger, "Committed")
 	return nil
 }
 
 func (tx *loggingTx) Rollback() error {
+	tx.conn.tx = nil
+	logger := tx.logger.WithValues("cost", tx.cost().String())
 	if err := tx.tx.Rollback(); err != nil {
-		tx.logger.Debug("failed to rollback transaction: %s", err)
+		logger.Error(errors.Wrap(err, "failed to rollback transaction"))
 		return err
 	}
-	tx.logger.Debug("=========== Rollback Transaction ===========")
+	tx.done(logger, "Rollback")
 	return nil
 }
+
+// done logs the end of transaction, as Warn when kept open longer than longTxThreshold
+func (tx *loggingTx) done(logger logr.Logger, action string) {
+	if cost := tx.cost(); tx.conn.longTxThreshold > 0 && cost > tx.conn.longTxThreshold {
+		logger.Warn(errors.Errorf("long transaction %s after %s", strings.ToLower(action), cost))
+		return
+	}
+	logger.Debug("=========== %s Transaction ===========", action)
+}
@@ -0,0 +1,549 @@
+package postgresqlconnector
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/go-courier/logr"
+	"github.com/go-courier/sqlx/v2"
+	"github.com/go-courier/sqlx/v2/builder"
+	"github.com/onsi/gomega"
+)
+
+func TestLoggerConn_Observer(t *testing.T) {
+	ops := make([]string, 0)
+
+	c := &loggerConn{
+		Conn: &fakeConn{queries: &[]string{}},
+		observer: sqlx.ObserverFunc(func(op string, dur time.Duration, err error) {
+			ops = append(ops, op)
+		}),
+	}
+
+	_, err := c.ExecContext(context.Background(), "DELETE FROM t", nil)
+	gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+
+	c.queryDone(context.Background(), nil, "SELECT 1", nil, 0, &fakeRows{}, nil)
+
+	gomega.NewWithT(t).Expect(ops).To(gomega.Equal([]string{sqlx.ObserveOpExec, sqlx.ObserveOpQuery}))
+}
+
+type valuesLogger struct {
+	logr.Logger
+	values *[]interface{}
+}
+
+func (l *valuesLogger) WithValues(keyAndValues ...interface{}) logr.Logger {
+	*l.values = append(*l.values, keyAndValues...)
+	return l
+}
+
+func TestLoggerConn_QueryDoneCost(t *testing.T) {
+	values := make([]interface{}, 0)
+
+	c := &loggerConn{Conn: &fakeConn{queries: &[]string{}}}
+	rows := c.queryDone(context.Background(), &valuesLogger{Logger: logr.Discard(), values: &values}, "SELECT 1", nil, 5*time.Millisecond, &fakeRows{}, nil)
+
+	// time spent on reading rows is not counted in cost of query
+	time.Sleep(20 * time.Millisecond)
+
+	gomega.NewWithT(t).Expect(rows.Close()).To(gomega.BeNil())
+	gomega.NewWithT(t).Expect(values).To(gomega.ContainElement("5ms"))
+}
+
+func TestLoggerConn_PrepareContext(t *testing.T) {
+	args := []driver.Value{"a", int64(1)}
+
+	newConn := func(queries *[]string, ops *[]string) (*preparingConn, *loggerConn) {
+		conn := &preparingConn{fakeConn: fakeConn{queries: queries}, closed: &[]string{}}
+		return conn, &loggerConn{
+			Conn: conn,
+			observer: sqlx.ObserverFunc(func(op string, dur time.Duration, err error) {
+				*ops = append(*ops, op)
+			}),
+		}
+	}
+
+	t.Run("exec and query logged", func(t *testing.T) {
+		queries := make([]string, 0)
+		ops := make([]string, 0)
+		conn, c := newConn(&queries, &ops)
+
+		stmt, err := c.PrepareContext(context.Background(), "UPDATE t SET f_a = ? WHERE f_id = ?")
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(conn.prepared).To(gomega.Equal([]string{"UPDATE t SET f_a = $1 WHERE f_id = $2"}))
+
+		_, err = stmt.Exec(args)
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+
+		_, err = stmt.(driver.StmtExecContext).ExecContext(context.Background(), valueToNamedValue(args))
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+
+		rows, err := stmt.Query(args)
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(rows.Close()).To(gomega.BeNil())
+
+		rows, err = stmt.(driver.StmtQueryContext).QueryContext(context.Background(), valueToNamedValue(args))
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(rows.Close()).To(gomega.BeNil())
+
+		gomega.NewWithT(t).Expect(queries).To(gomega.HaveLen(4))
+		gomega.NewWithT(t).Expect(ops).To(gomega.Equal([]string{sqlx.ObserveOpExec, sqlx.ObserveOpExec, sqlx.ObserveOpQuery, sqlx.ObserveOpQuery}))
+	})
+
+	t.Run("failed exec logged", func(t *testing.T) {
+		queries := make([]string, 0)
+		ops := make([]string, 0)
+		conn, c := newConn(&queries, &ops)
+		conn.stmtErr = io.ErrUnexpectedEOF
+
+		stmt, err := c.PrepareContext(context.Background(), "UPDATE t SET f_a = ? WHERE f_id = ?")
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+
+		_, err = stmt.Exec(args)
+		gomega.NewWithT(t).Expect(err).To(gomega.Equal(io.ErrUnexpectedEOF))
+		gomega.NewWithT(t).Expect(ops).To(gomega.Equal([]string{sqlx.ObserveOpExec}))
+	})
+
+	t.Run("rows of copy in not logged", func(t *testing.T) {
+		queries := make([]string, 0)
+		ops := make([]string, 0)
+		_, c := newConn(&queries, &ops)
+
+		stmt, err := c.PrepareContext(context.Background(), "COPY t (f_a) FROM STDIN")
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+
+		_, err = stmt.Exec([]driver.Value{"a"})
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(queries).To(gomega.Equal([]string{"COPY t (f_a) FROM STDIN"}))
+		gomega.NewWithT(t).Expect(ops).To(gomega.BeEmpty())
+	})
+
+	t.Run("prepare failed", func(t *testing.T) {
+		queries := make([]string, 0)
+		ops := make([]string, 0)
+		conn, c := newConn(&queries, &ops)
+		conn.prepareErr = io.ErrUnexpectedEOF
+
+		_, err := c.PrepareContext(context.Background(), "UPDATE t SET f_a = ? WHERE f_id = ?")
+		gomega.NewWithT(t).Expect(err).To(gomega.Equal(io.ErrUnexpectedEOF))
+	})
+}
+
+func TestLoggingRows(t *testing.T) {
+	counts := make([]int, 0)
+
+	rows := &loggingRows{
+		Rows: &fakeRows{n: 3},
+		done: func(count int) {
+			counts = append(counts, count)
+		},
+	}
+
+	dest := make([]driver.Value, 1)
+	for rows.Next(dest) == nil {
+	}
+
+	gomega.NewWithT(t).Expect(rows.Close()).To(gomega.BeNil())
+	gomega.NewWithT(t).Expect(rows.Close()).To(gomega.BeNil())
+	gomega.NewWithT(t).Expect(counts).To(gomega.Equal([]int{3}))
+}
+
+type fakeRows struct {
+	n int
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"f_id"}
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.n == 0 {
+		return io.EOF
+	}
+	r.n--
+	dest[0] = int64(r.n)
+	return nil
+}
+
+func TestLoggerConn_SetStatementTimeout(t *testing.T) {
+	queries := make([]string, 0)
+
+	t.Run("disabled", func(t *testing.T) {
+		c := &loggerConn{Conn: &fakeConn{queries: &queries}}
+
+		gomega.NewWithT(t).Expect(c.setStatementTimeout(context.Background())).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(queries).To(gomega.BeEmpty())
+	})
+
+	t.Run("set", func(t *testing.T) {
+		c := &loggerConn{Conn: &fakeConn{queries: &queries}, statementTimeout: 5 * time.Second}
+
+		gomega.NewWithT(t).Expect(c.setStatementTimeout(context.Background())).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(queries).To(gomega.Equal([]string{"SET statement_timeout = 5000"}))
+	})
+}
+
+func TestLoggerConn_SetSearchPath(t *testing.T) {
+	queries := make([]string, 0)
+
+	t.Run("disabled", func(t *testing.T) {
+		c := &loggerConn{Conn: &fakeConn{queries: &queries}}
+
+		gomega.NewWithT(t).Expect(c.setSearchPath(context.Background())).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(queries).To(gomega.BeEmpty())
+	})
+
+	t.Run("set", func(t *testing.T) {
+		c := &loggerConn{Conn: &fakeConn{queries: &queries}, searchPath: []string{"app", "public"}}
+
+		gomega.NewWithT(t).Expect(c.setSearchPath(context.Background())).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(queries).To(gomega.Equal([]string{`SET search_path TO "app", "public"`}))
+	})
+}
+
+func TestLoggerConn_CountQueries(t *testing.T) {
+	queries := make([]string, 0)
+	values := make([]interface{}, 0)
+
+	c := &loggerConn{
+		Conn:        &preparingConn{fakeConn: fakeConn{queries: &queries}, closed: &[]string{}},
+		logger:      &valuesLogger{Logger: logr.Discard(), values: &values},
+		connectedAt: time.Now(),
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := c.ExecContext(context.Background(), "SELECT 1", nil)
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+	}
+
+	stmt, err := c.PrepareContext(context.Background(), "UPDATE t SET f_a = ?")
+	gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+
+	_, err = stmt.(driver.StmtExecContext).ExecContext(context.Background(), valueToNamedValue([]driver.Value{"a"}))
+	gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+
+	rows, err := stmt.(driver.StmtQueryContext).QueryContext(context.Background(), valueToNamedValue([]driver.Value{"a"}))
+	gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+	gomega.NewWithT(t).Expect(rows.Close()).To(gomega.BeNil())
+
+	gomega.NewWithT(t).Expect(c.queries).To(gomega.Equal(4))
+
+	// logged by logger of the context the conn opened with
+	gomega.NewWithT(t).Expect(c.Close()).To(gomega.BeNil())
+	gomega.NewWithT(t).Expect(values).To(gomega.ContainElements("db.conn.queries", 4))
+}
+
+type failedPingConn struct {
+	fakeConn
+}
+
+func (c *failedPingConn) Ping(ctx context.Context) error {
+	return driver.ErrBadConn
+}
+
+func TestLoggerConn_Ping(t *testing.T) {
+	queries := make([]string, 0)
+
+	t.Run("disabled", func(t *testing.T) {
+		c := &loggerConn{Conn: &failedPingConn{fakeConn: fakeConn{queries: &queries}}}
+
+		gomega.NewWithT(t).Expect(c.ping(context.Background())).To(gomega.BeNil())
+	})
+
+	t.Run("ping by query", func(t *testing.T) {
+		c := &loggerConn{Conn: &fakeConn{queries: &queries}, pingOnConnect: true}
+
+		gomega.NewWithT(t).Expect(c.ping(context.Background())).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(queries).To(gomega.Equal([]string{"SELECT 1"}))
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		c := &loggerConn{Conn: &failedPingConn{fakeConn: fakeConn{queries: &queries}}, pingOnConnect: true}
+
+		gomega.NewWithT(t).Expect(c.ping(context.Background())).To(gomega.Equal(driver.ErrBadConn))
+	})
+}
+
+func TestPostgreSQLLoggingDriver_InvalidStatementTimeout(t *testing.T) {
+	d := &PostgreSQLLoggingDriver{}
+
+	_, err := d.Open("postgres://root@localhost:5432/db?statement_timeout=5")
+	gomega.NewWithT(t).Expect(err).To(gomega.MatchError(gomega.ContainSubstring("invalid statement_timeout")))
+
+	_, err = d.Open("postgres://root@localhost:5432/db?statement_timeout=-5s")
+	gomega.NewWithT(t).Expect(err).To(gomega.MatchError(gomega.ContainSubstring("invalid statement_timeout")))
+}
+
+func TestPostgreSQLLoggingDriver_InvalidLongTxThreshold(t *testing.T) {
+	d := &PostgreSQLLoggingDriver{}
+
+	_, err := d.Open("postgres://root@localhost:5432/db?long_tx_threshold=5")
+	gomega.NewWithT(t).Expect(err).To(gomega.MatchError(gomega.ContainSubstring("invalid long_tx_threshold")))
+}
+
+func TestReplaceValueHolder(t *testing.T) {
+	cases := map[string]struct {
+		query  string
+		expect string
+	}{
+		"values": {
+			"INSERT INTO t (f_id, f_name) VALUES (?, ?)",
+			"INSERT INTO t (f_id, f_name) VALUES ($1, $2)",
+		},
+		"in string": {
+			"SELECT * FROM t WHERE f_name = 'what?' AND f_desc = 'it''s ?' AND f_id = ?",
+			"SELECT * FROM t WHERE f_name = 'what?' AND f_desc = 'it''s ?' AND f_id = $1",
+		},
+		"in identifier": {
+			`SELECT "f_?" FROM t WHERE f_id = ?`,
+			`SELECT "f_?" FROM t WHERE f_id = $1`,
+		},
+		"jsonb contains key": {
+			"SELECT * FROM t WHERE f_data ? 'key' AND f_id = ?",
+			"SELECT * FROM t WHERE f_data ? 'key' AND f_id = $1",
+		},
+		"jsonb contains key of holder": {
+			"SELECT * FROM t WHERE f_data ? ? LIMIT ?",
+			"SELECT * FROM t WHERE f_data ? $1 LIMIT $2",
+		},
+		"jsonb contains any or all keys": {
+			"SELECT * FROM t WHERE f_data ?| ? OR (f_data)?&array['a', 'b']",
+			"SELECT * FROM t WHERE f_data ?| $1 OR (f_data)?&array['a', 'b']",
+		},
+		"concatenated": {
+			"SELECT ?||f_name FROM t",
+			"SELECT $1||f_name FROM t",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			gomega.NewWithT(t).Expect(replaceValueHolder(c.query)).To(gomega.Equal(c.expect))
+		})
+	}
+}
+
+func TestQueryNameFromContext(t *testing.T) {
+	gomega.NewWithT(t).Expect(QueryNameFromContext(context.Background())).To(gomega.Equal(""))
+	gomega.NewWithT(t).Expect(QueryNameFromContext(WithQueryName(context.Background(), "GetUserByID"))).To(gomega.Equal("GetUserByID"))
+}
+
+func TestLoggerConn_QueryRewriters(t *testing.T) {
+	queries := make([]string, 0)
+
+	c := &loggerConn{
+		Conn: &fakeConn{queries: &queries},
+		queryRewriters: []QueryRewriter{
+			func(ctx context.Context, query string) string {
+				return "/* tenant:123 */ " + query
+			},
+			func(ctx context.Context, query string) string {
+				if name := QueryNameFromContext(ctx); name != "" {
+					return "/* " + name + " */ " + query
+				}
+				return query
+			},
+		},
+	}
+
+	_, err := c.ExecContext(WithQueryName(context.Background(), "UpdateUser"), "UPDATE t SET f_a = ? WHERE f_id = ?", []driver.NamedValue{
+		{Ordinal: 1, Value: int64(1)},
+		{Ordinal: 2, Value: int64(2)},
+	})
+	gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+	gomega.NewWithT(t).Expect(queries).To(gomega.Equal([]string{"/* UpdateUser */ /* tenant:123 */ UPDATE t SET f_a = $1 WHERE f_id = $2"}))
+}
+
+type warnLogger struct {
+	logr.Logger
+	warns *[]error
+}
+
+func (l *warnLogger) WithValues(keyAndValues ...interface{}) logr.Logger {
+	return l
+}
+
+func (l *warnLogger) Warn(err error) {
+	*l.warns = append(*l.warns, err)
+}
+
+func TestLoggingTx_CommitAfterContextDone(t *testing.T) {
+	queries := make([]string, 0)
+	warns := make([]error, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	c := &loggerConn{Conn: &fakeConn{queries: &queries}}
+	tx := &loggingTx{ctx: ctx, tx: &fakeTx{queries: &queries}, logger: &warnLogger{Logger: logr.Discard(), warns: &warns}, conn: c, cost: startTimer()}
+
+	cancel()
+
+	gomega.NewWithT(t).Expect(tx.Commit()).To(gomega.BeNil())
+	gomega.NewWithT(t).Expect(queries).To(gomega.Equal([]string{"COMMIT"}))
+	gomega.NewWithT(t).Expect(warns).To(gomega.HaveLen(1))
+	gomega.NewWithT(t).Expect(warns[0]).To(gomega.MatchError("committing transaction after its context done: context canceled"))
+}
+
+type explainConn struct {
+	fakeConn
+	// deadlines records whether ctx of each query has deadline
+	deadlines []bool
+}
+
+func (c *explainConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	*c.queries = append(*c.queries, query)
+	_, ok := ctx.Deadline()
+	c.deadlines = append(c.deadlines, ok)
+	return &planRows{plan: `[{"Plan": {"Node Type": "Seq Scan"}}]`}, nil
+}
+
+type planRows struct {
+	plan string
+}
+
+func (r *planRows) Columns() []string {
+	return []string{"QUERY PLAN"}
+}
+
+func (r *planRows) Close() error {
+	return nil
+}
+
+func (r *planRows) Next(dest []driver.Value) error {
+	if r.plan == "" {
+		return io.EOF
+	}
+	dest[0] = []byte(r.plan)
+	r.plan = ""
+	return nil
+}
+
+func TestLoggerConn_ExplainIfSlow(t *testing.T) {
+	queries := make([]string, 0)
+
+	conn := &explainConn{fakeConn: fakeConn{queries: &queries}}
+	c := &loggerConn{Conn: conn, slowQueryThreshold: time.Millisecond, explainSlow: true}
+
+	t.Run("slow", func(t *testing.T) {
+		queries = queries[0:0]
+		c.explainIfSlow(context.Background(), logr.Discard(), "UPDATE t SET f_a = ? WHERE f_id = ?", []driver.NamedValue{{Ordinal: 1, Value: int64(1)}, {Ordinal: 2, Value: int64(2)}}, time.Second)
+		gomega.NewWithT(t).Expect(queries).To(gomega.Equal([]string{"EXPLAIN (FORMAT JSON) UPDATE t SET f_a = $1 WHERE f_id = $2"}))
+		// limited by explainTimeout
+		gomega.NewWithT(t).Expect(conn.deadlines).To(gomega.Equal([]bool{true}))
+	})
+
+	t.Run("in transaction", func(t *testing.T) {
+		queries = queries[0:0]
+		c.tx = &loggingTx{conn: c}
+		defer func() {
+			c.tx = nil
+		}()
+
+		c.explainIfSlow(context.Background(), logr.Discard(), "SELECT 1", nil, time.Second)
+		gomega.NewWithT(t).Expect(queries).To(gomega.BeEmpty())
+	})
+
+	t.Run("context done", func(t *testing.T) {
+		queries = queries[0:0]
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		c.explainIfSlow(ctx, logr.Discard(), "SELECT 1", nil, time.Second)
+		gomega.NewWithT(t).Expect(queries).To(gomega.BeEmpty())
+	})
+
+	t.Run("not slow", func(t *testing.T) {
+		queries = queries[0:0]
+		c.explainIfSlow(context.Background(), logr.Discard(), "SELECT 1", nil, time.Microsecond)
+		gomega.NewWithT(t).Expect(queries).To(gomega.BeEmpty())
+	})
+
+	t.Run("not explainable", func(t *testing.T) {
+		queries = queries[0:0]
+		c.explainIfSlow(context.Background(), logr.Discard(), "EXPLAIN SELECT 1", nil, time.Second)
+		c.explainIfSlow(context.Background(), logr.Discard(), "CREATE INDEX i ON t (f_a)", nil, time.Second)
+		gomega.NewWithT(t).Expect(queries).To(gomega.BeEmpty())
+	})
+
+	t.Run("plan", func(t *testing.T) {
+		plan, err := c.explain(context.Background(), "SELECT 1", nil)
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(plan).To(gomega.Equal(`[{"Plan": {"Node Type": "Seq Scan"}}]`))
+	})
+}
+
+func TestIsExplainable(t *testing.T) {
+	gomega.NewWithT(t).Expect(isExplainable("SELECT 1")).To(gomega.BeTrue())
+	gomega.NewWithT(t).Expect(isExplainable("  with t AS (SELECT 1) SELECT * FROM t")).To(gomega.BeTrue())
+	gomega.NewWithT(t).Expect(isExplainable("/* tenant:123 */ DELETE FROM t")).To(gomega.BeTrue())
+	gomega.NewWithT(t).Expect(isExplainable("-- name\nINSERT INTO t VALUES (1)")).To(gomega.BeTrue())
+	gomega.NewWithT(t).Expect(isExplainable("/* a */ EXPLAIN (FORMAT JSON) SELECT 1")).To(gomega.BeFalse())
+	gomega.NewWithT(t).Expect(isExplainable("SET statement_timeout = 5000")).To(gomega.BeFalse())
+}
+
+type argsConn struct {
+	fakeConn
+	args *[]driver.NamedValue
+}
+
+func (c *argsConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	*c.args = append(*c.args, args...)
+	return c.fakeConn.ExecContext(ctx, query, args)
+}
+
+func TestLoggerConn_ExecContextWithShuffledArgs(t *testing.T) {
+	queries := make([]string, 0)
+	args := make([]driver.NamedValue, 0)
+
+	c := &loggerConn{Conn: &argsConn{fakeConn: fakeConn{queries: &queries}, args: &args}}
+
+	_, err := c.ExecContext(context.Background(), "UPDATE t SET f_name = $1 WHERE f_id = $2", []driver.NamedValue{
+		{Ordinal: 2, Value: int64(1)},
+		{Ordinal: 1, Value: "name"},
+	})
+
+	gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+	gomega.NewWithT(t).Expect(args).To(gomega.Equal([]driver.NamedValue{
+		{Ordinal: 1, Value: "name"},
+		{Ordinal: 2, Value: int64(1)},
+	}))
+}
+
+func TestLoggerConn_Array(t *testing.T) {
+	c := &loggerConn{}
+
+	t.Run("slices are kept, and bound as arrays", func(t *testing.T) {
+		nv := &driver.NamedValue{Ordinal: 1, Value: builder.Array([]int{1, 2})}
+
+		gomega.NewWithT(t).Expect(c.CheckNamedValue(nv)).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(nv.Value).To(gomega.Equal([]int{1, 2}))
+
+		queries := make([]string, 0)
+		args := make([]driver.NamedValue, 0)
+		c := &loggerConn{Conn: &argsConn{fakeConn: fakeConn{queries: &queries}, args: &args}}
+
+		_, err := c.ExecContext(context.Background(), "DELETE FROM t WHERE f_id = ANY($1) AND f_name = $2", []driver.NamedValue{
+			*nv,
+			{Ordinal: 2, Value: "name"},
+		})
+
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(args).To(gomega.Equal([]driver.NamedValue{
+			{Ordinal: 1, Value: "{1,2}"},
+			{Ordinal: 2, Value: "name"},
+		}))
+	})
+
+	t.Run("others are converted as default", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(c.CheckNamedValue(&driver.NamedValue{Ordinal: 1, Value: []byte("x")})).To(gomega.Equal(driver.ErrSkip))
+		gomega.NewWithT(t).Expect(c.CheckNamedValue(&driver.NamedValue{Ordinal: 1, Value: 1})).To(gomega.Equal(driver.ErrSkip))
+	})
+}
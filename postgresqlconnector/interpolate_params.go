@@ -2,12 +2,22 @@ package postgresqlconnector
 
 import (
 	"database/sql/driver"
+	"encoding/hex"
 	"fmt"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/go-courier/sqlx/v2/builder"
+	"github.com/lib/pq"
 )
 
+// Interpolator renders query with args for logs and span attributes,
+// overriding it could redact sensitive values, the executed query keeps as it is.
+type Interpolator func(query string, args []driver.NamedValue) fmt.Stringer
+
 func interpolateParams(query string, args []driver.NamedValue) fmt.Stringer {
 	return &SqlPrinter{
 		query: query,
@@ -15,6 +25,40 @@ func interpolateParams(query string, args []driver.NamedValue) fmt.Stringer {
 	}
 }
 
+// RedactedValue replaces redacted args in logs
+const RedactedValue = "[REDACTED]"
+
+// RedactArgs returns Interpolator which inlines args like default,
+// but args of the ordinals (starts from 1) are replaced by RedactedValue.
+func RedactArgs(ordinals ...int) Interpolator {
+	redacted := map[int]bool{}
+	for _, ordinal := range ordinals {
+		redacted[ordinal] = true
+	}
+
+	return func(query string, args []driver.NamedValue) fmt.Stringer {
+		finalArgs := make([]driver.NamedValue, len(args))
+		for i, arg := range args {
+			if redacted[arg.Ordinal] {
+				arg.Value = RedactedValue
+			}
+			finalArgs[i] = arg
+		}
+		return interpolateParams(query, finalArgs)
+	}
+}
+
+// WithoutArgs is Interpolator which keeps placeholders as $n instead of inlining any args.
+func WithoutArgs(query string, args []driver.NamedValue) fmt.Stringer {
+	return placeholderPrinter(query)
+}
+
+type placeholderPrinter string
+
+func (p placeholderPrinter) String() string {
+	return replaceValueHolder(string(p))
+}
+
 type SqlPrinter struct {
 	query string
 	args  []driver.NamedValue
@@ -29,7 +73,10 @@ func (p *SqlPrinter) String() string {
 }
 
 func InterpolateParams(query string, args []driver.NamedValue, loc *time.Location) (string, error) {
-	if strings.Count(query, "?") != len(args) {
+	args = sortedByOrdinal(args)
+	holders := builder.ValueHolders(query)
+
+	if len(holders) != len(args) {
 		return "", driver.ErrSkip
 	}
 
@@ -46,87 +93,20 @@ func InterpolateParams(query string, args []driver.NamedValue, loc *time.Locatio
 
 	for i := range data {
 		q := query[i]
-		switch q {
-		case '?':
+
+		if argPos < len(holders) && holders[argPos] == i {
 			arg := args[argPos].Value
 			argPos++
 
-			if arg == nil {
-				buf = append(buf, "NULL"...)
-				continue
+			var err error
+			buf, err = appendValue(buf, arg, loc)
+			if err != nil {
+				return "", err
 			}
+			continue
+		}
 
-			switch v := arg.(type) {
-			case int64:
-				buf = strconv.AppendInt(buf, v, 10)
-			case float64:
-				buf = strconv.AppendFloat(buf, v, 'g', -1, 64)
-			case bool:
-				if v {
-					buf = append(buf, '1')
-				} else {
-					buf = append(buf, '0')
-				}
-			case time.Time:
-				if v.IsZero() {
-					buf = append(buf, "'0000-00-00'"...)
-				} else {
-					v := v.In(loc)
-					v = v.Add(time.Nanosecond * 500) // Write round under microsecond
-					year := v.Year()
-					year100 := year / 100
-					year1 := year % 100
-					month := v.Month()
-					day := v.Day()
-					hour := v.Hour()
-					minute := v.Minute()
-					second := v.Second()
-					micro := v.Nanosecond() / 1000
-
-					buf = append(buf, []byte{
-						'\'',
-						digits10[year100], digits01[year100],
-						digits10[year1], digits01[year1],
-						'-',
-						digits10[month], digits01[month],
-						'-',
-						digits10[day], digits01[day],
-						' ',
-						digits10[hour], digits01[hour],
-						':',
-						digits10[minute], digits01[minute],
-						':',
-						digits10[second], digits01[second],
-					}...)
-
-					if micro != 0 {
-						micro10000 := micro / 10000
-						micro100 := micro / 100 % 100
-						micro1 := micro % 100
-						buf = append(buf, []byte{
-							'.',
-							digits10[micro10000], digits01[micro10000],
-							digits10[micro100], digits01[micro100],
-							digits10[micro1], digits01[micro1],
-						}...)
-					}
-					buf = append(buf, '\'')
-				}
-			case []byte:
-				if v == nil {
-					buf = append(buf, "NULL"...)
-				} else {
-					buf = append(buf, "E'"...)
-					buf = escapeBytesBackslash(buf, v)
-					buf = append(buf, '\'')
-				}
-			case string:
-				buf = append(buf, '\'')
-				buf = escapeBytesBackslash(buf, []byte(v))
-				buf = append(buf, '\'')
-			default:
-				return "", fmt.Errorf("unsupported type %T: %v", v, v)
-			}
+		switch q {
 		case '\n':
 			buf = append(buf, ' ')
 		default:
@@ -141,59 +121,123 @@ func InterpolateParams(query string, args []driver.NamedValue, loc *time.Locatio
 	return string(buf), nil
 }
 
-const digits01 = "0123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789"
-const digits10 = "0000000000111111111122222222223333333333444444444455555555556666666666777777777788888888889999999999"
+func appendValue(buf []byte, arg interface{}, loc *time.Location) ([]byte, error) {
+	if arg == nil {
+		return append(buf, "NULL"...), nil
+	}
 
-func escapeBytesBackslash(buf, v []byte) []byte {
-	pos := len(buf)
-	buf = reserveBuffer(buf, len(v)*2)
+	if isArray(arg) {
+		return appendArray(buf, reflect.ValueOf(arg), loc)
+	}
 
-	for _, c := range v {
-		switch c {
-		case '\x00':
-			buf[pos] = '\\'
-			buf[pos+1] = '0'
-			pos += 2
-		case '\n':
-			buf[pos] = '\\'
-			buf[pos+1] = 'n'
-			pos += 2
-		case '\r':
-			buf[pos] = '\\'
-			buf[pos+1] = 'r'
-			pos += 2
-		case '\x1a':
-			buf[pos] = '\\'
-			buf[pos+1] = 'Z'
-			pos += 2
+	switch v := arg.(type) {
+	case int64:
+		buf = strconv.AppendInt(buf, v, 10)
+	case float64:
+		buf = strconv.AppendFloat(buf, v, 'g', -1, 64)
+	case bool:
+		if v {
+			buf = append(buf, "TRUE"...)
+		} else {
+			buf = append(buf, "FALSE"...)
+		}
+	case time.Time:
+		buf = append(buf, '\'')
+		buf = v.In(loc).AppendFormat(buf, time.RFC3339Nano)
+		buf = append(buf, '\'')
+	case []byte:
+		if v == nil {
+			buf = append(buf, "NULL"...)
+		} else {
+			buf = append(buf, "'\\x"...)
+			buf = append(buf, hex.EncodeToString(v)...)
+			buf = append(buf, '\'')
+		}
+	case string:
+		buf = appendQuoteLiteral(buf, v)
+	default:
+		return nil, fmt.Errorf("unsupported type %T: %v", v, v)
+	}
+
+	return buf, nil
+}
+
+// appendArray appends array as ARRAY[...] could be pasted into psql,
+// elements are converted like args, so elements implemented driver.Valuer like uuid are rendered as their values.
+// empty array is written as '{}', for ARRAY[] could not be typed.
+func appendArray(buf []byte, rv reflect.Value, loc *time.Location) ([]byte, error) {
+	if rv.IsNil() {
+		return append(buf, "NULL"...), nil
+	}
+	if rv.Len() == 0 {
+		return append(buf, "'{}'"...), nil
+	}
+
+	buf = append(buf, "ARRAY["...)
+
+	for i := 0; i < rv.Len(); i++ {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+
+		v, err := driver.DefaultParameterConverter.ConvertValue(rv.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+
+		buf, err = appendValue(buf, v, loc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return append(buf, ']'), nil
+}
+
+// appendQuoteLiteral appends s as string literal could be pasted into psql,
+// quotes are doubled by pq.QuoteLiteral, and literal with backslashes or line breaks is written as E'...' to keep the log in one line.
+func appendQuoteLiteral(buf []byte, s string) []byte {
+	if !strings.ContainsAny(s, "\\\n\r\t") {
+		return append(buf, pq.QuoteLiteral(s)...)
+	}
+
+	buf = append(buf, "E'"...)
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
 		case '\'':
-			buf[pos] = '\\'
-			buf[pos+1] = '\''
-			pos += 2
-		case '"':
-			buf[pos] = '\\'
-			buf[pos+1] = '"'
-			pos += 2
+			buf = append(buf, "''"...)
 		case '\\':
-			buf[pos] = '\\'
-			buf[pos+1] = '\\'
-			pos += 2
+			buf = append(buf, `\\`...)
+		case '\n':
+			buf = append(buf, `\n`...)
+		case '\r':
+			buf = append(buf, `\r`...)
+		case '\t':
+			buf = append(buf, `\t`...)
 		default:
-			buf[pos] = c
-			pos++
+			buf = append(buf, c)
 		}
 	}
 
-	return buf[:pos]
+	return append(buf, '\'')
 }
 
-func reserveBuffer(buf []byte, appendSize int) []byte {
-	newSize := len(buf) + appendSize
-	if cap(buf) < newSize {
-		// Grow buffer exponentially
-		newBuf := make([]byte, len(buf)*2+appendSize)
-		copy(newBuf, buf)
-		buf = newBuf
+// sortedByOrdinal returns args in order of Ordinal, for the n-th value holder binds $n, which takes the arg of Ordinal n.
+// Names of args are not resolved, since postgres binds by position only, and named args are rejected by pq.
+// args in order already are returned as they are.
+func sortedByOrdinal(args []driver.NamedValue) []driver.NamedValue {
+	less := func(args []driver.NamedValue) func(i, j int) bool {
+		return func(i, j int) bool {
+			return args[i].Ordinal < args[j].Ordinal
+		}
+	}
+
+	if sort.SliceIsSorted(args, less(args)) {
+		return args
 	}
-	return buf[:newSize]
+
+	sorted := append(make([]driver.NamedValue, 0, len(args)), args...)
+	sort.SliceStable(sorted, less(sorted))
+	return sorted
 }
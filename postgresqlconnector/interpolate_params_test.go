@@ -1,9 +1,118 @@
 package postgresqlconnector
 
-//func TestInterpolateParams(t *testing.T) {
-//	e := builder.Expr("INSERT INTO t (f_id, f_name, f_photo, f_created) VALUES (?, ?, ?, ?, ?)", 1, "name", []byte("0101000020110F00006E6BA55CA07A694154C51D5FC4715541"), time.Now())
-//
-//	//s, err := InterpolateParams(e.Query())
-//	//NewWithT(t).Expect(err).To(BeNil())
-//	//fmt.Println(s)
-//}
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/onsi/gomega"
+)
+
+func TestInterpolateParams(t *testing.T) {
+	createdAt := time.Date(2021, 6, 1, 12, 30, 0, 500, time.UTC)
+
+	s, err := InterpolateParams(
+		"INSERT INTO t (f_id, f_name, f_photo, f_enabled, f_created_at, f_deleted_at, f_score) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		[]driver.NamedValue{
+			{Ordinal: 1, Value: int64(1)},
+			{Ordinal: 2, Value: "name"},
+			{Ordinal: 3, Value: []byte{0x01, 0xab}},
+			{Ordinal: 4, Value: true},
+			{Ordinal: 5, Value: createdAt},
+			{Ordinal: 6, Value: nil},
+			{Ordinal: 7, Value: 1.5},
+		},
+		time.UTC,
+	)
+
+	gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+	gomega.NewWithT(t).Expect(s).To(gomega.Equal(
+		`INSERT INTO t (f_id, f_name, f_photo, f_enabled, f_created_at, f_deleted_at, f_score) VALUES (1, 'name', '\x01ab', TRUE, '2021-06-01T12:30:00.0000005Z', NULL, 1.5)`,
+	))
+}
+
+func TestInterpolateParams_ShuffledArgs(t *testing.T) {
+	s, err := InterpolateParams(
+		"SELECT * FROM t WHERE f_id = ? AND f_name = ? AND f_enabled = ?",
+		[]driver.NamedValue{
+			{Ordinal: 3, Value: true},
+			{Ordinal: 1, Value: int64(1)},
+			{Ordinal: 2, Value: "name"},
+		},
+		time.UTC,
+	)
+
+	gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+	gomega.NewWithT(t).Expect(s).To(gomega.Equal("SELECT * FROM t WHERE f_id = 1 AND f_name = 'name' AND f_enabled = TRUE"))
+}
+
+func TestInterpolateParams_Array(t *testing.T) {
+	id := uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+
+	s, err := InterpolateParams(
+		"SELECT * FROM t WHERE f_name = ANY(?) AND f_id = ANY(?) AND f_uuid = ANY(?) AND f_tags = ? AND f_codes = ?",
+		[]driver.NamedValue{
+			{Ordinal: 1, Value: []string{"a", "it's"}},
+			{Ordinal: 2, Value: []int{1, 2}},
+			{Ordinal: 3, Value: []uuid.UUID{id}},
+			{Ordinal: 4, Value: []string{}},
+			{Ordinal: 5, Value: []int64(nil)},
+		},
+		time.UTC,
+	)
+
+	gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+	gomega.NewWithT(t).Expect(s).To(gomega.Equal(
+		"SELECT * FROM t WHERE f_name = ANY(ARRAY['a','it''s']) AND f_id = ANY(ARRAY[1,2]) AND f_uuid = ANY(ARRAY['6ba7b810-9dad-11d1-80b4-00c04fd430c8']) AND f_tags = '{}' AND f_codes = NULL",
+	))
+}
+
+func TestInterpolateParams_JSONBOperator(t *testing.T) {
+	s, err := InterpolateParams("SELECT * FROM t WHERE f_data ? 'key' AND f_id = ?", []driver.NamedValue{{Ordinal: 1, Value: int64(1)}}, time.UTC)
+
+	gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+	gomega.NewWithT(t).Expect(s).To(gomega.Equal("SELECT * FROM t WHERE f_data ? 'key' AND f_id = 1"))
+}
+
+func TestInterpolateParams_QuoteLiteral(t *testing.T) {
+	cases := map[string]struct {
+		value  string
+		expect string
+	}{
+		"quote":     {"O'Brien", `SELECT * FROM t WHERE f_name = 'O''Brien'`},
+		"backslash": {`C:\dir`, `SELECT * FROM t WHERE f_name = E'C:\\dir'`},
+		"newline":   {"a\nb's", `SELECT * FROM t WHERE f_name = E'a\nb''s'`},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			s, err := InterpolateParams("SELECT * FROM t WHERE f_name = ?", []driver.NamedValue{{Ordinal: 1, Value: c.value}}, time.UTC)
+
+			gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+			gomega.NewWithT(t).Expect(s).To(gomega.Equal(c.expect))
+		})
+	}
+}
+
+func TestInterpolator(t *testing.T) {
+	query := "SELECT * FROM t WHERE f_email = ? AND f_id = ?"
+	args := []driver.NamedValue{
+		{Ordinal: 1, Value: "a@b.com"},
+		{Ordinal: 2, Value: int64(1)},
+	}
+
+	t.Run("default", func(t *testing.T) {
+		c := &loggerConn{}
+		gomega.NewWithT(t).Expect(c.interpolate(query, args).String()).To(gomega.Equal("SELECT * FROM t WHERE f_email = 'a@b.com' AND f_id = 1"))
+	})
+	t.Run("RedactArgs", func(t *testing.T) {
+		c := &loggerConn{interpolator: RedactArgs(1)}
+		gomega.NewWithT(t).Expect(c.interpolate(query, args).String()).To(gomega.Equal("SELECT * FROM t WHERE f_email = '[REDACTED]' AND f_id = 1"))
+		gomega.NewWithT(t).Expect(args[0].Value).To(gomega.Equal("a@b.com"))
+	})
+	t.Run("WithoutArgs", func(t *testing.T) {
+		c := &loggerConn{interpolator: WithoutArgs}
+		gomega.NewWithT(t).Expect(c.interpolate(query, args).String()).To(gomega.Equal("SELECT * FROM t WHERE f_email = $1 AND f_id = $2"))
+	})
+}
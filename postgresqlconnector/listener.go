@@ -0,0 +1,138 @@
+package postgresqlconnector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-courier/logr"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+var (
+	listenerMinReconnectInterval = time.Second
+	listenerMaxReconnectInterval = time.Minute
+	// listenerPingInterval pings the idle connection to detect connection loss
+	listenerPingInterval = 90 * time.Second
+)
+
+// Notification is delivered by Listener when NOTIFY channel, payload
+type Notification struct {
+	// Channel is the name of channel notified
+	Channel string
+	Payload string
+	// BePid is process id of the backend notified
+	BePid int
+	// Reconnected marks the notification delivered after the connection reestablished, without channel and payload.
+	// Notifications sent during reconnecting are lost, so states kept by notifications should be reloaded.
+	Reconnected bool
+}
+
+// Listen opens a dedicated connection and LISTEN channels, notifications are delivered by Listener.Notifications.
+// The connection is reconnected when lost, and channels are listened again,
+// notifications sent during reconnecting are lost, and a Notification marked Reconnected is delivered instead.
+func (c *PostgreSQLConnector) Listen(ctx context.Context, channels ...string) (*Listener, error) {
+	config, _, err := parseDSN(dsn(c.Host, c.DBName, c.Extra), driverOptKeys...)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := logr.FromContext(ctx).WithValues("db.system", "postgresql", "db.name", c.DBName)
+
+	l := &Listener{
+		notifications: make(chan *Notification),
+		done:          make(chan struct{}),
+	}
+
+	l.l = pq.NewListener(config, listenerMinReconnectInterval, listenerMaxReconnectInterval, func(ev pq.ListenerEventType, err error) {
+		switch ev {
+		case pq.ListenerEventConnected:
+			logger.Info("listener connected")
+		case pq.ListenerEventReconnected:
+			logger.Info("listener reconnected")
+		case pq.ListenerEventDisconnected:
+			logger.Warn(errors.Wrap(err, "listener disconnected"))
+		case pq.ListenerEventConnectionAttemptFailed:
+			logger.Warn(errors.Wrap(err, "listener failed to connect"))
+		}
+	})
+
+	l.notify = l.l.Notify
+	l.ping = l.l.Ping
+
+	for _, channel := range channels {
+		if err := l.Listen(channel); err != nil {
+			_ = l.l.Close()
+			return nil, err
+		}
+	}
+
+	go l.loop()
+
+	return l, nil
+}
+
+type Listener struct {
+	l             *pq.Listener
+	notify        <-chan *pq.Notification
+	ping          func() error
+	notifications chan *Notification
+	done          chan struct{}
+	closeOnce     sync.Once
+}
+
+// Notifications returns channel of notifications, which is closed when the listener closed
+func (l *Listener) Notifications() <-chan *Notification {
+	return l.notifications
+}
+
+func (l *Listener) Listen(channel string) error {
+	return errors.Wrapf(l.l.Listen(channel), "failed to listen %s", channel)
+}
+
+func (l *Listener) Unlisten(channel string) error {
+	return errors.Wrapf(l.l.Unlisten(channel), "failed to unlisten %s", channel)
+}
+
+// Close closes the listener, closing again does nothing
+func (l *Listener) Close() (err error) {
+	l.closeOnce.Do(func() {
+		close(l.done)
+		err = l.l.Close()
+	})
+	return
+}
+
+func (l *Listener) loop() {
+	defer close(l.notifications)
+
+	ticker := time.NewTicker(listenerPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.done:
+			return
+		case <-ticker.C:
+			// error of ping is reported by event callback when disconnected
+			go func() {
+				_ = l.ping()
+			}()
+		case n, ok := <-l.notify:
+			if !ok {
+				return
+			}
+			notification := &Notification{Reconnected: true}
+			// nil is sent after reconnected
+			if n != nil {
+				notification = &Notification{Channel: n.Channel, Payload: n.Extra, BePid: n.BePid}
+			}
+			select {
+			case l.notifications <- notification:
+			case <-l.done:
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package postgresqlconnector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/onsi/gomega"
+)
+
+func TestListener_Loop(t *testing.T) {
+	notify := make(chan *pq.Notification)
+
+	l := &Listener{
+		notify:        notify,
+		ping:          func() error { return nil },
+		notifications: make(chan *Notification),
+		done:          make(chan struct{}),
+	}
+
+	go l.loop()
+
+	go func() {
+		notify <- &pq.Notification{Channel: "c", Extra: "payload", BePid: 1}
+		// sent after reconnected
+		notify <- nil
+		close(notify)
+	}()
+
+	received := make([]*Notification, 0)
+	for n := range l.Notifications() {
+		received = append(received, n)
+	}
+
+	gomega.NewWithT(t).Expect(received).To(gomega.Equal([]*Notification{
+		{Channel: "c", Payload: "payload", BePid: 1},
+		{Reconnected: true},
+	}))
+}
+
+func TestListener_LoopStoppedWhenDone(t *testing.T) {
+	l := &Listener{
+		notify:        make(chan *pq.Notification),
+		ping:          func() error { return nil },
+		notifications: make(chan *Notification),
+		done:          make(chan struct{}),
+	}
+
+	go l.loop()
+
+	close(l.done)
+
+	select {
+	case _, ok := <-l.Notifications():
+		gomega.NewWithT(t).Expect(ok).To(gomega.BeFalse())
+	case <-time.After(time.Second):
+		t.Fatal("notifications not closed")
+	}
+}
+
+func TestListener_Close(t *testing.T) {
+	c := &PostgreSQLConnector{Host: "postgres://127.0.0.1:1", DBName: "db", Extra: "sslmode=disable&connect_timeout=1"}
+
+	l, err := c.Listen(context.Background())
+	gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+
+	gomega.NewWithT(t).Expect(l.Close()).To(gomega.BeNil())
+	gomega.NewWithT(t).Expect(l.Close()).To(gomega.BeNil())
+}
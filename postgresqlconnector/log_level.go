@@ -0,0 +1,50 @@
+package postgresqlconnector
+
+import (
+	"github.com/go-courier/sqlx/v2"
+
+	"github.com/go-courier/logr"
+	"github.com/lib/pq"
+)
+
+type LogLevel int
+
+const (
+	LogLevelError LogLevel = iota
+	LogLevelWarn
+)
+
+// DefaultErrorLogLevels maps SQLSTATE code or class (first two chars of code) to log level of failed queries,
+// expected errors are logged as Warn, others are logged as Error.
+var DefaultErrorLogLevels = map[string]LogLevel{
+	// integrity constraint violations, like unique_violation, foreign_key_violation, not_null_violation, check_violation
+	"23": LogLevelWarn,
+	// serialization_failure
+	"40001": LogLevelWarn,
+	// deadlock_detected
+	"40P01": LogLevelWarn,
+}
+
+// ErrorLogLevels resolves log level of pq.Error by code first, then by class
+type ErrorLogLevels map[string]LogLevel
+
+func (levels ErrorLogLevels) LogLevel(err *pq.Error) LogLevel {
+	if levels == nil {
+		levels = DefaultErrorLogLevels
+	}
+	if level, ok := levels[string(err.Code)]; ok {
+		return level
+	}
+	if level, ok := levels[string(err.Code.Class())]; ok {
+		return level
+	}
+	return LogLevelError
+}
+
+func (c *loggerConn) logFailed(logger logr.Logger, err error) {
+	if pgErr, ok := sqlx.UnwrapAll(err).(*pq.Error); ok && c.errorLogLevels.LogLevel(pgErr) == LogLevelWarn {
+		logger.Warn(err)
+		return
+	}
+	logger.Error(err)
+}
@@ -0,0 +1,32 @@
+package postgresqlconnector
+
+import (
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/onsi/gomega"
+)
+
+func TestErrorLogLevels(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		levels := ErrorLogLevels(nil)
+
+		gomega.NewWithT(t).Expect(levels.LogLevel(&pq.Error{Code: "23505"})).To(gomega.Equal(LogLevelWarn))
+		gomega.NewWithT(t).Expect(levels.LogLevel(&pq.Error{Code: "23503"})).To(gomega.Equal(LogLevelWarn))
+		gomega.NewWithT(t).Expect(levels.LogLevel(&pq.Error{Code: "40001"})).To(gomega.Equal(LogLevelWarn))
+		gomega.NewWithT(t).Expect(levels.LogLevel(&pq.Error{Code: "40P01"})).To(gomega.Equal(LogLevelWarn))
+		gomega.NewWithT(t).Expect(levels.LogLevel(&pq.Error{Code: "40003"})).To(gomega.Equal(LogLevelError))
+		gomega.NewWithT(t).Expect(levels.LogLevel(&pq.Error{Code: "42601"})).To(gomega.Equal(LogLevelError))
+	})
+
+	t.Run("overridden", func(t *testing.T) {
+		levels := ErrorLogLevels{
+			"23":    LogLevelWarn,
+			"23503": LogLevelError,
+		}
+
+		gomega.NewWithT(t).Expect(levels.LogLevel(&pq.Error{Code: "23505"})).To(gomega.Equal(LogLevelWarn))
+		gomega.NewWithT(t).Expect(levels.LogLevel(&pq.Error{Code: "23503"})).To(gomega.Equal(LogLevelError))
+		gomega.NewWithT(t).Expect(levels.LogLevel(&pq.Error{Code: "40001"})).To(gomega.Equal(LogLevelError))
+	})
+}
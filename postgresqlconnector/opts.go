@@ -2,10 +2,152 @@ package postgresqlconnector
 
 import (
 	"bytes"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+const (
+	optSlowQueryThreshold = "slow_query_threshold"
+	// optLongTxThreshold logs transactions kept open longer than it as Warn when committed or rolled back
+	optLongTxThreshold = "long_tx_threshold"
+	// optTraceStatement toggles span attribute db.statement, for sql may be sensitive
+	optTraceStatement = "trace_statement"
+	// optStatementTimeout bounds every query of the conn server-side, like 5s
+	optStatementTimeout = "statement_timeout"
+	// optMaxRetries retries auto-commit statements failed by serialization failure or deadlock, like 3
+	optMaxRetries = "max_retries"
+	// optSearchPath is set as search_path of session when connected, like app,public
+	optSearchPath = "search_path"
+	// optPingOnConnect pings the conn when connected, the conn is closed when ping failed
+	optPingOnConnect = "ping_on_connect"
+	// optStmtCacheSize caches at most n prepared statements of queries with args for each conn, 0 means disabled.
+	// cached statements are executed without context, so should be bounded by statement_timeout
+	optStmtCacheSize = "stmt_cache_size"
+	// optExplainSlow logs plan of slow queries by EXPLAIN (FORMAT JSON) with same args, requires slow_query_threshold
+	optExplainSlow = "explain_slow"
 )
 
+// driverOptKeys are options of the logging driver, which are popped from dsn before passed to pq
+var driverOptKeys = []string{optSlowQueryThreshold, optLongTxThreshold, optTraceStatement, optStatementTimeout, optMaxRetries, optStmtCacheSize, optPingOnConnect, optSearchPath, optExplainSlow}
+
+// configApplicationName is run-time parameter of pq, shown in pg_stat_activity
+const configApplicationName = "application_name"
+
+// parseDSN parses url or key/value dsn into key/value config of pq, with options of the logging driver popped.
+func parseDSN(dsn string, keys ...string) (string, map[string]string, error) {
+	if !isURLDSN(dsn) {
+		config, driverOpts := splitKeyValueDriverOpts(dsn, keys...)
+		return config, driverOpts, nil
+	}
+
+	u, driverOpts, err := splitDriverOpts(dsn, keys...)
+	if err != nil {
+		return "", nil, errors.Wrapf(maskURLError(err), "invalid dsn %s", maskPassword(dsn))
+	}
+
+	config, err := pq.ParseURL(u)
+	if err != nil {
+		return "", nil, errors.Wrapf(maskURLError(err), "invalid dsn %s", maskPassword(dsn))
+	}
+
+	return config, driverOpts, nil
+}
+
+// withApplicationName appends application_name to key/value config of pq when not present,
+// defaults to the process name for correlating server-side stats with services.
+func withApplicationName(config string, name string) string {
+	if _, ok := FromConfigString(config)[configApplicationName]; ok || name == "" {
+		return config
+	}
+
+	value := "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(name) + "'"
+
+	if config == "" {
+		return configApplicationName + "=" + value
+	}
+	return config + " " + configApplicationName + "=" + value
+}
+
+func processName() string {
+	if len(os.Args) == 0 {
+		return ""
+	}
+	return filepath.Base(os.Args[0])
+}
+
+// maskURLError masks password in url of *url.Error, which is returned by url.Parse
+func maskURLError(err error) error {
+	if urlErr, ok := err.(*url.Error); ok {
+		return &url.Error{Op: urlErr.Op, URL: maskPassword(urlErr.URL), Err: urlErr.Err}
+	}
+	return err
+}
+
+func isURLDSN(dsn string) bool {
+	return strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://")
+}
+
+var reURLPassword = regexp.MustCompile(`(://[^:/@]*:)[^@]*@`)
+
+func maskPassword(dsn string) string {
+	return reURLPassword.ReplaceAllString(dsn, "${1}***@")
+}
+
+// splitKeyValueDriverOpts pops options of the logging driver from key/value dsn like `host=localhost slow_query_threshold=200ms`
+func splitKeyValueDriverOpts(dsn string, keys ...string) (string, map[string]string) {
+	driverOpts := map[string]string{}
+
+	isDriverOpt := map[string]bool{}
+	for _, key := range keys {
+		isDriverOpt[key] = true
+	}
+
+	kvs := make([]string, 0)
+
+	for _, kv := range strings.Split(dsn, " ") {
+		if i := strings.Index(kv, "="); i > 0 && isDriverOpt[kv[0:i]] {
+			driverOpts[kv[0:i]] = kv[i+1:]
+			continue
+		}
+		kvs = append(kvs, kv)
+	}
+
+	return strings.Join(kvs, " "), driverOpts
+}
+
+// splitDriverOpts pops options of the logging driver from query of dsn,
+// pq sends unknown options to server as run-time parameters, which will be rejected.
+func splitDriverOpts(dsn string, keys ...string) (string, map[string]string, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", nil, err
+	}
+
+	query := u.Query()
+	driverOpts := map[string]string{}
+
+	for _, key := range keys {
+		if _, ok := query[key]; ok {
+			driverOpts[key] = query.Get(key)
+			query.Del(key)
+		}
+	}
+
+	if len(driverOpts) == 0 {
+		return dsn, driverOpts, nil
+	}
+
+	u.RawQuery = query.Encode()
+	return u.String(), driverOpts, nil
+}
+
 func FromConfigString(s string) PostgreSQLOpts {
 	opts := PostgreSQLOpts{}
 	for _, kv := range strings.Split(s, " ") {
@@ -19,6 +161,20 @@ func FromConfigString(s string) PostgreSQLOpts {
 
 type PostgreSQLOpts map[string]string
 
+// briefOptKeys are keys of config logged by default, others like sslkey may be sensitive
+var briefOptKeys = []string{"host", "port", "dbname", "user", "sslmode"}
+
+// Brief returns opts only with host, port, dbname, user and sslmode
+func (opts PostgreSQLOpts) Brief() PostgreSQLOpts {
+	brief := PostgreSQLOpts{}
+	for _, k := range briefOptKeys {
+		if v, ok := opts[k]; ok {
+			brief[k] = v
+		}
+	}
+	return brief
+}
+
 func (opts PostgreSQLOpts) String() string {
 	buf := bytes.NewBuffer(nil)
 
@@ -13,12 +13,17 @@ import (
 	"github.com/go-courier/sqlx/v2"
 	"github.com/go-courier/sqlx/v2/builder"
 	"github.com/go-courier/sqlx/v2/migration"
+
+	"github.com/go-courier/logr"
 	"github.com/lib/pq"
+	"github.com/pkg/errors"
 )
 
 var _ interface {
 	driver.Connector
 	builder.Dialect
+	builder.EnumTypeDialect
+	builder.PartitionDialect
 } = (*PostgreSQLConnector)(nil)
 
 type PostgreSQLConnector struct {
@@ -26,15 +31,47 @@ type PostgreSQLConnector struct {
 	DBName     string
 	Extra      string
 	Extensions []string
+	// MaxLoggedQueryLength limits the length of interpolated query in logs, 0 means no limit
+	MaxLoggedQueryLength int
+	// ErrorLogLevels overrides DefaultErrorLogLevels
+	ErrorLogLevels ErrorLogLevels
+	// Observer observes queries for metrics, optional
+	Observer sqlx.Observer
+	// CircuitBreaker fast-fails connecting during database outages, optional
+	CircuitBreaker *sqlx.CircuitBreaker
+	// VerboseConnectLog logs full config of connection instead of host, port, dbname, user and sslmode
+	VerboseConnectLog bool
+	// Interpolator renders queries in logs, could be RedactArgs or WithoutArgs to hide sensitive values
+	Interpolator Interpolator
+	// QueryRewriters rewrite queries in order before executed, like adding comments or index hints
+	QueryRewriters []QueryRewriter
+	// IfExists guards creating tables, indexes, columns and dropping columns by IF [NOT] EXISTS,
+	// for re-runnable migrations
+	IfExists bool
 }
 
 func (c *PostgreSQLConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	if c.CircuitBreaker == nil {
+		return c.connect(ctx)
+	}
+
+	var conn driver.Conn
+
+	err := c.CircuitBreaker.Do(ctx, func() (err error) {
+		conn, err = c.connect(ctx)
+		return err
+	})
+
+	return conn, err
+}
+
+func (c *PostgreSQLConnector) connect(ctx context.Context) (driver.Conn, error) {
 	d := c.Driver()
 
-	conn, err := d.Open(dsn(c.Host, c.DBName, c.Extra))
+	conn, err := openContext(ctx, d, dsn(c.Host, c.DBName, c.Extra))
 	if err != nil {
 		if c.IsErrorUnknownDatabase(err) {
-			connectForCreateDB, err := d.Open(dsn(c.Host, "", c.Extra))
+			connectForCreateDB, err := openContext(ctx, d, dsn(c.Host, "", c.Extra))
 			if err != nil {
 				return nil, err
 			}
@@ -44,10 +81,11 @@ func (c *PostgreSQLConnector) Connect(ctx context.Context) (driver.Conn, error)
 			if err := connectForCreateDB.Close(); err != nil {
 				return nil, err
 			}
-			return c.Connect(ctx)
+			return c.connect(ctx)
 		}
 		return nil, err
 	}
+
 	for _, ex := range c.Extensions {
 		if _, err := conn.(driver.ExecerContext).ExecContext(context.Background(), "CREATE EXTENSION IF NOT EXISTS "+ex+";", nil); err != nil {
 			return nil, err
@@ -57,8 +95,45 @@ func (c *PostgreSQLConnector) Connect(ctx context.Context) (driver.Conn, error)
 	return conn, nil
 }
 
-func (PostgreSQLConnector) Driver() driver.Driver {
-	return &PostgreSQLLoggingDriver{}
+// openContext opens conn in goroutine, and returns ctx.Err() when ctx is done before opened,
+// the conn opened after that will be closed.
+func openContext(ctx context.Context, d driver.Driver, dsn string) (driver.Conn, error) {
+	type result struct {
+		conn driver.Conn
+		err  error
+	}
+
+	opened := make(chan result, 1)
+
+	go func() {
+		conn, err := d.Open(dsn)
+		opened <- result{conn: conn, err: err}
+	}()
+
+	select {
+	case r := <-opened:
+		return r.conn, r.err
+	case <-ctx.Done():
+		go func() {
+			if r := <-opened; r.conn != nil {
+				_ = r.conn.Close()
+			}
+		}()
+		err := ctx.Err()
+		logr.FromContext(ctx).Error(errors.Wrap(err, "failed to open connection"))
+		return nil, err
+	}
+}
+
+func (c PostgreSQLConnector) Driver() driver.Driver {
+	return &PostgreSQLLoggingDriver{
+		MaxLoggedQueryLength: c.MaxLoggedQueryLength,
+		ErrorLogLevels:       c.ErrorLogLevels,
+		Observer:             c.Observer,
+		VerboseConnectLog:    c.VerboseConnectLog,
+		Interpolator:         c.Interpolator,
+		QueryRewriters:       c.QueryRewriters,
+	}
 }
 
 func dsn(host string, dbName string, extra string) string {
@@ -90,7 +165,7 @@ func (c *PostgreSQLConnector) Migrate(ctx context.Context, db sqlx.DBExecutor) e
 		}
 
 		if output != nil {
-			_, _ = io.WriteString(output, builder.ResolveExpr(expr).Query())
+			_, _ = io.WriteString(output, builder.ResolveExprContext(builder.ContextWithQuote(ctx, dialect.Quote), expr).Query())
 			_, _ = io.WriteString(output, "\n")
 			return nil
 		}
@@ -115,12 +190,49 @@ func (c *PostgreSQLConnector) Migrate(ctx context.Context, db sqlx.DBExecutor) e
 		prevDB = prevDB.WithSchema(d.Schema)
 	}
 
-	for _, name := range d.Tables.TableNames() {
-		table := d.Table(name)
+	for _, name := range d.EnumTypes.Names() {
+		exprList, err := d.EnumTypes.EnumType(name).Diff(prevDB.EnumTypes.EnumType(name), c)
+		if err != nil {
+			return err
+		}
+		for _, expr := range exprList {
+			if err := exec(expr); err != nil {
+				return err
+			}
+		}
+	}
+
+	tables := d.Tables.WithHistoryTables()
 
-		prevTable := prevDB.Table(name)
+	sorted, cyclic := tables.CreationOrder()
+	fks := make([]builder.SqlExpr, 0)
+
+	for _, table := range sorted {
+		prevTable := prevDB.Table(table.Name)
+
+		if prevTable == nil && table.RenameFrom != "" {
+			if renamedFrom := prevDB.Table(table.RenameFrom); renamedFrom != nil {
+				if err := exec(dialect.RenameTable(renamedFrom, table)); err != nil {
+					return err
+				}
+				prevTable = renamedFrom.Clone()
+				prevTable.Name = table.Name
+			}
+		}
 
 		if prevTable == nil {
+			if cyclic {
+				for _, expr := range dialect.CreateTableIsNotExists(table.WithoutForeignKeys()) {
+					if err := exec(expr); err != nil {
+						return err
+					}
+				}
+				table.ForeignKeys.Range(func(fk *builder.ForeignKey, idx int) {
+					fks = append(fks, dialect.AddForeignKey(fk))
+				})
+				continue
+			}
+
 			for _, expr := range dialect.CreateTableIsNotExists(table) {
 				if err := exec(expr); err != nil {
 					return err
@@ -129,15 +241,24 @@ func (c *PostgreSQLConnector) Migrate(ctx context.Context, db sqlx.DBExecutor) e
 			continue
 		}
 
-		exprList := table.Diff(prevTable, dialect)
-
-		for _, expr := range exprList {
-			if err := exec(expr); err != nil {
+		for _, action := range table.DiffActions(prevTable, dialect) {
+			if cyclic && action.Kind == builder.DiffActionAddForeignKey {
+				fks = append(fks, action.Expr)
+				continue
+			}
+			if err := exec(action.Expr); err != nil {
 				return err
 			}
 		}
 	}
 
+	// foreign keys in cycle could only be added when all tables created
+	for _, expr := range fks {
+		if err := exec(expr); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -145,10 +266,31 @@ func (PostgreSQLConnector) DriverName() string {
 	return "postgres"
 }
 
+// Quote quotes ident by double quotes when NeedsQuote
+func (c PostgreSQLConnector) Quote(ident string) string {
+	if !builder.NeedsQuote(ident, c.IsReservedWord) {
+		return ident
+	}
+	return pq.QuoteIdentifier(ident)
+}
+
+// BindVar returns ordinal placeholder $n of postgres
+func (PostgreSQLConnector) BindVar(i int) string {
+	return "$" + strconv.Itoa(i)
+}
+
+func (PostgreSQLConnector) SupportsReturning() bool {
+	return true
+}
+
 func (PostgreSQLConnector) PrimaryKeyName() string {
 	return "pkey"
 }
 
+func (PostgreSQLConnector) IsReservedWord(name string) bool {
+	return reservedWords[strings.ToLower(name)]
+}
+
 func (PostgreSQLConnector) IsErrorUnknownDatabase(err error) bool {
 	if e, ok := sqlx.UnwrapAll(err).(*pq.Error); ok && e.Code == "3D000" {
 		return true
@@ -184,16 +326,30 @@ func (c *PostgreSQLConnector) DropDatabase(dbName string) builder.SqlExpr {
 	return e
 }
 
+func (c *PostgreSQLConnector) AddPrimaryKey(key *builder.Key) builder.SqlExpr {
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(key.Table)
+	e.WriteString(" ADD PRIMARY KEY ")
+	e.WriteGroup(func(e *builder.Ex) {
+		e.WriteExpr(key.Columns)
+	})
+	writeStorageParams(e, key.StorageParams)
+	e.WriteEnd()
+	return e
+}
+
+func (c *PostgreSQLConnector) DropPrimaryKey(key *builder.Key) builder.SqlExpr {
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(key.Table)
+	e.WriteString(" DROP CONSTRAINT ")
+	e.WriteString(c.Quote(key.Table.Name + "_pkey"))
+	e.WriteEnd()
+	return e
+}
+
 func (c *PostgreSQLConnector) AddIndex(key *builder.Key) builder.SqlExpr {
 	if key.IsPrimary() {
-		e := builder.Expr("ALTER TABLE ")
-		e.WriteExpr(key.Table)
-		e.WriteString(" ADD PRIMARY KEY ")
-		e.WriteGroup(func(e *builder.Ex) {
-			e.WriteExpr(key.Columns)
-		})
-		e.WriteEnd()
-		return e
+		return c.AddPrimaryKey(key)
 	}
 
 	e := builder.Expr("CREATE ")
@@ -201,10 +357,11 @@ func (c *PostgreSQLConnector) AddIndex(key *builder.Key) builder.SqlExpr {
 		e.WriteString("UNIQUE ")
 	}
 	e.WriteString("INDEX ")
+	if c.IfExists {
+		e.WriteString("IF NOT EXISTS ")
+	}
 
-	e.WriteString(key.Table.Name)
-	e.WriteString("_")
-	e.WriteString(key.Name)
+	e.WriteString(c.Quote(key.Table.Name + "_" + key.Name))
 
 	e.WriteString(" ON ")
 	e.WriteExpr(key.Table)
@@ -226,36 +383,249 @@ func (c *PostgreSQLConnector) AddIndex(key *builder.Key) builder.SqlExpr {
 
 	e.WriteByte(' ')
 	e.WriteGroup(func(e *builder.Ex) {
-		e.WriteExpr(key.Columns)
+		e.WriteExpr(key.Members())
 	})
 
+	writeStorageParams(e, key.StorageParams)
+
+	if key.IsPartial() {
+		e.WriteString(" WHERE ")
+		e.WriteString(key.Where)
+	}
+
 	e.WriteEnd()
 	return e
 }
 
 func (c *PostgreSQLConnector) DropIndex(key *builder.Key) builder.SqlExpr {
 	if key.IsPrimary() {
-		e := builder.Expr("ALTER TABLE ")
-		e.WriteExpr(key.Table)
-		e.WriteString(" DROP CONSTRAINT ")
-		e.WriteExpr(key.Table)
-		e.WriteString("_pkey")
-		e.WriteEnd()
-		return e
+		return c.DropPrimaryKey(key)
 	}
 	e := builder.Expr("DROP ")
 
 	e.WriteString("INDEX IF EXISTS ")
-	e.WriteExpr(key.Table)
-	e.WriteByte('_')
-	e.WriteString(key.Name)
+	c.writeIndexName(e, key.Table, key.Name)
+
+	return e
+}
+
+// writeIndexName writes name of index prefixed by table name,
+// qualified by schema of table, or default schema from context when rendering like Table
+func (c *PostgreSQLConnector) writeIndexName(e *builder.Ex, t *builder.Table, keyName string) {
+	e.WriteExpr(builder.ExprBy(func(ctx context.Context) *builder.Ex {
+		schema := t.Schema
+		if schema == "" {
+			schema = builder.DefaultSchemaFromContext(ctx)
+		}
+		if schema != "" {
+			return builder.Expr(c.Quote(schema) + "." + c.Quote(t.Name+"_"+keyName)).Ex(ctx)
+		}
+		return builder.Expr(c.Quote(t.Name + "_" + keyName)).Ex(ctx)
+	}))
+}
+
+func (c *PostgreSQLConnector) RenameIndex(key *builder.Key, target *builder.Key) builder.SqlExpr {
+	e := builder.Expr("ALTER INDEX IF EXISTS ")
+	c.writeIndexName(e, key.Table, key.Name)
+	e.WriteString(" RENAME TO ")
+	e.WriteString(c.Quote(target.Table.Name + "_" + target.Name))
+	e.WriteEnd()
+	return e
+}
+
+// writeStorageParams writes WITH clause of storage parameters, nothing when empty
+func writeStorageParams(e *builder.Ex, params map[string]string) {
+	if len(params) == 0 {
+		return
+	}
+	e.WriteString(" WITH ")
+	writeStorageParamValues(e, params)
+}
+
+func writeStorageParamValues(e *builder.Ex, params map[string]string) {
+	e.WriteGroup(func(e *builder.Ex) {
+		for i, name := range builder.StorageParamNames(params) {
+			if i > 0 {
+				e.WriteString(", ")
+			}
+			e.WriteString(name)
+			e.WriteString(" = ")
+			e.WriteString(params[name])
+		}
+	})
+}
+
+// SetTableStorageParams sets and resets storage parameters of table, by separated statements
+func (c *PostgreSQLConnector) SetTableStorageParams(t *builder.Table, params map[string]string, reset []string) builder.SqlExpr {
+	return c.alterStorageParams(func(e *builder.Ex) {
+		e.WriteString("ALTER TABLE ")
+		e.WriteExpr(t)
+	}, params, reset)
+}
+
+// SetIndexStorageParams sets and resets storage parameters of index, by separated statements,
+// for ALTER INDEX accepts one action only
+func (c *PostgreSQLConnector) SetIndexStorageParams(key *builder.Key, params map[string]string, reset []string) builder.SqlExpr {
+	return c.alterStorageParams(func(e *builder.Ex) {
+		e.WriteString("ALTER INDEX ")
+		if key.IsPrimary() {
+			c.writeIndexName(e, key.Table, "pkey")
+		} else {
+			c.writeIndexName(e, key.Table, key.Name)
+		}
+	}, params, reset)
+}
+
+func (c *PostgreSQLConnector) alterStorageParams(writeTarget func(e *builder.Ex), params map[string]string, reset []string) builder.SqlExpr {
+	exprs := make([]builder.SqlExpr, 0, 2)
+
+	if len(params) > 0 {
+		e := builder.Expr("")
+		writeTarget(e)
+		e.WriteString(" SET ")
+		writeStorageParamValues(e, params)
+		e.WriteEnd()
+		exprs = append(exprs, e)
+	}
+
+	if len(reset) > 0 {
+		e := builder.Expr("")
+		writeTarget(e)
+		e.WriteString(" RESET ")
+		e.WriteGroup(func(e *builder.Ex) {
+			e.WriteString(strings.Join(reset, ", "))
+		})
+		e.WriteEnd()
+		exprs = append(exprs, e)
+	}
+
+	return builder.MultiWith("\n", exprs...)
+}
+
+func (c *PostgreSQLConnector) AddForeignKey(fk *builder.ForeignKey) builder.SqlExpr {
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(fk.Table)
+	e.WriteString(" ADD CONSTRAINT ")
+	e.WriteString(c.Quote(fk.Table.Name + "_" + fk.Name))
+	e.WriteString(" FOREIGN KEY ")
+	e.WriteGroup(func(e *builder.Ex) {
+		e.WriteExpr(fk.Columns)
+	})
+	e.WriteString(" REFERENCES ")
+	e.WriteExpr(fk.RefTable)
+	e.WriteByte(' ')
+	e.WriteGroup(func(e *builder.Ex) {
+		e.WriteExpr(fk.RefColumns)
+	})
+
+	if fk.OnDelete != "" {
+		e.WriteString(" ON DELETE ")
+		e.WriteString(fk.OnDelete)
+	}
+
+	if fk.OnUpdate != "" {
+		e.WriteString(" ON UPDATE ")
+		e.WriteString(fk.OnUpdate)
+	}
+
+	e.WriteEnd()
+	return e
+}
 
+func (c *PostgreSQLConnector) DropForeignKey(fk *builder.ForeignKey) builder.SqlExpr {
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(fk.Table)
+	e.WriteString(" DROP CONSTRAINT IF EXISTS ")
+	e.WriteString(c.Quote(fk.Table.Name + "_" + fk.Name))
+	e.WriteEnd()
+	return e
+}
+
+func (c *PostgreSQLConnector) AddConstraint(constraint *builder.Constraint) builder.SqlExpr {
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(constraint.Table)
+	e.WriteString(" ADD ")
+	c.writeCheckConstraint(e, constraint)
+	e.WriteEnd()
+	return e
+}
+
+func (c *PostgreSQLConnector) DropConstraint(constraint *builder.Constraint) builder.SqlExpr {
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(constraint.Table)
+	e.WriteString(" DROP CONSTRAINT IF EXISTS ")
+	e.WriteString(c.Quote(constraint.Table.Name + "_" + constraint.Name))
+	e.WriteEnd()
+	return e
+}
+
+func (c *PostgreSQLConnector) writeCheckConstraint(e *builder.Ex, constraint *builder.Constraint) {
+	e.WriteString("CONSTRAINT ")
+	e.WriteString(c.Quote(constraint.Table.Name + "_" + constraint.Name))
+	e.WriteString(" CHECK ")
+	e.WriteGroup(func(e *builder.Ex) {
+		e.WriteString(constraint.Def())
+	})
+}
+
+func (c *PostgreSQLConnector) CreateEnumType(enumType *builder.EnumType) builder.SqlExpr {
+	e := builder.Expr("CREATE TYPE ")
+	e.WriteExpr(enumType)
+	e.WriteString(" AS ENUM ")
+	e.WriteGroup(func(e *builder.Ex) {
+		for i, v := range enumType.Values {
+			if i > 0 {
+				e.WriteString(", ")
+			}
+			e.WriteString(quoteLiteral(v))
+		}
+	})
+	e.WriteEnd()
+	return e
+}
+
+// AddEnumValue adds value of enum type, which could not be executed in transaction before PostgreSQL 12
+func (c *PostgreSQLConnector) AddEnumValue(enumType *builder.EnumType, value string, before string, after string) builder.SqlExpr {
+	e := builder.Expr("ALTER TYPE ")
+	e.WriteExpr(enumType)
+	e.WriteString(" ADD VALUE IF NOT EXISTS ")
+	e.WriteString(quoteLiteral(value))
+	if before != "" {
+		e.WriteString(" BEFORE ")
+		e.WriteString(quoteLiteral(before))
+	} else if after != "" {
+		e.WriteString(" AFTER ")
+		e.WriteString(quoteLiteral(after))
+	}
+	e.WriteEnd()
 	return e
 }
 
 func (c *PostgreSQLConnector) CreateTableIsNotExists(t *builder.Table) (exprs []builder.SqlExpr) {
-	expr := builder.Expr("CREATE TABLE IF NOT EXISTS ")
+	return c.createTable(t, true)
+}
+
+func (c *PostgreSQLConnector) CreateTable(t *builder.Table) builder.SqlExpr {
+	return builder.MultiWith("\n", c.createTable(t, c.IfExists)...)
+}
+
+func (c *PostgreSQLConnector) createTable(t *builder.Table, ifNotExists bool) (exprs []builder.SqlExpr) {
+	expr := builder.Expr("CREATE TABLE ")
+	if ifNotExists {
+		expr.WriteString("IF NOT EXISTS ")
+	}
 	expr.WriteExpr(t)
+
+	// columns, keys and constraints are inherited from the partitioned table
+	if t.IsPartition() {
+		expr.WriteString(" PARTITION OF ")
+		expr.WriteExpr(t.PartitionOf.Parent)
+		expr.WriteByte(' ')
+		expr.WriteString(t.PartitionOf.Bound)
+		expr.WriteEnd()
+		return []builder.SqlExpr{expr}
+	}
+
 	expr.WriteByte(' ')
 	expr.WriteGroup(func(e *builder.Ex) {
 		if t.Columns.IsNil() {
@@ -275,7 +645,7 @@ func (c *PostgreSQLConnector) CreateTableIsNotExists(t *builder.Table) (exprs []
 
 			e.WriteExpr(col)
 			e.WriteByte(' ')
-			e.WriteExpr(c.DataType(col.ColumnType))
+			e.WriteExpr(c.columnDef(col))
 		})
 
 		t.Keys.Range(func(key *builder.Key, idx int) {
@@ -287,24 +657,71 @@ func (c *PostgreSQLConnector) CreateTableIsNotExists(t *builder.Table) (exprs []
 				e.WriteGroup(func(e *builder.Ex) {
 					e.WriteExpr(key.Columns)
 				})
+				writeStorageParams(e, key.StorageParams)
 			}
 		})
 
+		t.Constraints.Range(func(constraint *builder.Constraint, idx int) {
+			e.WriteByte(',')
+			e.WriteByte('\n')
+			e.WriteByte('\t')
+			c.writeCheckConstraint(e, constraint)
+		})
+
 		expr.WriteByte('\n')
 	})
 
+	if t.PartitionBy != nil {
+		expr.WriteString(" PARTITION BY ")
+		expr.WriteString(t.PartitionBy.Strategy)
+		expr.WriteByte(' ')
+		expr.WriteGroup(func(e *builder.Ex) {
+			e.WriteExpr(t.PartitionBy.Columns)
+		})
+	}
+
+	writeStorageParams(expr, t.StorageParams)
+
 	expr.WriteEnd()
 	exprs = append(exprs, expr)
 
+	if t.CommentText() != "" {
+		exprs = append(exprs, c.ModifyTableComment(t))
+	}
+
 	t.Keys.Range(func(key *builder.Key, idx int) {
 		if !key.IsPrimary() {
 			exprs = append(exprs, c.AddIndex(key))
 		}
 	})
 
+	t.ForeignKeys.Range(func(fk *builder.ForeignKey, idx int) {
+		exprs = append(exprs, c.AddForeignKey(fk))
+	})
+
 	return
 }
 
+func (c *PostgreSQLConnector) AttachPartition(partition *builder.Table) builder.SqlExpr {
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(partition.PartitionOf.Parent)
+	e.WriteString(" ATTACH PARTITION ")
+	e.WriteExpr(partition)
+	e.WriteByte(' ')
+	e.WriteString(partition.PartitionOf.Bound)
+	e.WriteEnd()
+	return e
+}
+
+func (c *PostgreSQLConnector) DetachPartition(partition *builder.Table) builder.SqlExpr {
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(partition.PartitionOf.Parent)
+	e.WriteString(" DETACH PARTITION ")
+	e.WriteExpr(partition)
+	e.WriteEnd()
+	return e
+}
+
 func (c *PostgreSQLConnector) DropTable(t *builder.Table) builder.SqlExpr {
 	e := builder.Expr("DROP TABLE IF EXISTS ")
 	e.WriteExpr(t)
@@ -312,6 +729,40 @@ func (c *PostgreSQLConnector) DropTable(t *builder.Table) builder.SqlExpr {
 	return e
 }
 
+// RenameTable renames table, with indexes and primary key named with prefix of table name
+func (c *PostgreSQLConnector) RenameTable(from *builder.Table, to *builder.Table) builder.SqlExpr {
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(from)
+	e.WriteString(" RENAME TO ")
+	e.WriteString(c.Quote(to.Name))
+	e.WriteEnd()
+
+	exprs := []builder.SqlExpr{e}
+
+	from.Keys.Range(func(key *builder.Key, idx int) {
+		e := builder.Expr("")
+
+		if key.IsPrimary() {
+			e.WriteString("ALTER TABLE ")
+			e.WriteExpr(to)
+			e.WriteString(" RENAME CONSTRAINT ")
+			e.WriteString(c.Quote(from.Name + "_pkey"))
+			e.WriteString(" TO ")
+			e.WriteString(c.Quote(to.Name + "_pkey"))
+		} else {
+			e.WriteString("ALTER INDEX IF EXISTS ")
+			c.writeIndexName(e, from, key.Name)
+			e.WriteString(" RENAME TO ")
+			e.WriteString(c.Quote(to.Name + "_" + key.Name))
+		}
+
+		e.WriteEnd()
+		exprs = append(exprs, e)
+	})
+
+	return builder.MultiWith("\n", exprs...)
+}
+
 func (c *PostgreSQLConnector) TruncateTable(t *builder.Table) builder.SqlExpr {
 	e := builder.Expr("TRUNCATE TABLE ")
 	e.WriteExpr(t)
@@ -323,13 +774,40 @@ func (c *PostgreSQLConnector) AddColumn(col *builder.Column) builder.SqlExpr {
 	e := builder.Expr("ALTER TABLE ")
 	e.WriteExpr(col.Table)
 	e.WriteString(" ADD COLUMN ")
+	if c.IfExists {
+		e.WriteString("IF NOT EXISTS ")
+	}
 	e.WriteExpr(col)
 	e.WriteByte(' ')
-	e.WriteExpr(c.DataType(col.ColumnType))
+	e.WriteExpr(c.columnDef(col))
 	e.WriteEnd()
 	return e
 }
 
+// columnDef returns data type of column, with generation expression of generated column
+func (c *PostgreSQLConnector) columnDef(col *builder.Column) builder.SqlExpr {
+	if !col.IsGenerated() {
+		return c.DataType(col.ColumnType)
+	}
+
+	if strings.ToUpper(col.GeneratedStorage) == builder.GeneratedStorageVirtual {
+		panic(fmt.Errorf("virtual generated column %s is not supported by postgres", col.Name))
+	}
+
+	dbDataType := dealias(c.dbDataType(col.ColumnType.Type, col.ColumnType))
+
+	e := builder.Expr(dbDataType + autocompleteSize(dbDataType, col.ColumnType))
+	if !col.Null {
+		e.WriteString(" NOT NULL")
+	}
+	e.WriteString(" GENERATED ALWAYS AS ")
+	e.WriteGroup(func(e *builder.Ex) {
+		e.WriteString(col.GeneratedExpr)
+	})
+	e.WriteString(" STORED")
+	return e
+}
+
 func (c *PostgreSQLConnector) RenameColumn(col *builder.Column, target *builder.Column) builder.SqlExpr {
 	e := builder.Expr("ALTER TABLE ")
 	e.WriteExpr(col.Table)
@@ -417,11 +895,105 @@ func (c *PostgreSQLConnector) ModifyColumn(col *builder.Column, prev *builder.Co
 	return e
 }
 
+func (c *PostgreSQLConnector) SetColumnDefault(col *builder.Column) builder.SqlExpr {
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(col.Table)
+	e.WriteString(" ALTER COLUMN ")
+	e.WriteExpr(col)
+	e.WriteString(" SET DEFAULT ")
+	e.WriteString(normalizeDefaultValue(col.Default, c.dataType(col.ColumnType.Type, col.ColumnType)))
+	e.WriteEnd()
+	return e
+}
+
+func (c *PostgreSQLConnector) DropColumnDefault(col *builder.Column) builder.SqlExpr {
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(col.Table)
+	e.WriteString(" ALTER COLUMN ")
+	e.WriteExpr(col)
+	e.WriteString(" DROP DEFAULT")
+	e.WriteEnd()
+	return e
+}
+
+// ModifyColumnRisk tells whether ModifyColumn rewrites the table.
+// Changing NULL or default, widening character varying, changing character varying to text,
+// or raising precision of numeric with same scale are done without rewriting,
+// other changes of data type (even integer to bigint) rewrite the table.
+func (c *PostgreSQLConnector) ModifyColumnRisk(col *builder.Column, prev *builder.Column) builder.Risk {
+	if col.AutoIncrement || c.dataType(col.ColumnType.Type, col.ColumnType) == c.dataType(prev.ColumnType.Type, prev.ColumnType) {
+		return builder.RiskSafe
+	}
+
+	dbDataType := dealias(c.dbDataType(col.ColumnType.Type, col.ColumnType))
+	prevDbDataType := dealias(c.dbDataType(prev.ColumnType.Type, prev.ColumnType))
+
+	switch prevDbDataType {
+	case "character varying":
+		if dbDataType == "text" || (dbDataType == prevDbDataType && varcharLength(col.ColumnType) >= varcharLength(prev.ColumnType)) {
+			return builder.RiskSafe
+		}
+	case "decimal", "numeric":
+		// numeric without precision is unconstrained
+		if dbDataType == prevDbDataType && (col.Length == 0 || (prev.Length > 0 && col.Length >= prev.Length && col.Decimal == prev.Decimal)) {
+			return builder.RiskSafe
+		}
+	}
+
+	return builder.RiskTableRewrite
+}
+
+func varcharLength(columnType *builder.ColumnType) uint64 {
+	if columnType.Length == 0 {
+		return 255
+	}
+	return columnType.Length
+}
+
+func (c *PostgreSQLConnector) ModifyTableComment(t *builder.Table) builder.SqlExpr {
+	e := builder.Expr("COMMENT ON TABLE ")
+	e.WriteExpr(t)
+	e.WriteString(" IS ")
+
+	if comment := t.CommentText(); comment != "" {
+		e.WriteString(quoteLiteral(comment))
+	} else {
+		e.WriteString("NULL")
+	}
+
+	e.WriteEnd()
+	return e
+}
+
+func (c *PostgreSQLConnector) ModifyColumnComment(col *builder.Column) builder.SqlExpr {
+	e := builder.Expr("COMMENT ON COLUMN ")
+	e.WriteExpr(col.Table)
+	e.WriteByte('.')
+	e.WriteString(c.Quote(col.Name))
+	e.WriteString(" IS ")
+
+	if comment := col.CommentText(); comment != "" {
+		e.WriteString(quoteLiteral(comment))
+	} else {
+		e.WriteString("NULL")
+	}
+
+	e.WriteEnd()
+	return e
+}
+
+func quoteLiteral(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
 func (c *PostgreSQLConnector) DropColumn(col *builder.Column) builder.SqlExpr {
 	e := builder.Expr("ALTER TABLE ")
 	e.WriteExpr(col.Table)
 	e.WriteString(" DROP COLUMN ")
-	e.WriteString(col.Name)
+	if c.IfExists {
+		e.WriteString("IF EXISTS ")
+	}
+	e.WriteString(c.Quote(col.Name))
 	e.WriteEnd()
 	return e
 }
@@ -501,6 +1073,11 @@ func (c *PostgreSQLConnector) dbDataType(typ reflect.Type, columnType *builder.C
 func (c *PostgreSQLConnector) dataTypeModify(columnType *builder.ColumnType, dataType string) string {
 	buf := bytes.NewBuffer(nil)
 
+	if columnType.Collation != "" {
+		buf.WriteString(" COLLATE ")
+		buf.WriteString(strconv.Quote(columnType.Collation))
+	}
+
 	if !columnType.Null {
 		buf.WriteString(" NOT NULL")
 	}
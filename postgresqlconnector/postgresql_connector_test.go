@@ -5,7 +5,9 @@ import (
 	"database/sql/driver"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/go-courier/sqlx/v2"
 	"github.com/go-courier/sqlx/v2/builder"
 	"github.com/go-courier/sqlx/v2/builder/buidertestingutils"
 	"github.com/onsi/gomega"
@@ -95,6 +97,1386 @@ func TestPostgreSQLConnector(t *testing.T) {
 	}
 }
 
+func TestPostgreSQLConnector_IsReservedWord(t *testing.T) {
+	c := &PostgreSQLConnector{}
+
+	gomega.NewWithT(t).Expect(c.IsReservedWord("USER")).To(gomega.BeTrue())
+	gomega.NewWithT(t).Expect(c.IsReservedWord("f_name")).To(gomega.BeFalse())
+
+	gomega.NewWithT(t).Expect(builder.T("t", builder.Col("f_name")).CheckReservedWords(c)).To(gomega.BeNil())
+	gomega.NewWithT(t).Expect(builder.T("order", builder.Col("group"), builder.Col("f_name"), builder.Index("limit", builder.Cols("f_name"))).CheckReservedWords(c)).
+		To(gomega.MatchError("table order uses reserved words of postgres: order, group, limit"))
+}
+
+func TestPostgreSQLConnector_Quote(t *testing.T) {
+	c := &PostgreSQLConnector{}
+
+	gomega.NewWithT(t).Expect(c.Quote("order")).To(gomega.Equal(`"order"`))
+	gomega.NewWithT(t).Expect(c.Quote("my-table")).To(gomega.Equal(`"my-table"`))
+	gomega.NewWithT(t).Expect(c.Quote("f_name")).To(gomega.Equal("f_name"))
+
+	table := builder.T("order",
+		builder.Col("f_id").Type(uint64(0), ",autoincrement"),
+		builder.Col("user").Type("", ",size=64"),
+	)
+	table.AddKey(builder.PrimaryKey(builder.Cols("f_id")))
+	table.AddKey(builder.Index("i_user", builder.Cols("user")))
+
+	t.Run("ddl", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(builder.RenderMigration(table.Diff(nil, c), c)).To(gomega.Equal(`-- migration of postgres
+CREATE TABLE "order" (
+	f_id bigserial NOT NULL,
+	"user" character varying(64) NOT NULL,
+	PRIMARY KEY (f_id)
+);
+CREATE INDEX order_i_user ON "order" ("user");
+`))
+
+		next := builder.T("order",
+			builder.Col("f_id").Type(uint64(0), ",autoincrement"),
+			builder.Col("user").Type("", ",deprecated"),
+		)
+		gomega.NewWithT(t).Expect(builder.RenderMigration(next.Diff(table, c), c)).To(gomega.Equal(`-- migration of postgres
+ALTER TABLE "order" DROP COLUMN "user";
+ALTER TABLE "order" DROP CONSTRAINT order_pkey;
+DROP INDEX IF EXISTS order_i_user;
+`))
+	})
+
+	t.Run("rename", func(t *testing.T) {
+		to := builder.T("select", builder.Col("f_id").Type(uint64(0), ""))
+		to.Schema = "app"
+
+		from := table.WithSchema("app")
+
+		ctx := builder.ContextWithQuote(context.Background(), c.Quote)
+
+		gomega.NewWithT(t).Expect(builder.ResolveExprContext(ctx, c.RenameTable(from, to)).Query()).To(gomega.Equal(`ALTER TABLE app."order" RENAME TO "select";
+ALTER TABLE app."select" RENAME CONSTRAINT order_pkey TO select_pkey;
+ALTER INDEX IF EXISTS app.order_i_user RENAME TO select_i_user;`))
+
+		gomega.NewWithT(t).Expect(builder.ResolveExprContext(ctx, c.RenameIndex(from.Key("i_user"), builder.Index("my-user", nil).On(to))).Query()).
+			To(gomega.Equal(`ALTER INDEX IF EXISTS app.order_i_user RENAME TO "select_my-user";`))
+	})
+
+	t.Run("default schema", func(t *testing.T) {
+		to := builder.T("t_account", builder.Col("f_id").Type(uint64(0), ""))
+		from := builder.T("t_user",
+			builder.Col("f_id").Type(uint64(0), ""),
+			builder.Index("i_id", builder.Cols("f_id")),
+		)
+
+		ctx := builder.ContextWithDefaultSchema(builder.ContextWithQuote(context.Background(), c.Quote), "app")
+
+		gomega.NewWithT(t).Expect(builder.ResolveExprContext(ctx, c.DropIndex(from.Key("i_id"))).Query()).
+			To(gomega.Equal(`DROP INDEX IF EXISTS app.t_user_i_id`))
+		gomega.NewWithT(t).Expect(builder.ResolveExprContext(ctx, c.RenameTable(from, to)).Query()).To(gomega.Equal(`ALTER TABLE app.t_user RENAME TO t_account;
+ALTER INDEX IF EXISTS app.t_user_i_id RENAME TO t_account_i_id;`))
+		gomega.NewWithT(t).Expect(builder.ResolveExprContext(ctx, c.CreateEnumType(builder.Enum("user", "a"))).Query()).
+			To(gomega.Equal(`CREATE TYPE app."user" AS ENUM ('a');`))
+	})
+
+	t.Run("query", func(t *testing.T) {
+		ctx := builder.ContextWithQuote(context.Background(), c.Quote)
+
+		e := builder.ResolveExprContext(ctx, builder.Select(table.Col("user")).From(table, builder.Where(table.Col("user").Eq("x"))))
+		gomega.NewWithT(t).Expect(e.Query()).To(gomega.Equal(`SELECT "user" FROM "order"
+WHERE "user" = ?`))
+	})
+}
+
+func TestKeyFromIndexDef(t *testing.T) {
+	c := &PostgreSQLConnector{}
+
+	cols := []builder.TableDefinition{
+		builder.Col("f_id").Type(uint64(0), ""),
+		builder.Col("order").Type("", ",size=64"),
+		builder.Col("f_name").Type("", ",size=64"),
+	}
+
+	table := builder.T("t", append(cols,
+		builder.Index("i_order", builder.Cols("order")).WithOrder("order", builder.KeyColumnOrder{Desc: true}),
+		builder.Index("i_name", builder.Cols("f_id")).WithExprs("lower(f_name)"),
+	)...)
+
+	loaded := builder.T("t", cols...)
+
+	for _, def := range [][2]string{
+		{"t_i_order", `CREATE INDEX t_i_order ON public.t USING btree ("order" DESC)`},
+		{"t_i_name", `CREATE INDEX t_i_name ON public.t USING btree (f_id, lower((f_name)::text))`},
+	} {
+		key, err := keyFromIndexDef(loaded, def[0], def[1])
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		loaded.AddKey(key)
+	}
+
+	gomega.NewWithT(t).Expect(loaded.Key("i_order").Columns.ColNames()).To(gomega.Equal([]string{"order"}))
+	gomega.NewWithT(t).Expect(loaded.Key("i_name").Exprs).To(gomega.Equal([]string{"lower((f_name)::text)"}))
+	gomega.NewWithT(t).Expect(table.Key("i_order").Def()).To(gomega.Equal(loaded.Key("i_order").Def()))
+
+	t.Run("index on reserved word is not changed", func(t *testing.T) {
+		table := builder.T("t", append(cols,
+			builder.Index("i_order", builder.Cols("order")).WithOrder("order", builder.KeyColumnOrder{Desc: true}),
+		)...)
+		loaded := builder.T("t", cols...)
+
+		key, err := keyFromIndexDef(loaded, "t_i_order", `CREATE INDEX t_i_order ON public.t USING btree ("order" DESC)`)
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		loaded.AddKey(key)
+
+		gomega.NewWithT(t).Expect(table.Diff(loaded, c)).To(gomega.BeEmpty())
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		_, err := keyFromIndexDef(builder.T("t", cols...), "t_i_x", `CREATE INDEX t_i_x ON public.t USING btree (f_x)`)
+		gomega.NewWithT(t).Expect(err).NotTo(gomega.BeNil())
+	})
+}
+
+func TestPostgreSQLConnector_IfExists(t *testing.T) {
+	c := &PostgreSQLConnector{IfExists: true}
+
+	table := builder.T("t",
+		builder.Col("f_id").Type(uint64(0), ",autoincrement"),
+		builder.Col("f_name").Type("", ",size=128,default=''"),
+		builder.PrimaryKey(builder.Cols("f_id")),
+		builder.Index("i_name", builder.Cols("f_name")),
+	)
+
+	t.Run("CreateTable", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(c.CreateTable(table)).
+			To(buidertestingutils.BeExpr( /* language=PostgreSQL */ `CREATE TABLE IF NOT EXISTS t (
+	f_id bigserial NOT NULL,
+	f_name character varying(128) NOT NULL DEFAULT ''::character varying,
+	PRIMARY KEY (f_id)
+);
+CREATE INDEX IF NOT EXISTS t_i_name ON t (f_name);`))
+	})
+	t.Run("AddColumn", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(c.AddColumn(table.Col("f_name"))).
+			To(buidertestingutils.BeExpr( /* language=PostgreSQL */ `ALTER TABLE t ADD COLUMN IF NOT EXISTS f_name character varying(128) NOT NULL DEFAULT ''::character varying;`))
+	})
+	t.Run("DropColumn", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(c.DropColumn(table.Col("f_name"))).
+			To(buidertestingutils.BeExpr( /* language=PostgreSQL */ `ALTER TABLE t DROP COLUMN IF EXISTS f_name;`))
+	})
+	t.Run("DropTable", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(c.DropTable(table)).
+			To(buidertestingutils.BeExpr( /* language=PostgreSQL */ `DROP TABLE IF EXISTS t;`))
+	})
+}
+
+func TestPostgreSQLConnector_DiffColumns(t *testing.T) {
+	c := &PostgreSQLConnector{}
+
+	prevTable := builder.T("t",
+		builder.Col("f_name").Type("", ",size=128,default=''"),
+		builder.Col("f_desc").Type("", ",size=128"),
+	)
+
+	table := builder.T("t",
+		builder.Col("f_name").Type("", ",size=128,default=''"),
+		builder.Col("f_desc").Type("", ",size=128,null"),
+	)
+
+	exprs := table.Diff(prevTable, c)
+
+	gomega.NewWithT(t).Expect(exprs).To(gomega.HaveLen(1))
+	gomega.NewWithT(t).Expect(exprs[0]).To(buidertestingutils.BeExpr(
+		"ALTER TABLE t ALTER COLUMN f_desc DROP NOT NULL;",
+	))
+}
+
+func TestPostgreSQLConnector_DiffNewTable(t *testing.T) {
+	c := &PostgreSQLConnector{}
+
+	table := builder.T("t",
+		builder.Col("f_id").Type(uint64(0), ",autoincrement"),
+		builder.Col("f_name").Type("", ",size=128,default=''"),
+		builder.PrimaryKey(builder.Cols("f_id")),
+		builder.UniqueIndex("i_name", builder.Cols("f_name")),
+	).WithSchema("s")
+
+	exprs := table.Diff(nil, c)
+
+	gomega.NewWithT(t).Expect(exprs).To(gomega.HaveLen(1))
+	gomega.NewWithT(t).Expect(exprs[0]).To(buidertestingutils.BeExpr( /* language=PostgreSQL */ `
+CREATE TABLE s.t (
+	f_id bigserial NOT NULL,
+	f_name character varying(128) NOT NULL DEFAULT ''::character varying,
+	PRIMARY KEY (f_id)
+);
+CREATE UNIQUE INDEX t_i_name ON s.t (f_name);
+`))
+}
+
+func TestPostgreSQLConnector_DiffColumnCollation(t *testing.T) {
+	c := &PostgreSQLConnector{}
+
+	prevTable := builder.T("t",
+		builder.Col("f_name").Type("", ",size=128,default=''"),
+		builder.Col("f_code").Type("", ",size=32,default=''"),
+	)
+	prevTable.Col("f_code").Collation = "en_US"
+
+	table := builder.T("t",
+		builder.Col("f_name").Type("", ",size=128,default='',collate=C"),
+		builder.Col("f_code").Type("", ",size=32,default=''"),
+	)
+
+	actions := table.DiffActions(prevTable, c)
+	gomega.NewWithT(t).Expect(actions).To(gomega.HaveLen(1))
+	gomega.NewWithT(t).Expect(actions[0].Kind).To(gomega.Equal(builder.DiffActionModifyColumn))
+	gomega.NewWithT(t).Expect(actions[0].Target).To(gomega.Equal("f_name"))
+
+	gomega.NewWithT(t).Expect(c.AddColumn(table.Col("f_name"))).
+		To(buidertestingutils.BeExpr( /* language=PostgreSQL */ `ALTER TABLE t ADD COLUMN f_name character varying(128) COLLATE "C" NOT NULL DEFAULT ''::character varying;`))
+}
+
+func TestPostgreSQLConnector_DiffColumnComment(t *testing.T) {
+	c := &PostgreSQLConnector{}
+
+	col := builder.Col("f_name").Type("", ",size=128")
+	col.Description = []string{"name", "it's unique"}
+
+	prevCol := builder.Col("f_name").Type("", ",size=128")
+	prevCol.Description = []string{"name"}
+
+	t.Run("changed", func(t *testing.T) {
+		exprs := builder.T("t", col).Diff(builder.T("t", prevCol), c)
+
+		gomega.NewWithT(t).Expect(exprs).To(gomega.HaveLen(1))
+		gomega.NewWithT(t).Expect(exprs[0]).To(buidertestingutils.BeExpr("COMMENT ON COLUMN t.f_name IS 'name\nit''s unique';"))
+	})
+
+	t.Run("cleared", func(t *testing.T) {
+		exprs := builder.T("t", builder.Col("f_name").Type("", ",size=128")).Diff(builder.T("t", prevCol), c)
+
+		gomega.NewWithT(t).Expect(exprs).To(gomega.HaveLen(1))
+		gomega.NewWithT(t).Expect(exprs[0]).To(buidertestingutils.BeExpr("COMMENT ON COLUMN t.f_name IS NULL;"))
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(builder.T("t", col).Diff(builder.T("t", col), c)).To(gomega.HaveLen(0))
+	})
+}
+
+func TestPostgreSQLConnector_TableComment(t *testing.T) {
+	c := &PostgreSQLConnector{}
+
+	table := builder.T("t", builder.Col("f_id").Type(uint64(0), ""))
+	table.Description = []string{"user", "it's unique"}
+
+	prevTable := builder.T("t", builder.Col("f_id").Type(uint64(0), ""))
+	prevTable.Description = []string{"user"}
+
+	t.Run("created", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(c.CreateTable(table)).To(buidertestingutils.BeExpr( /* language=PostgreSQL */ `CREATE TABLE t (
+	f_id bigint NOT NULL
+);
+COMMENT ON TABLE t IS 'user
+it''s unique';`))
+	})
+
+	t.Run("changed", func(t *testing.T) {
+		actions := table.DiffActions(prevTable, c)
+
+		gomega.NewWithT(t).Expect(actions).To(gomega.HaveLen(1))
+		gomega.NewWithT(t).Expect(actions[0].Kind).To(gomega.Equal(builder.DiffActionModifyTableComment))
+		gomega.NewWithT(t).Expect(actions[0].Expr).To(buidertestingutils.BeExpr("COMMENT ON TABLE t IS 'user\nit''s unique';"))
+	})
+
+	t.Run("cleared", func(t *testing.T) {
+		exprs := builder.T("t", builder.Col("f_id").Type(uint64(0), "")).Diff(prevTable, c)
+
+		gomega.NewWithT(t).Expect(exprs).To(gomega.HaveLen(1))
+		gomega.NewWithT(t).Expect(exprs[0]).To(buidertestingutils.BeExpr("COMMENT ON TABLE t IS NULL;"))
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(table.Diff(table.Clone(), c)).To(gomega.HaveLen(0))
+	})
+}
+
+func TestPostgreSQLConnector_ForeignKey(t *testing.T) {
+	c := &PostgreSQLConnector{}
+
+	tUser := builder.T("t_user",
+		builder.Col("f_id").Type(uint64(0), ",autoincrement"),
+	)
+
+	prevTable := builder.T("t_order",
+		builder.Col("f_id").Type(uint64(0), ",autoincrement"),
+		builder.Col("f_user_id").Type(uint64(0), ""),
+		builder.FK("fk_user", builder.Cols("f_user_id")).References(tUser, builder.Cols("f_id")),
+	)
+
+	t.Run("AddForeignKey", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(c.AddForeignKey(
+			prevTable.ForeignKey("fk_user").OnDeleteAction(builder.ForeignKeyActionCascade),
+		)).To(buidertestingutils.BeExpr(
+			"ALTER TABLE t_order ADD CONSTRAINT t_order_fk_user FOREIGN KEY (f_user_id) REFERENCES t_user (f_id) ON DELETE CASCADE;",
+		))
+	})
+
+	t.Run("Diff with changed action", func(t *testing.T) {
+		table := builder.T("t_order",
+			builder.Col("f_id").Type(uint64(0), ",autoincrement"),
+			builder.Col("f_user_id").Type(uint64(0), ""),
+			builder.FK("fk_user", builder.Cols("f_user_id")).References(tUser, builder.Cols("f_id")).OnDeleteAction("cascade"),
+		)
+
+		gomega.NewWithT(t).Expect(builder.MultiWith("\n", table.Diff(prevTable, c)...)).To(buidertestingutils.BeExpr(`
+ALTER TABLE t_order DROP CONSTRAINT IF EXISTS t_order_fk_user;
+ALTER TABLE t_order ADD CONSTRAINT t_order_fk_user FOREIGN KEY (f_user_id) REFERENCES t_user (f_id) ON DELETE CASCADE;
+`))
+	})
+
+	t.Run("Diff with column dropped", func(t *testing.T) {
+		table := builder.T("t_order",
+			builder.Col("f_id").Type(uint64(0), ",autoincrement"),
+			builder.Col("f_user_id").Type(uint64(0), ",deprecated"),
+		)
+
+		gomega.NewWithT(t).Expect(builder.MultiWith("\n", table.Diff(prevTable, c)...)).To(buidertestingutils.BeExpr(`
+ALTER TABLE t_order DROP CONSTRAINT IF EXISTS t_order_fk_user;
+ALTER TABLE t_order DROP COLUMN f_user_id;
+`))
+	})
+}
+
+func TestAddForeignKeys(t *testing.T) {
+	c := &PostgreSQLConnector{}
+
+	tOrg := builder.T("t_org",
+		builder.Col("f_id").Type(uint64(0), ""),
+		builder.Col("f_region").Type("", ",size=8"),
+	)
+
+	cols := []builder.TableDefinition{
+		builder.Col("f_id").Type(uint64(0), ",autoincrement"),
+		builder.Col("f_org_id").Type(uint64(0), ""),
+		builder.Col("f_org_region").Type("", ",size=8"),
+		builder.Col("f_parent_id").Type(uint64(0), ",null"),
+	}
+
+	table := builder.T("t_user", cols...)
+	table.AddForeignKey(builder.FK("fk_org", builder.Cols("f_org_id", "f_org_region")).
+		References(tOrg, builder.Cols("f_id", "f_region")).
+		OnDeleteAction(builder.ForeignKeyActionCascade))
+	table.AddForeignKey(builder.FK("fk_parent", builder.Cols("f_parent_id")).
+		References(table, builder.Cols("f_id")))
+
+	d := sqlx.NewDatabase("db")
+	d.AddTable(builder.T("t_user", cols...))
+
+	// second migration loads foreign keys created by the first one
+	err := addForeignKeys(d, []ForeignKeySchema{
+		{TABLE_NAME: "t_user", CONSTRAINT_NAME: "t_user_fk_org", COLUMN_NAME: "f_org_id", REFERENCED_TABLE_NAME: "t_org", REFERENCED_COLUMN_NAME: "f_id", UPDATE_RULE: "a", DELETE_RULE: "c"},
+		{TABLE_NAME: "t_user", CONSTRAINT_NAME: "t_user_fk_org", COLUMN_NAME: "f_org_region", REFERENCED_TABLE_NAME: "t_org", REFERENCED_COLUMN_NAME: "f_region", UPDATE_RULE: "a", DELETE_RULE: "c"},
+		{TABLE_NAME: "t_user", CONSTRAINT_NAME: "t_user_fk_parent", COLUMN_NAME: "f_parent_id", REFERENCED_TABLE_NAME: "t_user", REFERENCED_COLUMN_NAME: "f_id", UPDATE_RULE: "a", DELETE_RULE: "a"},
+		{TABLE_NAME: "t_other", CONSTRAINT_NAME: "t_other_fk_org", COLUMN_NAME: "f_org_id", REFERENCED_TABLE_NAME: "t_org", REFERENCED_COLUMN_NAME: "f_id", UPDATE_RULE: "a", DELETE_RULE: "a"},
+	})
+	gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+
+	loaded := d.Table("t_user")
+
+	gomega.NewWithT(t).Expect(loaded.ForeignKeys.Len()).To(gomega.Equal(2))
+	gomega.NewWithT(t).Expect(loaded.ForeignKey("fk_org").Columns.ColNames()).To(gomega.Equal([]string{"f_org_id", "f_org_region"}))
+	gomega.NewWithT(t).Expect(table.DiffActions(loaded, c)).To(gomega.BeEmpty())
+
+	t.Run("changed action", func(t *testing.T) {
+		table := builder.T("t_user", cols...)
+		table.AddForeignKey(builder.FK("fk_org", builder.Cols("f_org_id", "f_org_region")).References(tOrg, builder.Cols("f_id", "f_region")))
+		table.AddForeignKey(builder.FK("fk_parent", builder.Cols("f_parent_id")).References(table, builder.Cols("f_id")))
+
+		actions := table.DiffActions(loaded, c)
+
+		gomega.NewWithT(t).Expect(actions).To(gomega.HaveLen(2))
+		gomega.NewWithT(t).Expect(actions[0].Kind).To(gomega.Equal(builder.DiffActionDropForeignKey))
+		gomega.NewWithT(t).Expect(actions[1].Kind).To(gomega.Equal(builder.DiffActionAddForeignKey))
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		err := addForeignKeys(d, []ForeignKeySchema{
+			{TABLE_NAME: "t_user", CONSTRAINT_NAME: "t_user_fk_x", COLUMN_NAME: "f_x", REFERENCED_TABLE_NAME: "t_org", REFERENCED_COLUMN_NAME: "f_id"},
+		})
+		gomega.NewWithT(t).Expect(err).NotTo(gomega.BeNil())
+	})
+}
+
+func TestPostgreSQLConnector_PartialIndex(t *testing.T) {
+	c := &PostgreSQLConnector{}
+
+	cols := []builder.TableDefinition{
+		builder.Col("f_name").Type("", ",size=128"),
+		builder.Col("f_deleted_at").Type(int64(0), ",default='0'"),
+	}
+
+	prevTable := builder.T("t", append(cols, builder.PartialUniqueIndex("i_name", builder.Cols("f_name"), "f_deleted_at = 0"))...)
+
+	t.Run("AddIndex", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(c.AddIndex(prevTable.Key("i_name"))).To(buidertestingutils.BeExpr(
+			"CREATE UNIQUE INDEX t_i_name ON t (f_name) WHERE f_deleted_at = 0;",
+		))
+	})
+
+	t.Run("Diff with changed predicate", func(t *testing.T) {
+		table := builder.T("t", append(cols, builder.PartialUniqueIndex("i_name", builder.Cols("f_name"), "f_deleted_at IS NULL"))...)
+
+		exprs := table.Diff(prevTable, c)
+
+		gomega.NewWithT(t).Expect(exprs).To(gomega.HaveLen(2))
+		gomega.NewWithT(t).Expect(exprs[0]).To(buidertestingutils.BeExpr("DROP INDEX IF EXISTS t_i_name"))
+		gomega.NewWithT(t).Expect(exprs[1]).To(buidertestingutils.BeExpr("CREATE UNIQUE INDEX t_i_name ON t (f_name) WHERE f_deleted_at IS NULL;"))
+	})
+
+	t.Run("Diff with predicate loaded from database", func(t *testing.T) {
+		table := builder.T("t", append(cols, builder.PartialUniqueIndex("i_name", builder.Cols("f_name"), "(f_deleted_at = 0)"))...)
+
+		gomega.NewWithT(t).Expect(table.Diff(prevTable, c)).To(gomega.HaveLen(0))
+	})
+
+	t.Run("Diff to plain index", func(t *testing.T) {
+		table := builder.T("t", append(cols, builder.UniqueIndex("i_name", builder.Cols("f_name")))...)
+
+		exprs := table.Diff(prevTable, c)
+
+		gomega.NewWithT(t).Expect(exprs).To(gomega.HaveLen(2))
+		gomega.NewWithT(t).Expect(exprs[1]).To(buidertestingutils.BeExpr("CREATE UNIQUE INDEX t_i_name ON t (f_name);"))
+	})
+}
+
+func TestPostgreSQLConnector_DiffUniqueness(t *testing.T) {
+	c := &PostgreSQLConnector{}
+
+	cols := []builder.TableDefinition{
+		builder.Col("f_name").Type("", ",size=128"),
+	}
+
+	prevTable := builder.T("t", append(cols, builder.UniqueIndex("i_name", builder.Cols("f_name")))...)
+	table := builder.T("t", append(cols, builder.Index("i_name", builder.Cols("f_name")))...)
+
+	t.Run("to non-unique", func(t *testing.T) {
+		exprs := table.Diff(prevTable, c)
+
+		gomega.NewWithT(t).Expect(exprs).To(gomega.HaveLen(2))
+		gomega.NewWithT(t).Expect(exprs[0]).To(buidertestingutils.BeExpr("DROP INDEX IF EXISTS t_i_name"))
+		gomega.NewWithT(t).Expect(exprs[1]).To(buidertestingutils.BeExpr("CREATE INDEX t_i_name ON t (f_name);"))
+	})
+
+	t.Run("to unique", func(t *testing.T) {
+		exprs := prevTable.Diff(table, c)
+
+		gomega.NewWithT(t).Expect(exprs).To(gomega.HaveLen(2))
+		gomega.NewWithT(t).Expect(exprs[1]).To(buidertestingutils.BeExpr("CREATE UNIQUE INDEX t_i_name ON t (f_name);"))
+	})
+}
+
+func TestPostgreSQLConnector_ExprIndex(t *testing.T) {
+	c := &PostgreSQLConnector{}
+
+	cols := []builder.TableDefinition{
+		builder.Col("f_org_id").Type(uint64(0), ""),
+		builder.Col("f_email").Type("", ",size=128"),
+	}
+
+	prevTable := builder.T("t", append(cols,
+		builder.ExprIndex("i_email", "lower(f_email)"),
+		builder.UniqueIndex("i_org_email", builder.Cols("f_org_id")).WithExprs("lower(f_email)"),
+	)...)
+
+	t.Run("AddIndex", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(c.AddIndex(prevTable.Key("i_email"))).To(buidertestingutils.BeExpr(
+			"CREATE INDEX t_i_email ON t (lower(f_email));",
+		))
+		gomega.NewWithT(t).Expect(c.AddIndex(prevTable.Key("i_org_email"))).To(buidertestingutils.BeExpr(
+			"CREATE UNIQUE INDEX t_i_org_email ON t (f_org_id,lower(f_email));",
+		))
+	})
+
+	t.Run("Diff with changed expression", func(t *testing.T) {
+		table := builder.T("t", append(cols,
+			builder.ExprIndex("i_email", "upper(f_email)"),
+			builder.UniqueIndex("i_org_email", builder.Cols("f_org_id")).WithExprs("lower(f_email)"),
+		)...)
+
+		exprs := table.Diff(prevTable, c)
+
+		gomega.NewWithT(t).Expect(exprs).To(gomega.HaveLen(2))
+		gomega.NewWithT(t).Expect(exprs[0]).To(buidertestingutils.BeExpr("DROP INDEX IF EXISTS t_i_email"))
+		gomega.NewWithT(t).Expect(exprs[1]).To(buidertestingutils.BeExpr("CREATE INDEX t_i_email ON t (upper(f_email));"))
+	})
+}
+
+func TestPostgreSQLConnector_IndexOrder(t *testing.T) {
+	c := &PostgreSQLConnector{}
+
+	cols := []builder.TableDefinition{
+		builder.Col("f_a").Type(0, ""),
+		builder.Col("f_b").Type(0, ",null"),
+	}
+
+	prevTable := builder.T("t", append(cols,
+		builder.Index("i_a_b", builder.Cols("f_a", "f_b")).
+			WithOrder("f_a", builder.KeyColumnOrder{Desc: true, Nulls: builder.NullsFirst}).
+			WithOrder("f_b", builder.KeyColumnOrder{Nulls: builder.NullsFirst}),
+	)...)
+
+	t.Run("AddIndex", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(c.AddIndex(prevTable.Key("i_a_b"))).To(buidertestingutils.BeExpr(
+			"CREATE INDEX t_i_a_b ON t (f_a DESC NULLS FIRST,f_b NULLS FIRST);",
+		))
+	})
+
+	t.Run("Diff with default nulls order omitted", func(t *testing.T) {
+		table := builder.T("t", append(cols,
+			builder.Index("i_a_b", builder.Cols("f_a", "f_b")).
+				WithOrder("f_a", builder.ParseKeyColumnOrder("DESC")).
+				WithOrder("f_b", builder.ParseKeyColumnOrder("NULLS FIRST")),
+		)...)
+
+		gomega.NewWithT(t).Expect(table.Diff(prevTable, c)).To(gomega.BeEmpty())
+	})
+
+	t.Run("Diff with changed order", func(t *testing.T) {
+		table := builder.T("t", append(cols,
+			builder.Index("i_a_b", builder.Cols("f_a", "f_b")).
+				WithOrder("f_b", builder.KeyColumnOrder{Nulls: builder.NullsFirst}),
+		)...)
+
+		exprs := table.Diff(prevTable, c)
+
+		gomega.NewWithT(t).Expect(exprs).To(gomega.HaveLen(2))
+		gomega.NewWithT(t).Expect(exprs[0]).To(buidertestingutils.BeExpr("DROP INDEX IF EXISTS t_i_a_b"))
+		gomega.NewWithT(t).Expect(exprs[1]).To(buidertestingutils.BeExpr("CREATE INDEX t_i_a_b ON t (f_a,f_b NULLS FIRST);"))
+	})
+}
+
+func TestPostgreSQLConnector_GeneratedColumn(t *testing.T) {
+	c := &PostgreSQLConnector{}
+
+	cols := []builder.TableDefinition{
+		builder.Col("f_price").Type(int64(0), ""),
+		builder.Col("f_qty").Type(int64(0), ""),
+	}
+
+	prevTable := builder.T("t", append(cols, builder.Col("f_amount").Type(int64(0), "").GeneratedAs("f_price * f_qty", ""))...)
+
+	t.Run("AddColumn", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(c.AddColumn(prevTable.Col("f_amount"))).To(buidertestingutils.BeExpr(
+			"ALTER TABLE t ADD COLUMN f_amount bigint NOT NULL GENERATED ALWAYS AS (f_price * f_qty) STORED;",
+		))
+	})
+
+	t.Run("Diff with changed expression", func(t *testing.T) {
+		table := builder.T("t", append(cols, builder.Col("f_amount").Type(int64(0), "").GeneratedAs("f_price * f_qty * 2", ""))...)
+
+		exprs := table.Diff(prevTable, c)
+
+		gomega.NewWithT(t).Expect(exprs).To(gomega.HaveLen(2))
+		gomega.NewWithT(t).Expect(exprs[0]).To(buidertestingutils.BeExpr("ALTER TABLE t DROP COLUMN f_amount;"))
+		gomega.NewWithT(t).Expect(exprs[1]).To(buidertestingutils.BeExpr("ALTER TABLE t ADD COLUMN f_amount bigint NOT NULL GENERATED ALWAYS AS (f_price * f_qty * 2) STORED;"))
+	})
+
+	t.Run("Diff with expression loaded from database", func(t *testing.T) {
+		table := builder.T("t", append(cols, builder.Col("f_amount").Type(int64(0), "").GeneratedAs("(f_price * f_qty)", builder.GeneratedStorageStored))...)
+
+		gomega.NewWithT(t).Expect(table.Diff(prevTable, c)).To(gomega.HaveLen(0))
+	})
+
+	t.Run("excluded from assignments", func(t *testing.T) {
+		table := builder.T("t",
+			builder.Col("f_price").Field("Price").Type(int64(0), ""),
+			builder.Col("f_amount").Field("Amount").Type(int64(0), "").GeneratedAs("f_price * 2", ""),
+		)
+
+		fieldValues := builder.FieldValues{"Price": 1, "Amount": 2}
+
+		gomega.NewWithT(t).Expect(table.AssignmentsByFieldValues(fieldValues)).To(gomega.HaveLen(1))
+
+		columns, args := table.ColumnsAndValuesByFieldValues(fieldValues)
+		gomega.NewWithT(t).Expect(columns.FieldNames()).To(gomega.Equal([]string{"Price"}))
+		gomega.NewWithT(t).Expect(args).To(gomega.Equal([]interface{}{1}))
+	})
+}
+
+func TestPostgreSQLConnector_RenameTable(t *testing.T) {
+	c := &PostgreSQLConnector{}
+
+	prevTable := builder.T("t_user",
+		builder.Col("f_id").Type(uint64(0), ",autoincrement"),
+		builder.Col("f_name").Type("", ",size=128"),
+		builder.PrimaryKey(builder.Cols("f_id")),
+		builder.UniqueIndex("i_name", builder.Cols("f_name")),
+	)
+
+	table := builder.T("t_account",
+		builder.Col("f_id").Type(uint64(0), ",autoincrement"),
+		builder.Col("f_name").Type("", ",size=128"),
+	)
+	table.RenameFrom = "t_user"
+
+	gomega.NewWithT(t).Expect(c.RenameTable(prevTable, table)).To(buidertestingutils.BeExpr(`ALTER TABLE t_user RENAME TO t_account;
+ALTER TABLE t_account RENAME CONSTRAINT t_user_pkey TO t_account_pkey;
+ALTER INDEX IF EXISTS t_user_i_name RENAME TO t_account_i_name;`))
+
+	tables := builder.Tables{}
+	tables.Add(table)
+	gomega.NewWithT(t).Expect(tables.TableNamesWithRenameFrom()).To(gomega.Equal([]string{"t_account", "t_user"}))
+}
+
+func TestPostgreSQLConnector_TablesDiffActions(t *testing.T) {
+	c := &PostgreSQLConnector{}
+
+	tUser := builder.T("t_user", builder.Col("f_id").Type(uint64(0), ""))
+	tOrder := builder.T("t_order",
+		builder.Col("f_user_id").Type(uint64(0), ""),
+		builder.FK("fk_user", builder.Cols("f_user_id")).References(tUser, builder.Cols("f_id")),
+	)
+
+	t.Run("referenced tables created first", func(t *testing.T) {
+		tables := builder.Tables{}
+		tables.Add(tOrder, tUser)
+
+		targets := make([]string, 0)
+		for _, action := range tables.DiffActions(nil, c, false) {
+			targets = append(targets, action.Kind+" "+action.Target)
+		}
+		gomega.NewWithT(t).Expect(targets).To(gomega.Equal([]string{"create_table t_user", "create_table t_order"}))
+	})
+
+	t.Run("foreign keys in cycle added after all tables created", func(t *testing.T) {
+		tA := builder.T("t_a",
+			builder.Col("f_id").Type(uint64(0), ""),
+			builder.Col("f_b_id").Type(uint64(0), ""),
+		)
+		tB := builder.T("t_b",
+			builder.Col("f_id").Type(uint64(0), ""),
+			builder.Col("f_a_id").Type(uint64(0), ""),
+			builder.FK("fk_a", builder.Cols("f_a_id")).References(tA, builder.Cols("f_id")),
+		)
+		tA.AddForeignKey(builder.FK("fk_b", builder.Cols("f_b_id")).References(tB, builder.Cols("f_id")))
+
+		tables := builder.Tables{}
+		tables.Add(tA, tB)
+
+		actions := tables.DiffActions(nil, c, false)
+
+		targets := make([]string, 0)
+		for _, action := range actions {
+			targets = append(targets, action.Kind+" "+action.Target)
+		}
+		gomega.NewWithT(t).Expect(targets).To(gomega.Equal([]string{"create_table t_a", "create_table t_b", "add_foreign_key fk_b", "add_foreign_key fk_a"}))
+		gomega.NewWithT(t).Expect(builder.ResolveExpr(actions[0].Expr).Query()).NotTo(gomega.ContainSubstring("FOREIGN KEY"))
+	})
+}
+
+func TestPostgreSQLConnector_DiffColumnDefault(t *testing.T) {
+	c := &PostgreSQLConnector{}
+
+	tableWith := func(tagValue string) *builder.Table {
+		return builder.T("t",
+			builder.Col("f_count").Type(0, tagValue),
+		)
+	}
+
+	cases := map[string]struct {
+		prev   string
+		next   string
+		expect string
+	}{
+		"add default": {
+			"", ",default='0'",
+			"ALTER TABLE t ALTER COLUMN f_count SET DEFAULT '0'::integer;",
+		},
+		"change default": {
+			",default='0'", ",default='1'",
+			"ALTER TABLE t ALTER COLUMN f_count SET DEFAULT '1'::integer;",
+		},
+		"remove default": {
+			",default='1'", "",
+			"ALTER TABLE t ALTER COLUMN f_count DROP DEFAULT;",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actions := tableWith(tc.next).DiffActions(tableWith(tc.prev), c)
+
+			gomega.NewWithT(t).Expect(actions).To(gomega.HaveLen(1))
+			gomega.NewWithT(t).Expect(actions[0].Expr).To(buidertestingutils.BeExpr(tc.expect))
+		})
+	}
+
+	t.Run("default and type changed", func(t *testing.T) {
+		actions := builder.T("t", builder.Col("f_count").Type(int64(0), ",default='1'")).DiffActions(tableWith(",default='0'"), c)
+
+		gomega.NewWithT(t).Expect(actions).To(gomega.HaveLen(1))
+		gomega.NewWithT(t).Expect(actions[0].Kind).To(gomega.Equal(builder.DiffActionModifyColumn))
+	})
+}
+
+func TestPostgreSQLConnector_DiffActions(t *testing.T) {
+	c := &PostgreSQLConnector{}
+
+	prevTable := builder.T("t",
+		builder.Col("f_name").Type("", ",size=128,default=''"),
+		builder.Col("f_desc").Type("", ",size=128"),
+		builder.Index("i_desc", builder.Cols("f_desc")),
+	)
+
+	table := builder.T("t",
+		builder.Col("f_name").Type("", ",size=255,default=''"),
+		builder.Col("f_desc").Type("", ",deprecated"),
+		builder.Col("f_nickname").Type("", ",size=128,default=''"),
+	)
+
+	kinds := make([]string, 0)
+	targets := make([]string, 0)
+
+	for _, action := range table.DiffActions(prevTable, c) {
+		kinds = append(kinds, action.Kind)
+		targets = append(targets, action.Target)
+	}
+
+	gomega.NewWithT(t).Expect(kinds).To(gomega.Equal([]string{
+		builder.DiffActionModifyColumn,
+		builder.DiffActionDropColumn,
+		builder.DiffActionAddColumn,
+		builder.DiffActionDropIndex,
+	}))
+	gomega.NewWithT(t).Expect(targets).To(gomega.Equal([]string{"f_name", "f_desc", "f_nickname", "i_desc"}))
+	gomega.NewWithT(t).Expect(table.Diff(prevTable, c)).To(gomega.HaveLen(4))
+
+	actions := table.DiffActions(nil, c)
+	gomega.NewWithT(t).Expect(actions).To(gomega.HaveLen(1))
+	gomega.NewWithT(t).Expect(actions[0].Kind).To(gomega.Equal(builder.DiffActionCreateTable))
+	gomega.NewWithT(t).Expect(actions[0].Target).To(gomega.Equal("t"))
+}
+
+func TestPostgreSQLConnector_DiffBackfill(t *testing.T) {
+	c := &PostgreSQLConnector{}
+
+	prevTable := builder.T("t", builder.Col("f_count").Type(int64(0), ",null"))
+
+	t.Run("without backfill", func(t *testing.T) {
+		actions := builder.T("t", builder.Col("f_count").Type(int64(0), "")).DiffActions(prevTable, c)
+
+		gomega.NewWithT(t).Expect(actions).To(gomega.HaveLen(1))
+		gomega.NewWithT(t).Expect(actions[0].Kind).To(gomega.Equal(builder.DiffActionModifyColumn))
+		gomega.NewWithT(t).Expect(actions[0].RequiresBackfill).To(gomega.BeTrue())
+	})
+
+	t.Run("with backfill", func(t *testing.T) {
+		actions := builder.T("t", builder.Col("f_count").Type(int64(0), ",backfill='0'")).DiffActions(prevTable, c)
+
+		gomega.NewWithT(t).Expect(actions).To(gomega.HaveLen(2))
+		gomega.NewWithT(t).Expect(actions[0].Kind).To(gomega.Equal(builder.DiffActionBackfillColumn))
+		gomega.NewWithT(t).Expect(actions[0].Expr).To(buidertestingutils.BeExpr("UPDATE t SET f_count = '0' WHERE f_count IS NULL;"))
+		gomega.NewWithT(t).Expect(actions[1].Kind).To(gomega.Equal(builder.DiffActionModifyColumn))
+		gomega.NewWithT(t).Expect(actions[1].RequiresBackfill).To(gomega.BeFalse())
+	})
+
+	t.Run("drop not null", func(t *testing.T) {
+		actions := prevTable.DiffActions(builder.T("t", builder.Col("f_count").Type(int64(0), ",backfill='0'")), c)
+
+		gomega.NewWithT(t).Expect(actions).To(gomega.HaveLen(1))
+		gomega.NewWithT(t).Expect(actions[0].RequiresBackfill).To(gomega.BeFalse())
+	})
+}
+
+func TestPostgreSQLConnector_DiffWith(t *testing.T) {
+	c := &PostgreSQLConnector{}
+
+	table := builder.T("t_doc",
+		builder.Col("f_id").Type(uint64(0), ""),
+		builder.Col("f_title").Type("", ",size=255"),
+		builder.Index("i_title", builder.Cols("f_title")),
+	)
+
+	prevTable := builder.T("t_doc",
+		builder.Col("f_id").Type(uint64(0), ""),
+		builder.Col("f_title").Type("", ",size=128"),
+		builder.Col("f_search").Type("", ""),
+		builder.Index("i_search", builder.Cols("f_search")).Using("GIST"),
+	)
+
+	opts := builder.DiffOptions{IgnoreColumns: []string{"f_search"}, IgnoreIndexes: []string{"i_search", "i_title"}}
+
+	gomega.NewWithT(t).Expect(builder.RenderMigration(table.DiffWith(prevTable, c, opts), c)).To(gomega.Equal(`-- migration of postgres
+ALTER TABLE t_doc ALTER COLUMN f_title TYPE character varying(255) /* FROM character varying(128) */;
+`))
+
+	t.Run("ignored are not created", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(builder.RenderMigration(prevTable.DiffWith(nil, c, opts), c)).To(gomega.Equal(`-- migration of postgres
+CREATE TABLE t_doc (
+	f_id bigint NOT NULL,
+	f_title character varying(128) NOT NULL
+);
+`))
+	})
+}
+
+func TestPostgreSQLConnector_ModifyColumnRisk(t *testing.T) {
+	c := &PostgreSQLConnector{}
+
+	numeric := func(tagValue string) *builder.Column {
+		col := builder.Col("f_amount").Type(float64(0), tagValue)
+		col.GetDataType = func(engine string) string {
+			return "numeric"
+		}
+		return col
+	}
+
+	cases := map[string]struct {
+		prev   *builder.Column
+		next   *builder.Column
+		expect builder.Risk
+	}{
+		"widen varchar": {
+			builder.Col("f_name").Type("", ",size=50"),
+			builder.Col("f_name").Type("", ",size=100"),
+			builder.RiskSafe,
+		},
+		"varchar to text": {
+			builder.Col("f_name").Type("", ",size=50"),
+			builder.Col("f_name").Type("", ",size=65535"),
+			builder.RiskSafe,
+		},
+		"narrow varchar": {
+			builder.Col("f_name").Type("", ",size=100"),
+			builder.Col("f_name").Type("", ",size=50"),
+			builder.RiskTableRewrite,
+		},
+		"raise precision of numeric": {
+			numeric(",size=10,decimal=2"),
+			numeric(",size=12,decimal=2"),
+			builder.RiskSafe,
+		},
+		"change scale of numeric": {
+			numeric(",size=10,decimal=2"),
+			numeric(",size=12,decimal=4"),
+			builder.RiskTableRewrite,
+		},
+		"integer to bigint": {
+			builder.Col("f_count").Type(int32(0), ""),
+			builder.Col("f_count").Type(int64(0), ""),
+			builder.RiskTableRewrite,
+		},
+		"drop not null": {
+			builder.Col("f_count").Type(int64(0), ""),
+			builder.Col("f_count").Type(int64(0), ",null"),
+			builder.RiskSafe,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actions := builder.T("t", tc.next).DiffActions(builder.T("t", tc.prev), c)
+
+			gomega.NewWithT(t).Expect(actions).To(gomega.HaveLen(1))
+			gomega.NewWithT(t).Expect(actions[0].Kind).To(gomega.Equal(builder.DiffActionModifyColumn))
+			gomega.NewWithT(t).Expect(actions[0].Risk).To(gomega.Equal(tc.expect))
+		})
+	}
+}
+
+func TestPostgreSQLConnector_DiffPrimaryKey(t *testing.T) {
+	c := &PostgreSQLConnector{}
+
+	prevTable := builder.T("t",
+		builder.Col("f_org_id").Type(uint64(0), ""),
+		builder.Col("f_user_id").Type(uint64(0), ""),
+		builder.Col("f_role").Type("", ",size=32"),
+		builder.PrimaryKey(builder.Cols("f_org_id", "f_user_id")),
+	)
+
+	table := builder.T("t",
+		builder.Col("f_org_id").Type(uint64(0), ""),
+		builder.Col("f_user_id").Type(uint64(0), ""),
+		builder.Col("f_role").Type("", ",size=32"),
+		builder.PrimaryKey(builder.Cols("f_org_id", "f_user_id", "f_role")),
+	)
+
+	actions := table.DiffActions(prevTable, c)
+
+	gomega.NewWithT(t).Expect(actions).To(gomega.HaveLen(2))
+	gomega.NewWithT(t).Expect(actions[0].Kind).To(gomega.Equal(builder.DiffActionDropPrimaryKey))
+	gomega.NewWithT(t).Expect(actions[1].Kind).To(gomega.Equal(builder.DiffActionAddPrimaryKey))
+	gomega.NewWithT(t).Expect(builder.HasDestructiveChanges(actions)).To(gomega.BeTrue())
+
+	gomega.NewWithT(t).Expect(builder.RenderMigration(table.Diff(prevTable, c), c)).To(gomega.Equal(`-- migration of postgres
+ALTER TABLE t DROP CONSTRAINT t_pkey;
+ALTER TABLE t ADD PRIMARY KEY (f_org_id,f_user_id,f_role);
+`))
+
+	gomega.NewWithT(t).Expect(table.DiffActions(table, c)).To(gomega.HaveLen(0))
+}
+
+func TestPostgreSQLConnector_TablesDiff(t *testing.T) {
+	c := &PostgreSQLConnector{}
+
+	prev := &builder.Tables{}
+	prev.Add(
+		builder.T("t_user",
+			builder.Col("f_name").Type("", ",size=128"),
+		),
+		builder.T("t_legacy",
+			builder.Col("f_name").Type("", ",size=128"),
+		),
+	)
+
+	tables := &builder.Tables{}
+	tables.Add(
+		builder.T("t_user",
+			builder.Col("f_name").Type("", ",size=128"),
+			builder.Col("f_age").Type(0, ",default='0'"),
+		),
+		builder.T("t_org",
+			builder.Col("f_name").Type("", ",size=128"),
+		),
+	)
+
+	t.Run("KeepMissing", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(builder.RenderMigration(tables.Diff(prev, c, false), c)).To(gomega.Equal(`-- migration of postgres
+CREATE TABLE t_org (
+	f_name character varying(128) NOT NULL
+);
+ALTER TABLE t_user ADD COLUMN f_age integer NOT NULL DEFAULT '0'::integer;
+`))
+	})
+
+	t.Run("DropMissing", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(builder.RenderMigration(tables.Diff(prev, c, true), c)).To(gomega.Equal(`-- migration of postgres
+CREATE TABLE t_org (
+	f_name character varying(128) NOT NULL
+);
+ALTER TABLE t_user ADD COLUMN f_age integer NOT NULL DEFAULT '0'::integer;
+DROP TABLE IF EXISTS t_legacy;
+`))
+	})
+
+	t.Run("Destructive", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(builder.HasDestructiveChanges(tables.DiffActions(prev, c, false))).To(gomega.BeFalse())
+
+		actions := tables.DiffActions(prev, c, true)
+		gomega.NewWithT(t).Expect(builder.HasDestructiveChanges(actions)).To(gomega.BeTrue())
+
+		last := actions[len(actions)-1]
+		gomega.NewWithT(t).Expect(last.Kind).To(gomega.Equal(builder.DiffActionDropTable))
+		gomega.NewWithT(t).Expect(last.Target).To(gomega.Equal("t_legacy"))
+		gomega.NewWithT(t).Expect(last.IsDestructive()).To(gomega.BeTrue())
+		gomega.NewWithT(t).Expect(actions[0].IsDestructive()).To(gomega.BeFalse())
+	})
+}
+
+func TestPostgreSQLConnector_VersionedTable(t *testing.T) {
+	c := &PostgreSQLConnector{}
+
+	tableWith := func(cols ...*builder.Column) *builder.Table {
+		table := builder.T("t_user",
+			builder.Col("f_id").Type(uint64(0), ",autoincrement"),
+			builder.Col("f_name").Type("", ",size=128"),
+		)
+		table.AddCols(cols...)
+		table.AddKey(builder.PrimaryKey(builder.Cols("f_id")))
+		table.Versioned = true
+		return table
+	}
+
+	tables := &builder.Tables{}
+	tables.Add(tableWith())
+
+	t.Run("create", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(builder.RenderMigration(tables.Diff(nil, c, false), c)).To(gomega.Equal(`-- migration of postgres
+CREATE TABLE t_user (
+	f_id bigserial NOT NULL,
+	f_name character varying(128) NOT NULL,
+	PRIMARY KEY (f_id)
+);
+CREATE TABLE t_user_history (
+	f_id bigint,
+	f_name character varying(128),
+	valid_from timestamp with time zone NOT NULL,
+	valid_to timestamp with time zone
+);
+CREATE INDEX t_user_history_i_history ON t_user_history (f_id,valid_from);
+`))
+	})
+
+	t.Run("add column", func(t *testing.T) {
+		next := &builder.Tables{}
+		next.Add(tableWith(builder.Col("f_age").Type(0, ",default='0'")))
+
+		gomega.NewWithT(t).Expect(builder.RenderMigration(next.Diff(tables.WithHistoryTables(), c, true), c)).To(gomega.Equal(`-- migration of postgres
+ALTER TABLE t_user ADD COLUMN f_age integer NOT NULL DEFAULT '0'::integer;
+ALTER TABLE t_user_history ADD COLUMN f_age integer DEFAULT '0'::integer;
+`))
+	})
+
+	t.Run("add column to prev without history tables", func(t *testing.T) {
+		next := &builder.Tables{}
+		next.Add(tableWith(builder.Col("f_age").Type(0, ",default='0'")))
+
+		gomega.NewWithT(t).Expect(builder.RenderMigration(next.Diff(tables, c, true), c)).To(gomega.Equal(`-- migration of postgres
+ALTER TABLE t_user ADD COLUMN f_age integer NOT NULL DEFAULT '0'::integer;
+ALTER TABLE t_user_history ADD COLUMN f_age integer DEFAULT '0'::integer;
+`))
+	})
+}
+
+func TestPostgreSQLConnector_RenameIndex(t *testing.T) {
+	c := &PostgreSQLConnector{}
+
+	prevTable := builder.T("t_user",
+		builder.Col("f_name").Type("", ",size=128"),
+		builder.Col("f_age").Type(0, ""),
+		builder.UniqueIndex("name", builder.Cols("f_name")),
+		builder.Index("age", builder.Cols("f_age")),
+	)
+
+	renamed := builder.UniqueIndex("i_name", builder.Cols("f_name"))
+	renamed.RenameFrom = "name"
+
+	changed := builder.Index("i_age", builder.Cols("f_name", "f_age"))
+	changed.RenameFrom = "age"
+
+	table := builder.T("t_user",
+		builder.Col("f_name").Type("", ",size=128"),
+		builder.Col("f_age").Type(0, ""),
+		renamed,
+		changed,
+	)
+
+	gomega.NewWithT(t).Expect(builder.RenderMigration(table.Diff(prevTable, c), c)).To(gomega.Equal(`-- migration of postgres
+ALTER INDEX IF EXISTS t_user_name RENAME TO t_user_i_name;
+CREATE INDEX t_user_i_age ON t_user (f_name,f_age);
+DROP INDEX IF EXISTS t_user_age;
+`))
+}
+
+func TestPostgreSQLConnector_StorageParams(t *testing.T) {
+	c := &PostgreSQLConnector{}
+
+	table := builder.T("t_user",
+		builder.Col("f_id").Type(uint64(0), ""),
+		builder.Col("f_name").Type("", ",size=128"),
+		builder.PrimaryKey(builder.Cols("f_id")),
+		builder.Index("i_name", builder.Cols("f_name")),
+	)
+	table.StorageParams = map[string]string{"fillfactor": "70", "autovacuum_vacuum_scale_factor": "0.05"}
+	table.Key("i_name").StorageParams = map[string]string{"fillfactor": "80"}
+
+	t.Run("create", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(builder.RenderMigration(table.Diff(nil, c), c)).To(gomega.Equal(`-- migration of postgres
+CREATE TABLE t_user (
+	f_id bigint NOT NULL,
+	f_name character varying(128) NOT NULL,
+	PRIMARY KEY (f_id)
+) WITH (autovacuum_vacuum_scale_factor = 0.05, fillfactor = 70);
+CREATE INDEX t_user_i_name ON t_user (f_name) WITH (fillfactor = 80);
+`))
+	})
+
+	t.Run("changed only", func(t *testing.T) {
+		prevTable := table.Clone()
+		prevTable.StorageParams = map[string]string{"fillfactor": "70", "autovacuum_enabled": "false"}
+		prevTable.Key("i_name").StorageParams = map[string]string{"fillfactor": "90"}
+
+		actions := table.DiffActions(prevTable, c)
+		gomega.NewWithT(t).Expect(actions).To(gomega.HaveLen(2))
+		gomega.NewWithT(t).Expect(actions[0].Kind).To(gomega.Equal(builder.DiffActionSetTableStorageParams))
+		gomega.NewWithT(t).Expect(actions[1].Kind).To(gomega.Equal(builder.DiffActionSetIndexStorageParams))
+
+		gomega.NewWithT(t).Expect(builder.RenderMigration(table.Diff(prevTable, c), c)).To(gomega.Equal(`-- migration of postgres
+ALTER TABLE t_user SET (autovacuum_vacuum_scale_factor = 0.05);
+ALTER TABLE t_user RESET (autovacuum_enabled);
+ALTER INDEX t_user_i_name SET (fillfactor = 80);
+`))
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(table.Diff(table.Clone(), c)).To(gomega.BeEmpty())
+	})
+
+	t.Run("ignored by other dialects", func(t *testing.T) {
+		prevTable := table.Clone()
+		prevTable.StorageParams = nil
+
+		gomega.NewWithT(t).Expect(table.Diff(prevTable, &noStorageParamsDialect{c})).To(gomega.BeEmpty())
+	})
+}
+
+type noStorageParamsDialect struct {
+	builder.Dialect
+}
+
+func TestPostgreSQLConnector_Partition(t *testing.T) {
+	c := &PostgreSQLConnector{}
+
+	table := builder.T("t_event",
+		builder.Col("f_id").Type(uint64(0), ""),
+		builder.Col("f_created_at").Type(time.Time{}, ""),
+		builder.PrimaryKey(builder.Cols("f_id", "f_created_at")),
+	).PartitionedBy(builder.PartitionStrategyRange, "f_created_at")
+
+	partition := table.Partition("t_event_2024", "FOR VALUES FROM ('2024-01-01') TO ('2025-01-01')")
+
+	tables := &builder.Tables{}
+	tables.Add(table, partition)
+
+	gomega.NewWithT(t).Expect(builder.RenderMigration(tables.Diff(&builder.Tables{}, c, false), c)).To(gomega.Equal(`-- migration of postgres
+CREATE TABLE t_event (
+	f_id bigint NOT NULL,
+	f_created_at timestamp with time zone NOT NULL,
+	PRIMARY KEY (f_id,f_created_at)
+) PARTITION BY RANGE (f_created_at);
+CREATE TABLE t_event_2024 PARTITION OF t_event FOR VALUES FROM ('2024-01-01') TO ('2025-01-01');
+`))
+
+	t.Run("columns of partition are not diffed", func(t *testing.T) {
+		prev := partition.Clone()
+		prev.Columns = builder.Columns{}
+
+		gomega.NewWithT(t).Expect(partition.Diff(prev, c)).To(gomega.BeEmpty())
+	})
+
+	t.Run("attach and detach", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(c.AttachPartition(partition)).
+			To(buidertestingutils.BeExpr("ALTER TABLE t_event ATTACH PARTITION t_event_2024 FOR VALUES FROM ('2024-01-01') TO ('2025-01-01');"))
+		gomega.NewWithT(t).Expect(c.DetachPartition(partition)).
+			To(buidertestingutils.BeExpr("ALTER TABLE t_event DETACH PARTITION t_event_2024;"))
+	})
+}
+
+func TestPostgreSQLConnector_EnumType(t *testing.T) {
+	c := &PostgreSQLConnector{}
+
+	prev := builder.Enum("state", "active", "inactive").WithSchema("s")
+
+	t.Run("Create", func(t *testing.T) {
+		exprList, err := prev.Diff(nil, c)
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(builder.RenderMigration(exprList, c)).To(gomega.Equal(`-- migration of postgres
+CREATE TYPE s.state AS ENUM ('active', 'inactive');
+`))
+	})
+
+	t.Run("AddValues", func(t *testing.T) {
+		enumType := builder.Enum("state", "draft", "pending", "active", "suspended", "inactive", "archived").WithSchema("s")
+
+		exprList, err := enumType.Diff(prev, c)
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(builder.RenderMigration(exprList, c)).To(gomega.Equal(`-- migration of postgres
+ALTER TYPE s.state ADD VALUE IF NOT EXISTS 'pending' BEFORE 'active';
+ALTER TYPE s.state ADD VALUE IF NOT EXISTS 'draft' BEFORE 'pending';
+ALTER TYPE s.state ADD VALUE IF NOT EXISTS 'suspended' AFTER 'active';
+ALTER TYPE s.state ADD VALUE IF NOT EXISTS 'archived' AFTER 'inactive';
+`))
+	})
+
+	t.Run("Unchanged", func(t *testing.T) {
+		exprList, err := prev.Diff(prev, c)
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(exprList).To(gomega.BeEmpty())
+	})
+
+	t.Run("DropValue", func(t *testing.T) {
+		_, err := builder.Enum("state", "active").Diff(prev, c)
+		gomega.NewWithT(t).Expect(err).To(gomega.MatchError("value inactive of enum type state could not be dropped"))
+	})
+
+	t.Run("ReorderValues", func(t *testing.T) {
+		_, err := builder.Enum("state", "inactive", "active").Diff(prev, c)
+		gomega.NewWithT(t).Expect(err).To(gomega.MatchError("values of enum type state could not be reordered"))
+	})
+}
+
+func TestPostgreSQLConnector_Constraint(t *testing.T) {
+	c := &PostgreSQLConnector{}
+
+	prevTable := builder.T("t_user",
+		builder.Col("f_age").Type(0, ""),
+		builder.Check("c_age", builder.Col("f_age").Expr("# >= ?", 0)),
+	)
+
+	t.Run("CreateTable", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(c.CreateTable(prevTable)).To(buidertestingutils.BeExpr(`CREATE TABLE t_user (
+	f_age integer NOT NULL,
+	CONSTRAINT t_user_c_age CHECK (f_age >= 0)
+);`))
+	})
+
+	t.Run("Diff with changed expression", func(t *testing.T) {
+		table := builder.T("t_user",
+			builder.Col("f_age").Type(0, ""),
+			builder.Check("c_age", builder.Col("f_age").Expr("# >= ?", 18)),
+		)
+
+		exprs := table.Diff(prevTable, c)
+
+		gomega.NewWithT(t).Expect(exprs).To(gomega.HaveLen(2))
+		gomega.NewWithT(t).Expect(exprs[0]).To(buidertestingutils.BeExpr("ALTER TABLE t_user DROP CONSTRAINT IF EXISTS t_user_c_age;"))
+		gomega.NewWithT(t).Expect(exprs[1]).To(buidertestingutils.BeExpr("ALTER TABLE t_user ADD CONSTRAINT t_user_c_age CHECK (f_age >= 18);"))
+	})
+
+	t.Run("Diff unchanged", func(t *testing.T) {
+		table := builder.T("t_user",
+			builder.Col("f_age").Type(0, ""),
+			builder.Check("c_age", builder.Expr("(f_age >= 0)")),
+		)
+
+		gomega.NewWithT(t).Expect(table.Diff(prevTable, c)).To(gomega.HaveLen(0))
+	})
+
+	t.Run("Diff with dropped", func(t *testing.T) {
+		exprs := builder.T("t_user", builder.Col("f_age").Type(0, "")).Diff(prevTable, c)
+
+		gomega.NewWithT(t).Expect(exprs).To(gomega.HaveLen(1))
+		gomega.NewWithT(t).Expect(exprs[0]).To(buidertestingutils.BeExpr("ALTER TABLE t_user DROP CONSTRAINT IF EXISTS t_user_c_age;"))
+	})
+
+	t.Run("quoted names", func(t *testing.T) {
+		tOrg := builder.T("t_org", builder.Col("f_id").Type(uint64(0), ""))
+
+		table := builder.T("t_user",
+			builder.Col("f_age").Type(0, ""),
+			builder.Col("f_org_id").Type(uint64(0), ""),
+			builder.Check("c-age", builder.Col("f_age").Expr("# >= ?", 0)),
+			builder.FK("fk-org", builder.Cols("f_org_id")).References(tOrg, builder.Cols("f_id")),
+		)
+
+		gomega.NewWithT(t).Expect(c.AddConstraint(table.Constraint("c-age"))).
+			To(buidertestingutils.BeExpr(`ALTER TABLE t_user ADD CONSTRAINT "t_user_c-age" CHECK (f_age >= 0);`))
+		gomega.NewWithT(t).Expect(c.DropConstraint(table.Constraint("c-age"))).
+			To(buidertestingutils.BeExpr(`ALTER TABLE t_user DROP CONSTRAINT IF EXISTS "t_user_c-age";`))
+		gomega.NewWithT(t).Expect(c.AddForeignKey(table.ForeignKey("fk-org"))).
+			To(buidertestingutils.BeExpr(`ALTER TABLE t_user ADD CONSTRAINT "t_user_fk-org" FOREIGN KEY (f_org_id) REFERENCES t_org (f_id);`))
+		gomega.NewWithT(t).Expect(c.DropForeignKey(table.ForeignKey("fk-org"))).
+			To(buidertestingutils.BeExpr(`ALTER TABLE t_user DROP CONSTRAINT IF EXISTS "t_user_fk-org";`))
+	})
+}
+
+func TestAddConstraints(t *testing.T) {
+	c := &PostgreSQLConnector{}
+
+	cols := []builder.TableDefinition{
+		builder.Col("f_age").Type(0, ""),
+		builder.Col("f_name").Type("", ",size=64"),
+	}
+
+	table := builder.T("t_user", append(cols,
+		builder.Check("c_age", builder.Col("f_age").Expr("# >= ?", 0)),
+		builder.Check("c_name", builder.Expr("f_name <> '' AND lower(f_name) <> 'admin'")),
+	)...)
+
+	d := sqlx.NewDatabase("db")
+	d.AddTable(builder.T("t_user", cols...))
+
+	// second migration loads constraints created by the first one
+	addConstraints(d, []ConstraintSchema{
+		{TABLE_NAME: "t_user", CONSTRAINT_NAME: "t_user_c_age", CHECK_CLAUSE: "CHECK ((f_age >= 0))"},
+		{TABLE_NAME: "t_user", CONSTRAINT_NAME: "t_user_c_name", CHECK_CLAUSE: "CHECK ((((f_name)::text <> ''::text) AND (lower((f_name)::text) <> 'admin'::text))) NOT VALID"},
+		{TABLE_NAME: "t_other", CONSTRAINT_NAME: "t_other_c_age", CHECK_CLAUSE: "CHECK ((f_age >= 0))"},
+	})
+
+	loaded := d.Table("t_user")
+
+	gomega.NewWithT(t).Expect(loaded.Constraints.Len()).To(gomega.Equal(2))
+	gomega.NewWithT(t).Expect(table.DiffActions(loaded, c)).To(gomega.BeEmpty())
+
+	t.Run("changed expression", func(t *testing.T) {
+		table := builder.T("t_user", append(cols,
+			builder.Check("c_age", builder.Col("f_age").Expr("# >= ?", 18)),
+			builder.Check("c_name", builder.Expr("f_name <> '' AND lower(f_name) <> 'admin'")),
+		)...)
+
+		actions := table.DiffActions(loaded, c)
+
+		gomega.NewWithT(t).Expect(actions).To(gomega.HaveLen(2))
+		gomega.NewWithT(t).Expect(actions[0].Kind).To(gomega.Equal(builder.DiffActionDropConstraint))
+		gomega.NewWithT(t).Expect(actions[1].Kind).To(gomega.Equal(builder.DiffActionAddConstraint))
+	})
+}
+
+func TestRenderMigration(t *testing.T) {
+	c := &PostgreSQLConnector{}
+
+	prevTable := builder.T("t",
+		builder.Col("f_name").Type("", ",size=128"),
+	)
+
+	col := builder.Col("f_status").Type(int64(0), ",default='0'")
+	col.Description = []string{"it's status"}
+
+	table := builder.T("t",
+		builder.Col("f_name").Type("", ",size=128"),
+		col,
+	)
+
+	t.Run("RenderDiff", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(table.RenderDiff(prevTable, c)).To(gomega.Equal(`-- table t
+ALTER TABLE t ADD COLUMN f_status bigint NOT NULL DEFAULT '0'::bigint;
+COMMENT ON COLUMN t.f_status IS 'it''s status';
+`))
+	})
+
+	t.Run("RenderMigration with args", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(builder.RenderMigration([]builder.SqlExpr{
+			builder.Update(table).Set(builder.ColumnsAndValues(builder.Cols("f_name"), "it's ?")).Where(builder.Col("f_status").Eq(1)),
+			nil,
+		}, c)).To(gomega.Equal(`-- migration of postgres
+UPDATE t SET f_name = 'it''s ?'
+WHERE f_status = 1;
+`))
+	})
+}
+
+func TestOpenContext(t *testing.T) {
+	t.Run("opened", func(t *testing.T) {
+		d := &blockingDriver{release: make(chan struct{})}
+		close(d.release)
+
+		conn, err := openContext(context.Background(), d, "")
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(conn).NotTo(gomega.BeNil())
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		d := &blockingDriver{release: make(chan struct{}), closed: make(chan struct{})}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := openContext(ctx, d, "")
+		gomega.NewWithT(t).Expect(err).To(gomega.Equal(context.DeadlineExceeded))
+
+		close(d.release)
+		gomega.NewWithT(t).Eventually(d.closed).Should(gomega.BeClosed())
+	})
+}
+
+type blockingDriver struct {
+	release chan struct{}
+	closed  chan struct{}
+}
+
+func (d *blockingDriver) Open(dsn string) (driver.Conn, error) {
+	<-d.release
+	return &blockingConn{closed: d.closed}, nil
+}
+
+type blockingConn struct {
+	driver.Conn
+	closed chan struct{}
+}
+
+func (c *blockingConn) Close() error {
+	close(c.closed)
+	return nil
+}
+
 type Point struct {
 	X float64
 	Y float64
@@ -0,0 +1,23 @@
+package postgresqlconnector
+
+import (
+	"context"
+)
+
+type contextKeyQueryName int
+
+// WithQueryName names queries executed with the ctx, like GetUserByID,
+// which is logged as db.query.name for attributing queries to callers.
+func WithQueryName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, contextKeyQueryName(1), name)
+}
+
+func QueryNameFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if name, ok := ctx.Value(contextKeyQueryName(1)).(string); ok {
+		return name
+	}
+	return ""
+}
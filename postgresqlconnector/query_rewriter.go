@@ -0,0 +1,17 @@
+package postgresqlconnector
+
+import (
+	"context"
+)
+
+// QueryRewriter rewrites query before executed, like adding comment `/* tenant:123 */` for routing,
+// the query holds value holders as ?, and the rewritten query is executed and logged.
+type QueryRewriter func(ctx context.Context, query string) string
+
+// rewrite applies query rewriters in order
+func (c *loggerConn) rewrite(ctx context.Context, query string) string {
+	for _, rewrite := range c.queryRewriters {
+		query = rewrite(ctx, query)
+	}
+	return query
+}
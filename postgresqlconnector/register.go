@@ -0,0 +1,86 @@
+package postgresqlconnector
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-courier/sqlx/v2"
+)
+
+// Options configures the logging driver programmatically instead of by options of dsn,
+// options of dsn take precedence over the ones here.
+type Options struct {
+	MaxLoggedQueryLength int
+	ErrorLogLevels       ErrorLogLevels
+	Observer             sqlx.Observer
+	VerboseConnectLog    bool
+	Interpolator         Interpolator
+	QueryRewriters       []QueryRewriter
+
+	SlowQueryThreshold time.Duration
+	ExplainSlow        bool
+	LongTxThreshold    time.Duration
+	// TraceStatement nil means true
+	TraceStatement   *bool
+	StatementTimeout time.Duration
+	MaxRetries       int
+	StmtCacheSize    int
+	PingOnConnect    bool
+	SearchPath       []string
+}
+
+// Register registers the logging driver configured by opts as name, then sql.Open(name, dsn) could be used.
+// It panics like sql.Register when called twice with same name.
+func Register(name string, opts Options) {
+	sql.Register(name, opts.Driver())
+}
+
+// Driver returns the logging driver configured by opts
+func (opts Options) Driver() *PostgreSQLLoggingDriver {
+	return &PostgreSQLLoggingDriver{
+		MaxLoggedQueryLength: opts.MaxLoggedQueryLength,
+		ErrorLogLevels:       opts.ErrorLogLevels,
+		Observer:             opts.Observer,
+		VerboseConnectLog:    opts.VerboseConnectLog,
+		Interpolator:         opts.Interpolator,
+		QueryRewriters:       opts.QueryRewriters,
+		defaultOpts:          opts.driverOpts(),
+	}
+}
+
+// driverOpts returns options set as options of dsn, which are validated as same as the ones from dsn
+func (opts Options) driverOpts() map[string]string {
+	driverOpts := map[string]string{}
+
+	if opts.SlowQueryThreshold != 0 {
+		driverOpts[optSlowQueryThreshold] = opts.SlowQueryThreshold.String()
+	}
+	if opts.ExplainSlow {
+		driverOpts[optExplainSlow] = strconv.FormatBool(opts.ExplainSlow)
+	}
+	if opts.LongTxThreshold != 0 {
+		driverOpts[optLongTxThreshold] = opts.LongTxThreshold.String()
+	}
+	if opts.TraceStatement != nil {
+		driverOpts[optTraceStatement] = strconv.FormatBool(*opts.TraceStatement)
+	}
+	if opts.StatementTimeout != 0 {
+		driverOpts[optStatementTimeout] = opts.StatementTimeout.String()
+	}
+	if opts.MaxRetries != 0 {
+		driverOpts[optMaxRetries] = strconv.Itoa(opts.MaxRetries)
+	}
+	if opts.StmtCacheSize != 0 {
+		driverOpts[optStmtCacheSize] = strconv.Itoa(opts.StmtCacheSize)
+	}
+	if opts.PingOnConnect {
+		driverOpts[optPingOnConnect] = strconv.FormatBool(opts.PingOnConnect)
+	}
+	if len(opts.SearchPath) > 0 {
+		driverOpts[optSearchPath] = strings.Join(opts.SearchPath, ",")
+	}
+
+	return driverOpts
+}
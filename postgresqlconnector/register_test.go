@@ -0,0 +1,77 @@
+package postgresqlconnector
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+)
+
+type fakeDriver struct {
+	queries *[]string
+}
+
+func (d *fakeDriver) Open(dsn string) (driver.Conn, error) {
+	return &fakeConn{queries: d.queries}, nil
+}
+
+func TestRegister(t *testing.T) {
+	traceStatement := false
+
+	Register("postgres-test", Options{
+		MaxLoggedQueryLength: 1024,
+		SlowQueryThreshold:   200 * time.Millisecond,
+		TraceStatement:       &traceStatement,
+		StatementTimeout:     5 * time.Second,
+		SearchPath:           []string{"app", "public"},
+		PingOnConnect:        true,
+	})
+
+	db, err := sql.Open("postgres-test", "postgres://root@localhost:5432/db")
+	gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+	defer db.Close()
+
+	queries := make([]string, 0)
+
+	d := db.Driver().(*PostgreSQLLoggingDriver)
+	d.driver = &fakeDriver{queries: &queries}
+
+	gomega.NewWithT(t).Expect(d.MaxLoggedQueryLength).To(gomega.Equal(1024))
+
+	t.Run("session is set up when connected", func(t *testing.T) {
+		conn, err := db.Conn(context.Background())
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		defer conn.Close()
+
+		gomega.NewWithT(t).Expect(queries).To(gomega.Equal([]string{
+			"SET statement_timeout = 5000",
+			`SET search_path TO "app", "public"`,
+			"SELECT 1",
+		}))
+	})
+
+	t.Run("options of dsn take precedence", func(t *testing.T) {
+		queries = queries[0:0]
+
+		db, err := sql.Open("postgres-test", "postgres://root@localhost:5432/db?statement_timeout=1s&ping_on_connect=false")
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		defer db.Close()
+
+		gomega.NewWithT(t).Expect(db.Ping()).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(queries).To(gomega.Equal([]string{
+			"SET statement_timeout = 1000",
+			`SET search_path TO "app", "public"`,
+		}))
+	})
+
+	t.Run("options are validated as dsn options", func(t *testing.T) {
+		_, err := Options{MaxRetries: -1}.Driver().Open("postgres://root@localhost:5432/db")
+		gomega.NewWithT(t).Expect(err).To(gomega.MatchError(gomega.ContainSubstring("invalid max_retries")))
+
+		_, err = d.Open("postgres://root@localhost:5432/db?slow_query_threshold=x")
+		gomega.NewWithT(t).Expect(err).To(gomega.MatchError(gomega.ContainSubstring("invalid slow_query_threshold")))
+	})
+}
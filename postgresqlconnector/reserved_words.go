@@ -0,0 +1,39 @@
+package postgresqlconnector
+
+import (
+	"strings"
+)
+
+// https://www.postgresql.org/docs/current/sql-keywords-appendix.html
+// key words marked as reserved (including those could be function or type) for PostgreSQL
+var reservedWords = toSet(`
+ALL ANALYSE ANALYZE AND ANY ARRAY AS ASC ASYMMETRIC AUTHORIZATION
+BINARY BOTH
+CASE CAST CHECK COLLATE COLLATION COLUMN CONCURRENTLY CONSTRAINT CREATE CROSS
+CURRENT_CATALOG CURRENT_DATE CURRENT_ROLE CURRENT_SCHEMA CURRENT_TIME CURRENT_TIMESTAMP CURRENT_USER
+DEFAULT DEFERRABLE DESC DISTINCT DO
+ELSE END EXCEPT
+FALSE FETCH FOR FOREIGN FREEZE FROM FULL
+GRANT GROUP
+HAVING
+ILIKE IN INITIALLY INNER INTERSECT INTO IS ISNULL
+JOIN
+LATERAL LEADING LEFT LIKE LIMIT LOCALTIME LOCALTIMESTAMP
+NATURAL NOT NOTNULL NULL
+OFFSET ON ONLY OR ORDER OUTER OVERLAPS
+PLACING PRIMARY
+REFERENCES RETURNING RIGHT
+SELECT SESSION_USER SIMILAR SOME SYMMETRIC SYSTEM_USER
+TABLE TABLESAMPLE THEN TO TRAILING TRUE
+UNION UNIQUE USER USING
+VARIADIC VERBOSE
+WHEN WHERE WINDOW WITH
+`)
+
+func toSet(words string) map[string]bool {
+	set := map[string]bool{}
+	for _, w := range strings.Fields(words) {
+		set[strings.ToLower(w)] = true
+	}
+	return set
+}
@@ -0,0 +1,58 @@
+package postgresqlconnector
+
+import (
+	"time"
+
+	"github.com/go-courier/sqlx/v2"
+
+	"github.com/go-courier/logr"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// retryBackoff is the delay before the first retry, doubled for each next
+var retryBackoff = 50 * time.Millisecond
+
+// retryableErrorCodes are SQLSTATE codes of failures safe to retry
+var retryableErrorCodes = map[pq.ErrorCode]bool{
+	// serialization_failure
+	"40001": true,
+	// deadlock_detected
+	"40P01": true,
+}
+
+func isRetryable(err error) bool {
+	if pgErr, ok := sqlx.UnwrapAll(err).(*pq.Error); ok {
+		return retryableErrorCodes[pgErr.Code]
+	}
+	return false
+}
+
+// retry calls fn, and calls it again with exponential backoff at most maxRetries times
+// when failed by serialization failure or deadlock.
+// Statements in explicit transaction are never retried, for the transaction is aborted already.
+// The error of the last call is returned as it is.
+func (c *loggerConn) retry(logger logr.Logger, done <-chan struct{}, fn func() error) error {
+	if c.maxRetries <= 0 || c.tx != nil {
+		return fn()
+	}
+
+	backoff := retryBackoff
+
+	for i := 0; ; i++ {
+		err := fn()
+		if err == nil || i >= c.maxRetries || !isRetryable(err) {
+			return err
+		}
+
+		logger.Warn(errors.Wrapf(err, "retry %d/%d after %s", i+1, c.maxRetries, backoff))
+
+		select {
+		case <-done:
+			return err
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+}
@@ -0,0 +1,89 @@
+package postgresqlconnector
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/onsi/gomega"
+)
+
+type flakyConn struct {
+	fakeConn
+	errs []error
+}
+
+func (c *flakyConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	*c.queries = append(*c.queries, query)
+	if len(c.errs) > 0 {
+		err := c.errs[0]
+		c.errs = c.errs[1:]
+		return nil, err
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func TestLoggerConn_Retry(t *testing.T) {
+	retryBackoff = time.Millisecond
+
+	serializationFailure := &pq.Error{Code: "40001"}
+
+	t.Run("retried", func(t *testing.T) {
+		queries := make([]string, 0)
+		c := &loggerConn{
+			Conn:       &flakyConn{fakeConn: fakeConn{queries: &queries}, errs: []error{serializationFailure, &pq.Error{Code: "40P01"}}},
+			maxRetries: 3,
+		}
+
+		_, err := c.ExecContext(context.Background(), "UPDATE t SET f_a = 1", nil)
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(queries).To(gomega.HaveLen(3))
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		queries := make([]string, 0)
+		c := &loggerConn{
+			Conn:       &flakyConn{fakeConn: fakeConn{queries: &queries}, errs: []error{serializationFailure, serializationFailure, serializationFailure}},
+			maxRetries: 2,
+		}
+
+		_, err := c.ExecContext(context.Background(), "UPDATE t SET f_a = 1", nil)
+		gomega.NewWithT(t).Expect(err).To(gomega.Equal(serializationFailure))
+		gomega.NewWithT(t).Expect(queries).To(gomega.HaveLen(3))
+	})
+
+	t.Run("not retryable", func(t *testing.T) {
+		queries := make([]string, 0)
+		uniqueViolation := &pq.Error{Code: "23505"}
+		c := &loggerConn{
+			Conn:       &flakyConn{fakeConn: fakeConn{queries: &queries}, errs: []error{uniqueViolation}},
+			maxRetries: 3,
+		}
+
+		_, err := c.ExecContext(context.Background(), "INSERT INTO t (f_a) VALUES (1)", nil)
+		gomega.NewWithT(t).Expect(err).To(gomega.Equal(uniqueViolation))
+		gomega.NewWithT(t).Expect(queries).To(gomega.HaveLen(1))
+	})
+
+	t.Run("in transaction", func(t *testing.T) {
+		queries := make([]string, 0)
+		c := &loggerConn{
+			Conn:       &flakyConn{fakeConn: fakeConn{queries: &queries}, errs: []error{serializationFailure}},
+			maxRetries: 3,
+		}
+		c.tx = &loggingTx{conn: c}
+
+		_, err := c.ExecContext(context.Background(), "UPDATE t SET f_a = 1", nil)
+		gomega.NewWithT(t).Expect(err).To(gomega.Equal(serializationFailure))
+		gomega.NewWithT(t).Expect(queries).To(gomega.HaveLen(1))
+	})
+}
+
+func TestPostgreSQLLoggingDriver_InvalidMaxRetries(t *testing.T) {
+	d := &PostgreSQLLoggingDriver{}
+
+	_, err := d.Open("postgres://root@localhost:5432/db?max_retries=-1")
+	gomega.NewWithT(t).Expect(err).To(gomega.MatchError(gomega.ContainSubstring("invalid max_retries")))
+}
@@ -0,0 +1,97 @@
+package postgresqlconnector
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+var ErrNotInTx = errors.New("conn is not in transaction")
+
+// NewSavepointer creates Savepointer of conn, which should be opened by PostgreSQLLoggingDriver.
+// database/sql doesn't expose savepoints, so transaction should be began by conn.BeginTx,
+// then nested transactions could be began and rolled back independently by savepoints.
+func NewSavepointer(conn *sql.Conn) *Savepointer {
+	return &Savepointer{conn: conn}
+}
+
+type Savepointer struct {
+	conn *sql.Conn
+}
+
+// Savepoint emits SAVEPOINT sp_n, n is the nesting depth, and returns the name of savepoint
+func (s *Savepointer) Savepoint(ctx context.Context) (name string, err error) {
+	err = s.withTx(func(tx *loggingTx) error {
+		name = fmt.Sprintf("sp_%d", len(tx.savepoints)+1)
+		if err := tx.exec(ctx, "SAVEPOINT "+name, "Savepoint "+name); err != nil {
+			return err
+		}
+		tx.savepoints = append(tx.savepoints, name)
+		return nil
+	})
+	return
+}
+
+// Release emits RELEASE SAVEPOINT, the savepoint and savepoints after it are destroyed
+func (s *Savepointer) Release(ctx context.Context, name string) error {
+	return s.withTx(func(tx *loggingTx) error {
+		i, err := tx.savepointIndex(name)
+		if err != nil {
+			return err
+		}
+		if err := tx.exec(ctx, "RELEASE SAVEPOINT "+name, "Released Savepoint "+name); err != nil {
+			return err
+		}
+		tx.savepoints = tx.savepoints[0:i]
+		return nil
+	})
+}
+
+// RollbackTo emits ROLLBACK TO SAVEPOINT, the savepoint is kept, but savepoints after it are destroyed
+func (s *Savepointer) RollbackTo(ctx context.Context, name string) error {
+	return s.withTx(func(tx *loggingTx) error {
+		i, err := tx.savepointIndex(name)
+		if err != nil {
+			return err
+		}
+		if err := tx.exec(ctx, "ROLLBACK TO SAVEPOINT "+name, "Rollback To Savepoint "+name); err != nil {
+			return err
+		}
+		tx.savepoints = tx.savepoints[0 : i+1]
+		return nil
+	})
+}
+
+func (s *Savepointer) withTx(fn func(tx *loggingTx) error) error {
+	return s.conn.Raw(func(driverConn interface{}) error {
+		c, ok := driverConn.(*loggerConn)
+		if !ok {
+			return errors.Errorf("conn %T is not opened by PostgreSQLLoggingDriver", driverConn)
+		}
+		if c.tx == nil {
+			return ErrNotInTx
+		}
+		return fn(c.tx)
+	})
+}
+
+func (tx *loggingTx) savepointIndex(name string) (int, error) {
+	for i := range tx.savepoints {
+		if tx.savepoints[i] == name {
+			return i, nil
+		}
+	}
+	return -1, errors.Errorf("unknown savepoint %s", name)
+}
+
+func (tx *loggingTx) exec(ctx context.Context, query string, title string) error {
+	if _, err := tx.conn.Conn.(driver.ExecerContext).ExecContext(ctx, query, nil); err != nil {
+		tx.logger.Debug("failed to exec %s: %s", query, err)
+		return err
+	}
+	tx.logger.Debug("=========== %s ===========", title)
+	return nil
+}
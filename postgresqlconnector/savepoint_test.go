@@ -0,0 +1,114 @@
+package postgresqlconnector
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+func TestSavepointer(t *testing.T) {
+	queries := make([]string, 0)
+
+	db := sql.OpenDB(&fakeConnector{queries: &queries})
+	defer db.Close()
+
+	ctx := context.Background()
+
+	conn, err := db.Conn(ctx)
+	gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+	defer conn.Close()
+
+	sp := NewSavepointer(conn)
+
+	t.Run("not in tx", func(t *testing.T) {
+		_, err := sp.Savepoint(ctx)
+		gomega.NewWithT(t).Expect(err).To(gomega.Equal(ErrNotInTx))
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		queries = queries[0:0]
+
+		tx, err := conn.BeginTx(ctx, nil)
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+
+		sp1, _ := sp.Savepoint(ctx)
+		sp2, _ := sp.Savepoint(ctx)
+		gomega.NewWithT(t).Expect(sp.RollbackTo(ctx, sp2)).To(gomega.BeNil())
+		sp3, _ := sp.Savepoint(ctx)
+		gomega.NewWithT(t).Expect(sp.Release(ctx, sp1)).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(sp.Release(ctx, sp3)).NotTo(gomega.BeNil())
+		sp4, _ := sp.Savepoint(ctx)
+
+		gomega.NewWithT(t).Expect(tx.Commit()).To(gomega.BeNil())
+
+		gomega.NewWithT(t).Expect([]string{sp1, sp2, sp3, sp4}).To(gomega.Equal([]string{"sp_1", "sp_2", "sp_3", "sp_1"}))
+		gomega.NewWithT(t).Expect(queries).To(gomega.Equal([]string{
+			"BEGIN",
+			"SAVEPOINT sp_1",
+			"SAVEPOINT sp_2",
+			"ROLLBACK TO SAVEPOINT sp_2",
+			"SAVEPOINT sp_3",
+			"RELEASE SAVEPOINT sp_1",
+			"SAVEPOINT sp_1",
+			"COMMIT",
+		}))
+
+		_, err = sp.Savepoint(ctx)
+		gomega.NewWithT(t).Expect(err).To(gomega.Equal(ErrNotInTx))
+	})
+}
+
+type fakeConnector struct {
+	queries *[]string
+}
+
+func (c *fakeConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &loggerConn{Conn: &fakeConn{queries: c.queries}}, nil
+}
+
+func (c *fakeConnector) Driver() driver.Driver {
+	return &PostgreSQLLoggingDriver{}
+}
+
+type fakeConn struct {
+	queries *[]string
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, driver.ErrSkip
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *fakeConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	*c.queries = append(*c.queries, "BEGIN")
+	return &fakeTx{queries: c.queries}, nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	*c.queries = append(*c.queries, query)
+	return driver.RowsAffected(0), nil
+}
+
+type fakeTx struct {
+	queries *[]string
+}
+
+func (tx *fakeTx) Commit() error {
+	*tx.queries = append(*tx.queries, "COMMIT")
+	return nil
+}
+
+func (tx *fakeTx) Rollback() error {
+	*tx.queries = append(*tx.queries, "ROLLBACK")
+	return nil
+}
@@ -22,7 +22,8 @@ func dbFromInformationSchema(db sqlx.DBExecutor) (*sqlx.Database, error) {
 
 	dbName := d.Name
 	dbSchema := d.Schema
-	tableNames := d.Tables.TableNames()
+	// history tables are migrated along with tables
+	tableNames := d.Tables.WithHistoryTables().TableNamesWithRenameFrom()
 
 	d = sqlx.NewDatabase(dbName).WithSchema(dbSchema)
 
@@ -60,6 +61,32 @@ func dbFromInformationSchema(db sqlx.DBExecutor) (*sqlx.Database, error) {
 		table.AddCol(colFromColumnSchema(&columnSchema))
 	}
 
+	columnCommentList := make([]ColumnComment, 0)
+
+	err = db.QueryExprAndScan(
+		builder.Expr(`SELECT c.relname AS table_name, COALESCE(a.attname, '') AS column_name, d.description AS comment
+FROM pg_catalog.pg_description d
+JOIN pg_catalog.pg_class c ON c.oid = d.objoid AND d.classoid = 'pg_catalog.pg_class'::regclass
+JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+LEFT JOIN pg_catalog.pg_attribute a ON a.attrelid = c.oid AND a.attnum = d.objsubid AND d.objsubid > 0
+WHERE n.nspname = ?`, tableSchema),
+		&columnCommentList,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, columnComment := range columnCommentList {
+		if table := d.Table(columnComment.TABLE_NAME); table != nil {
+			// comment of table has no column name
+			if columnComment.COLUMN_NAME == "" {
+				table.Description = strings.Split(columnComment.COMMENT, "\n")
+			} else if col := table.Col(columnComment.COLUMN_NAME); col != nil {
+				col.Description = strings.Split(columnComment.COMMENT, "\n")
+			}
+		}
+	}
+
 	if tableColumnSchema.Columns.Len() != 0 {
 		tableIndexSchema := SchemaDatabase.T(&IndexSchema{})
 
@@ -86,23 +113,175 @@ func dbFromInformationSchema(db sqlx.DBExecutor) (*sqlx.Database, error) {
 		for _, indexSchema := range indexList {
 			table := d.Table(indexSchema.TABLE_NAME)
 
-			key := &builder.Key{}
-			key.Name = indexSchema.INDEX_NAME[len(table.Name)+1:]
-			key.Method = strings.ToUpper(regexp.MustCompile(`USING ([^ ]+)`).FindString(indexSchema.INDEX_DEF)[6:])
-			key.IsUnique = strings.Contains(indexSchema.INDEX_DEF, "UNIQUE")
-
-			fields := regexp.MustCompile(`\([^\)]+\)`).FindString(indexSchema.INDEX_DEF)
-			if len(fields) > 0 {
-				fields = fields[1 : len(fields)-1]
+			key, err := keyFromIndexDef(table, indexSchema.INDEX_NAME, indexSchema.INDEX_DEF)
+			if err != nil {
+				return nil, err
 			}
-			key.Columns, _ = table.Cols(strings.Split(fields, ", ")...)
 			table.AddKey(key)
 		}
 	}
 
+	foreignKeyList := make([]ForeignKeySchema, 0)
+
+	err = db.QueryExprAndScan(
+		builder.Expr(`SELECT t.relname AS table_name, con.conname AS constraint_name, a.attname AS column_name,
+rt.relname AS referenced_table_name, ra.attname AS referenced_column_name,
+con.confupdtype::text AS update_rule, con.confdeltype::text AS delete_rule
+FROM pg_catalog.pg_constraint con
+JOIN pg_catalog.pg_class t ON t.oid = con.conrelid
+JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+JOIN pg_catalog.pg_class rt ON rt.oid = con.confrelid
+CROSS JOIN LATERAL unnest(con.conkey, con.confkey) WITH ORDINALITY AS k(attnum, refattnum, ord)
+JOIN pg_catalog.pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+JOIN pg_catalog.pg_attribute ra ON ra.attrelid = con.confrelid AND ra.attnum = k.refattnum
+WHERE con.contype = 'f' AND n.nspname = ?
+ORDER BY t.relname, con.conname, k.ord`, tableSchema),
+		&foreignKeyList,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := addForeignKeys(d, foreignKeyList); err != nil {
+		return nil, err
+	}
+
+	constraintList := make([]ConstraintSchema, 0)
+
+	err = db.QueryExprAndScan(
+		builder.Expr(`SELECT t.relname AS table_name, con.conname AS constraint_name, pg_get_constraintdef(con.oid) AS check_clause
+FROM pg_catalog.pg_constraint con
+JOIN pg_catalog.pg_class t ON t.oid = con.conrelid
+JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+WHERE con.contype = 'c' AND n.nspname = ?`, tableSchema),
+		&constraintList,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	addConstraints(d, constraintList)
+
+	storageParamsList := make([]StorageParamsSchema, 0)
+
+	err = db.QueryExprAndScan(
+		builder.Expr(`SELECT COALESCE(t.relname, c.relname) AS table_name, CASE WHEN t.relname IS NULL THEN '' ELSE c.relname END AS index_name, array_to_string(c.reloptions, ',') AS options
+FROM pg_catalog.pg_class c
+JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+LEFT JOIN pg_catalog.pg_index i ON i.indexrelid = c.oid
+LEFT JOIN pg_catalog.pg_class t ON t.oid = i.indrelid
+WHERE n.nspname = ? AND c.reloptions IS NOT NULL`, tableSchema),
+		&storageParamsList,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, storageParams := range storageParamsList {
+		table := d.Table(storageParams.TABLE_NAME)
+		if table == nil {
+			continue
+		}
+
+		params := map[string]string{}
+		for _, option := range strings.Split(storageParams.OPTIONS, ",") {
+			if i := strings.Index(option, "="); i > 0 {
+				params[option[:i]] = option[i+1:]
+			}
+		}
+
+		if storageParams.INDEX_NAME == "" {
+			table.StorageParams = params
+		} else if key := table.Key(strings.TrimPrefix(storageParams.INDEX_NAME, table.Name+"_")); key != nil {
+			key.StorageParams = params
+		}
+	}
+
+	if db.D().EnumTypes.Len() != 0 {
+		enumValueList := make([]EnumValueSchema, 0)
+
+		err = db.QueryExprAndScan(
+			builder.Expr(`SELECT t.typname AS type_name, e.enumlabel AS value
+FROM pg_catalog.pg_type t
+JOIN pg_catalog.pg_enum e ON e.enumtypid = t.oid
+JOIN pg_catalog.pg_namespace n ON n.oid = t.typnamespace
+WHERE n.nspname = ?
+ORDER BY t.typname, e.enumsortorder`, tableSchema),
+			&enumValueList,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, enumValue := range enumValueList {
+			enumType := d.EnumTypes.EnumType(enumValue.TYPE_NAME)
+			if enumType == nil {
+				enumType = builder.Enum(enumValue.TYPE_NAME)
+				enumType.Schema = d.Schema
+				d.EnumTypes.Add(enumType)
+			}
+			enumType.Values = append(enumType.Values, enumValue.VALUE)
+		}
+	}
+
 	return d, nil
 }
 
+// foreignKeyActions maps confupdtype and confdeltype of pg_constraint to actions
+var foreignKeyActions = map[string]string{
+	"a": builder.ForeignKeyActionNoAction,
+	"r": builder.ForeignKeyActionRestrict,
+	"c": builder.ForeignKeyActionCascade,
+	"n": builder.ForeignKeyActionSetNull,
+	"d": builder.ForeignKeyActionSetDefault,
+}
+
+// addForeignKeys adds foreign keys to loaded tables, rows of each foreign key should be ordered by positions of columns
+func addForeignKeys(d *sqlx.Database, foreignKeyList []ForeignKeySchema) error {
+	for _, foreignKeySchema := range foreignKeyList {
+		table := d.Table(foreignKeySchema.TABLE_NAME)
+		if table == nil {
+			continue
+		}
+
+		col := table.Col(foreignKeySchema.COLUMN_NAME)
+		if col == nil {
+			return fmt.Errorf("invalid columns of foreign key %s: unknown column %s", foreignKeySchema.CONSTRAINT_NAME, foreignKeySchema.COLUMN_NAME)
+		}
+
+		name := strings.TrimPrefix(foreignKeySchema.CONSTRAINT_NAME, table.Name+"_")
+
+		if fk := table.ForeignKey(name); fk != nil {
+			fk.Columns.Add(col)
+			fk.RefColumns.Add(builder.Col(foreignKeySchema.REFERENCED_COLUMN_NAME))
+			continue
+		}
+
+		cols := &builder.Columns{}
+		cols.Add(col)
+
+		table.AddForeignKey(
+			builder.FK(name, cols).
+				References(builder.T(foreignKeySchema.REFERENCED_TABLE_NAME), builder.Cols(foreignKeySchema.REFERENCED_COLUMN_NAME)).
+				OnDeleteAction(foreignKeyActions[foreignKeySchema.DELETE_RULE]).
+				OnUpdateAction(foreignKeyActions[foreignKeySchema.UPDATE_RULE]),
+		)
+	}
+	return nil
+}
+
+// addConstraints adds check constraints to loaded tables,
+// definition from pg_get_constraintdef is like CHECK ((f_age >= 0)) NOT VALID, expression is compared after normalized by builder
+func addConstraints(d *sqlx.Database, constraintList []ConstraintSchema) {
+	for _, constraintSchema := range constraintList {
+		if table := d.Table(constraintSchema.TABLE_NAME); table != nil {
+			name := strings.TrimPrefix(constraintSchema.CONSTRAINT_NAME, table.Name+"_")
+			expr := strings.TrimSuffix(strings.TrimPrefix(constraintSchema.CHECK_CLAUSE, "CHECK "), " NOT VALID")
+			table.AddConstraint(builder.Check(name, builder.Expr(expr)))
+		}
+	}
+}
+
 var SchemaDatabase = sqlx.NewDatabase("INFORMATION_SCHEMA")
 
 func init() {
@@ -152,6 +331,13 @@ func colFromColumnSchema(columnSchema *ColumnSchema) *builder.Column {
 		col.Null = true
 	}
 
+	col.Collation = columnSchema.COLLATION_NAME
+
+	if columnSchema.IS_GENERATED == "ALWAYS" {
+		col.GeneratedExpr = columnSchema.GENERATION_EXPRESSION
+		col.GeneratedStorage = builder.GeneratedStorageStored
+	}
+
 	return col
 }
 
@@ -165,12 +351,177 @@ type ColumnSchema struct {
 	CHARACTER_MAXIMUM_LENGTH uint64 `db:"character_maximum_length"`
 	NUMERIC_PRECISION        uint64 `db:"numeric_precision"`
 	NUMERIC_SCALE            uint64 `db:"numeric_scale"`
+	IS_GENERATED             string `db:"is_generated"`
+	GENERATION_EXPRESSION    string `db:"generation_expression"`
+	COLLATION_NAME           string `db:"collation_name"`
 }
 
 func (ColumnSchema) TableName() string {
 	return "columns"
 }
 
+type ColumnComment struct {
+	TABLE_NAME  string `db:"table_name"`
+	COLUMN_NAME string `db:"column_name"`
+	COMMENT     string `db:"comment"`
+}
+
+type ForeignKeySchema struct {
+	TABLE_NAME             string `db:"table_name"`
+	CONSTRAINT_NAME        string `db:"constraint_name"`
+	COLUMN_NAME            string `db:"column_name"`
+	REFERENCED_TABLE_NAME  string `db:"referenced_table_name"`
+	REFERENCED_COLUMN_NAME string `db:"referenced_column_name"`
+	UPDATE_RULE            string `db:"update_rule"`
+	DELETE_RULE            string `db:"delete_rule"`
+}
+
+type ConstraintSchema struct {
+	TABLE_NAME      string `db:"table_name"`
+	CONSTRAINT_NAME string `db:"constraint_name"`
+	CHECK_CLAUSE    string `db:"check_clause"`
+}
+
+type StorageParamsSchema struct {
+	TABLE_NAME string `db:"table_name"`
+	INDEX_NAME string `db:"index_name"`
+	OPTIONS    string `db:"options"`
+}
+
+type EnumValueSchema struct {
+	TYPE_NAME string `db:"type_name"`
+	VALUE     string `db:"value"`
+}
+
+// keyFromIndexDef parses key of table from indexdef of pg_indexes,
+// like `CREATE INDEX t_i_a ON public.t USING btree ("order" DESC NULLS LAST, lower(f_name)) WHERE ...`,
+// quoted names of columns are unquoted, and members other than columns are parsed as Exprs.
+func keyFromIndexDef(table *builder.Table, indexName string, indexDef string) (*builder.Key, error) {
+	key := &builder.Key{}
+	key.Name = indexName[len(table.Name)+1:]
+	key.Method = strings.ToUpper(regexp.MustCompile(`USING ([^ ]+)`).FindString(indexDef)[6:])
+	key.IsUnique = strings.Contains(indexDef, "UNIQUE")
+
+	colNames := make([]string, 0)
+
+	for _, member := range indexMembers(indexDef) {
+		name, order, ok := splitIndexColumn(member)
+		if !ok {
+			key.Exprs = append(key.Exprs, member)
+			continue
+		}
+		colNames = append(colNames, name)
+		// column may be followed by order like `f_a DESC NULLS LAST`
+		if order != "" {
+			if key.Orders == nil {
+				key.Orders = map[string]builder.KeyColumnOrder{}
+			}
+			key.Orders[name] = builder.ParseKeyColumnOrder(order)
+		}
+	}
+
+	cols, err := table.Cols(colNames...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid columns of index %s: %s", indexName, err)
+	}
+	key.Columns = cols
+
+	if i := strings.Index(indexDef, " WHERE "); i > -1 {
+		key.Where = indexDef[i+len(" WHERE "):]
+	}
+
+	return key, nil
+}
+
+// indexMembers returns members in parentheses after USING method, split by top level commas
+func indexMembers(indexDef string) (members []string) {
+	i := strings.Index(indexDef, " USING ")
+	if i < 0 {
+		return nil
+	}
+	start := strings.IndexByte(indexDef[i:], '(')
+	if start < 0 {
+		return nil
+	}
+	start += i
+
+	depth := 0
+	quoted := false
+	from := start + 1
+
+	for j := start; j < len(indexDef); j++ {
+		switch c := indexDef[j]; {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return append(members, strings.TrimSpace(indexDef[from:j]))
+			}
+		case c == ',' && depth == 1:
+			members = append(members, strings.TrimSpace(indexDef[from:j]))
+			from = j + 1
+		}
+	}
+
+	return members
+}
+
+// splitIndexColumn splits member of index into unquoted name of column and its order,
+// ok is false when member is expression
+func splitIndexColumn(member string) (name string, order string, ok bool) {
+	rest := ""
+
+	if strings.HasPrefix(member, `"`) {
+		end := 1
+		for ; end < len(member); end++ {
+			if member[end] == '"' {
+				if end+1 < len(member) && member[end+1] == '"' {
+					end++
+					continue
+				}
+				break
+			}
+		}
+		if end >= len(member) {
+			return "", "", false
+		}
+		name = strings.Replace(member[1:end], `""`, `"`, -1)
+		rest = member[end+1:]
+	} else {
+		end := 0
+		for ; end < len(member); end++ {
+			c := member[end]
+			if !(c == '_' || c == '$' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9') {
+				break
+			}
+		}
+		if end == 0 {
+			return "", "", false
+		}
+		name = member[:end]
+		rest = member[end:]
+	}
+
+	if rest != "" && rest[0] != ' ' {
+		return "", "", false
+	}
+
+	order = strings.TrimSpace(rest)
+	for _, word := range strings.Fields(strings.ToUpper(order)) {
+		switch word {
+		case "ASC", "DESC", "NULLS", "FIRST", "LAST":
+		default:
+			return "", "", false
+		}
+	}
+
+	return name, order, true
+}
+
 type IndexSchema struct {
 	TABLE_SCHEMA string `db:"schemaname"`
 	TABLE_NAME   string `db:"tablename"`
@@ -0,0 +1,204 @@
+package postgresqlconnector
+
+import (
+	"container/list"
+	"context"
+	"database/sql/driver"
+
+	"github.com/go-courier/sqlx/v2"
+
+	"github.com/go-courier/logr"
+	"github.com/lib/pq"
+)
+
+func newStmtCache(size int) *stmtCache {
+	return &stmtCache{
+		size: size,
+		l:    list.New(),
+		m:    map[string]*list.Element{},
+	}
+}
+
+// stmtCache caches prepared statements of one conn by query,
+// the least recently used statement is evicted and closed when full.
+type stmtCache struct {
+	size int
+	l    *list.List
+	m    map[string]*list.Element
+	// cancelable tells whether statements of the driver could be canceled by context,
+	// learned from the first prepared statement, probed is false before learned.
+	probed     bool
+	cancelable bool
+}
+
+type cachedStmt struct {
+	query string
+	stmt  driver.Stmt
+}
+
+func (c *stmtCache) Len() int {
+	return c.l.Len()
+}
+
+func (c *stmtCache) get(query string) driver.Stmt {
+	if e, ok := c.m[query]; ok {
+		c.l.MoveToFront(e)
+		return e.Value.(*cachedStmt).stmt
+	}
+	return nil
+}
+
+// put caches stmt of query, and returns the evicted statement which should be closed
+func (c *stmtCache) put(query string, stmt driver.Stmt) (evicted driver.Stmt) {
+	c.m[query] = c.l.PushFront(&cachedStmt{query: query, stmt: stmt})
+
+	if c.l.Len() > c.size {
+		last := c.l.Back()
+		c.l.Remove(last)
+		cached := last.Value.(*cachedStmt)
+		delete(c.m, cached.query)
+		return cached.stmt
+	}
+
+	return nil
+}
+
+// remove drops cached stmt of query, and returns it which should be closed
+func (c *stmtCache) remove(query string) driver.Stmt {
+	if e, ok := c.m[query]; ok {
+		c.l.Remove(e)
+		delete(c.m, query)
+		return e.Value.(*cachedStmt).stmt
+	}
+	return nil
+}
+
+func (c *stmtCache) closeAll() (err error) {
+	for e := c.l.Front(); e != nil; e = e.Next() {
+		if e := e.Value.(*cachedStmt).stmt.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	c.l.Init()
+	c.m = map[string]*list.Element{}
+	return
+}
+
+// preparedStmt returns the cached prepared statement of query, which is prepared and cached when missed.
+func (c *loggerConn) preparedStmt(logger logr.Logger, query string) (driver.Stmt, error) {
+	if stmt := c.stmtCache.get(query); stmt != nil {
+		logger.Debug("prepared statement cache hit")
+		return stmt, nil
+	}
+
+	logger.Debug("prepared statement cache miss")
+
+	stmt, err := c.Conn.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if !c.stmtCache.probed {
+		_, execer := stmt.(driver.StmtExecContext)
+		_, queryer := stmt.(driver.StmtQueryContext)
+		c.stmtCache.probed, c.stmtCache.cancelable = true, execer && queryer
+	}
+
+	if evicted := c.stmtCache.put(query, stmt); evicted != nil {
+		if err := evicted.Close(); err != nil {
+			logger.Warn(err)
+		}
+	}
+
+	return stmt, nil
+}
+
+// useStmtCache returns true when query with args should be executed by cached prepared statement,
+// queries without args are sent as they are, for they may be multiple statements which could not be prepared.
+func (c *loggerConn) useStmtCache(args []driver.NamedValue) bool {
+	return c.stmtCache != nil && len(args) > 0
+}
+
+// stmtFor returns the cached prepared statement to run query,
+// nil when statements of the driver could not be canceled and ctx could be done,
+// then query should be sent without the statement cache, for canceling ctx should stop it.
+// The capability is checked before preparing, so only the first statement is prepared to learn it.
+func (c *loggerConn) stmtFor(ctx context.Context, logger logr.Logger, query string) (driver.Stmt, error) {
+	if ctx.Done() != nil && c.stmtCache.probed && !c.stmtCache.cancelable {
+		return nil, nil
+	}
+	stmt, err := c.preparedStmt(logger, query)
+	if err != nil {
+		return nil, err
+	}
+	if ctx.Done() != nil && !c.stmtCache.cancelable {
+		return nil, nil
+	}
+	return stmt, nil
+}
+
+// invalidStmtErrorCodes are SQLSTATE codes of failures which invalidate the prepared statement
+var invalidStmtErrorCodes = map[pq.ErrorCode]bool{
+	// feature_not_supported, like `cached plan must not change result type` after schema changed
+	"0A000": true,
+	// invalid_sql_statement_name, like `prepared statement does not exist` after DISCARD ALL
+	"26000": true,
+}
+
+func isStmtInvalidated(err error) bool {
+	if err == driver.ErrBadConn {
+		return true
+	}
+	if pgErr, ok := sqlx.UnwrapAll(err).(*pq.Error); ok {
+		return invalidStmtErrorCodes[pgErr.Code]
+	}
+	return false
+}
+
+// evictStmt drops and closes the cached statement of query when run failed by invalidating errors,
+// so the statement is prepared again next time.
+// Statements failed by others, like unique violation (23505), are kept as they are still valid.
+func (c *loggerConn) evictStmt(logger logr.Logger, query string, err error) {
+	if !isStmtInvalidated(err) {
+		return
+	}
+	if stmt := c.stmtCache.remove(query); stmt != nil {
+		if err := stmt.Close(); err != nil {
+			logger.Warn(err)
+		}
+	}
+}
+
+func (c *loggerConn) execStmt(ctx context.Context, logger logr.Logger, stmt driver.Stmt, query string, args []driver.NamedValue) (result driver.Result, err error) {
+	defer func() {
+		if err != nil {
+			c.evictStmt(logger, query, err)
+		}
+	}()
+
+	if execer, ok := stmt.(driver.StmtExecContext); ok {
+		return execer.ExecContext(ctx, args)
+	}
+	values, err := namedValueToValue(args)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.Exec(values)
+}
+
+func (c *loggerConn) queryStmt(ctx context.Context, logger logr.Logger, stmt driver.Stmt, query string, args []driver.NamedValue) (rows driver.Rows, err error) {
+	defer func() {
+		if err != nil {
+			c.evictStmt(logger, query, err)
+		}
+	}()
+
+	if queryer, ok := stmt.(driver.StmtQueryContext); ok {
+		return queryer.QueryContext(ctx, args)
+	}
+	values, err := namedValueToValue(args)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.Query(values)
+}
@@ -0,0 +1,205 @@
+package postgresqlconnector
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/onsi/gomega"
+)
+
+type preparingConn struct {
+	fakeConn
+	prepared []string
+	closed   *[]string
+	// stmtErr fails statements prepared
+	stmtErr error
+	// withContext prepares statements with context variants
+	withContext bool
+	prepareErr  error
+}
+
+func (c *preparingConn) Prepare(query string) (driver.Stmt, error) {
+	if c.prepareErr != nil {
+		return nil, c.prepareErr
+	}
+	c.prepared = append(c.prepared, query)
+	stmt := &preparedStmt{query: query, queries: c.queries, closed: c.closed, err: c.stmtErr}
+	if c.withContext {
+		return &contextStmt{preparedStmt: stmt}, nil
+	}
+	return stmt, nil
+}
+
+type preparedStmt struct {
+	query   string
+	queries *[]string
+	closed  *[]string
+	err     error
+}
+
+func (s *preparedStmt) Close() error {
+	*s.closed = append(*s.closed, s.query)
+	return nil
+}
+
+func (s *preparedStmt) NumInput() int {
+	return -1
+}
+
+func (s *preparedStmt) Exec(args []driver.Value) (driver.Result, error) {
+	*s.queries = append(*s.queries, s.query)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *preparedStmt) Query(args []driver.Value) (driver.Rows, error) {
+	*s.queries = append(*s.queries, s.query)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &fakeRows{n: 1}, nil
+}
+
+func TestLoggerConn_StmtCache(t *testing.T) {
+	queries := make([]string, 0)
+	closed := make([]string, 0)
+
+	conn := &preparingConn{fakeConn: fakeConn{queries: &queries}, closed: &closed}
+	c := &loggerConn{Conn: conn, stmtCache: newStmtCache(2)}
+
+	args := []driver.NamedValue{{Ordinal: 1, Value: int64(1)}}
+
+	for _, query := range []string{
+		"UPDATE t SET f_a = 1 WHERE f_id = ?",
+		"UPDATE t SET f_a = 1 WHERE f_id = ?",
+		"UPDATE t SET f_b = 1 WHERE f_id = ?",
+		"UPDATE t SET f_a = 1 WHERE f_id = ?",
+		"UPDATE t SET f_c = 1 WHERE f_id = ?",
+	} {
+		_, err := c.ExecContext(context.Background(), query, args)
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+	}
+
+	_, err := c.ExecContext(context.Background(), "SET timezone = 'UTC'", nil)
+	gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+
+	gomega.NewWithT(t).Expect(queries).To(gomega.HaveLen(6))
+	gomega.NewWithT(t).Expect(conn.prepared).To(gomega.Equal([]string{
+		"UPDATE t SET f_a = 1 WHERE f_id = $1",
+		"UPDATE t SET f_b = 1 WHERE f_id = $1",
+		"UPDATE t SET f_c = 1 WHERE f_id = $1",
+	}))
+	gomega.NewWithT(t).Expect(closed).To(gomega.Equal([]string{"UPDATE t SET f_b = 1 WHERE f_id = $1"}))
+
+	gomega.NewWithT(t).Expect(c.Close()).To(gomega.BeNil())
+	gomega.NewWithT(t).Expect(closed).To(gomega.HaveLen(3))
+	gomega.NewWithT(t).Expect(c.stmtCache.Len()).To(gomega.Equal(0))
+}
+
+type contextStmt struct {
+	*preparedStmt
+}
+
+func (s *contextStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	*s.queries = append(*s.queries, "ctx: "+s.query)
+	return driver.RowsAffected(1), nil
+}
+
+func (s *contextStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	*s.queries = append(*s.queries, "ctx: "+s.query)
+	return &fakeRows{}, nil
+}
+
+func TestLoggerConn_StmtCacheEvictedOnError(t *testing.T) {
+	queries := make([]string, 0)
+	closed := make([]string, 0)
+
+	conn := &preparingConn{fakeConn: fakeConn{queries: &queries}, closed: &closed, stmtErr: &pq.Error{Code: "0A000", Message: "cached plan must not change result type"}}
+	c := &loggerConn{Conn: conn, stmtCache: newStmtCache(2)}
+
+	args := []driver.NamedValue{{Ordinal: 1, Value: int64(1)}}
+
+	_, err := c.ExecContext(context.Background(), "UPDATE t SET f_a = 1 WHERE f_id = ?", args)
+	gomega.NewWithT(t).Expect(err).NotTo(gomega.BeNil())
+	gomega.NewWithT(t).Expect(closed).To(gomega.Equal([]string{"UPDATE t SET f_a = 1 WHERE f_id = $1"}))
+	gomega.NewWithT(t).Expect(c.stmtCache.Len()).To(gomega.Equal(0))
+
+	conn.stmtErr = nil
+
+	_, err = c.ExecContext(context.Background(), "UPDATE t SET f_a = 1 WHERE f_id = ?", args)
+	gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+	gomega.NewWithT(t).Expect(conn.prepared).To(gomega.HaveLen(2))
+	gomega.NewWithT(t).Expect(c.stmtCache.Len()).To(gomega.Equal(1))
+
+	t.Run("kept when failed by errors not invalidating statements", func(t *testing.T) {
+		c.stmtCache.get("UPDATE t SET f_a = 1 WHERE f_id = $1").(*preparedStmt).err = &pq.Error{Code: "23505", Message: "duplicate key value violates unique constraint"}
+
+		_, err = c.ExecContext(context.Background(), "UPDATE t SET f_a = 1 WHERE f_id = ?", args)
+		gomega.NewWithT(t).Expect(err).NotTo(gomega.BeNil())
+		gomega.NewWithT(t).Expect(conn.prepared).To(gomega.HaveLen(2))
+		gomega.NewWithT(t).Expect(c.stmtCache.Len()).To(gomega.Equal(1))
+	})
+}
+
+func TestLoggerConn_StmtCacheWithCancelableContext(t *testing.T) {
+	args := []driver.NamedValue{{Ordinal: 1, Value: int64(1)}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	t.Run("bypassed when statements could not be canceled", func(t *testing.T) {
+		queries := make([]string, 0)
+
+		conn := &preparingConn{fakeConn: fakeConn{queries: &queries}, closed: &[]string{}}
+		c := &loggerConn{Conn: conn, stmtCache: newStmtCache(2)}
+
+		for _, query := range []string{
+			"UPDATE t SET f_a = 1 WHERE f_id = ?",
+			"UPDATE t SET f_b = 1 WHERE f_id = ?",
+		} {
+			_, err := c.ExecContext(ctx, query, args)
+			gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		}
+
+		gomega.NewWithT(t).Expect(queries).To(gomega.Equal([]string{
+			"UPDATE t SET f_a = 1 WHERE f_id = $1",
+			"UPDATE t SET f_b = 1 WHERE f_id = $1",
+		}))
+		// only the first one is prepared to learn the capability
+		gomega.NewWithT(t).Expect(conn.prepared).To(gomega.Equal([]string{"UPDATE t SET f_a = 1 WHERE f_id = $1"}))
+
+		// cached statements still used without cancelable context
+		_, err := c.ExecContext(context.Background(), "UPDATE t SET f_a = 1 WHERE f_id = ?", args)
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(conn.prepared).To(gomega.HaveLen(1))
+		gomega.NewWithT(t).Expect(queries).To(gomega.HaveLen(3))
+	})
+
+	t.Run("context variants used", func(t *testing.T) {
+		queries := make([]string, 0)
+
+		conn := &preparingConn{fakeConn: fakeConn{queries: &queries}, closed: &[]string{}, withContext: true}
+		c := &loggerConn{Conn: conn, stmtCache: newStmtCache(2)}
+
+		_, err := c.ExecContext(ctx, "UPDATE t SET f_a = 1 WHERE f_id = ?", args)
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+
+		rows, err := c.QueryContext(ctx, "SELECT * FROM t WHERE f_id = ?", args)
+		gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+		gomega.NewWithT(t).Expect(rows.Close()).To(gomega.BeNil())
+
+		gomega.NewWithT(t).Expect(queries).To(gomega.Equal([]string{
+			"ctx: UPDATE t SET f_a = 1 WHERE f_id = $1",
+			"ctx: SELECT * FROM t WHERE f_id = $1",
+		}))
+	})
+}
+
+func TestPostgreSQLLoggingDriver_InvalidStmtCacheSize(t *testing.T) {
+	_, err := (&PostgreSQLLoggingDriver{}).Open("postgres://root@localhost:5432/db?stmt_cache_size=-1")
+	gomega.NewWithT(t).Expect(err).To(gomega.MatchError(gomega.ContainSubstring("invalid stmt_cache_size")))
+}
@@ -0,0 +1,86 @@
+package sqliteconnector
+
+import (
+	"context"
+	"database/sql/driver"
+
+	"github.com/go-courier/sqlx/v2/builder"
+)
+
+var _ interface {
+	driver.ConnPrepareContext
+	driver.ConnBeginTx
+	driver.ExecerContext
+	driver.QueryerContext
+	driver.Pinger
+	driver.SessionResetter
+	driver.Validator
+	driver.NamedValueChecker
+} = (*bindVarsConn)(nil)
+
+// bindVarsConn replaces value holders of queries by SQLiteConnector.BindVar before sending them to conn of the driver,
+// optional interfaces of conn are forwarded, and fall back as database/sql does when not implemented.
+type bindVarsConn struct {
+	driver.Conn
+	bindVar func(i int) string
+}
+
+func (c *bindVarsConn) Prepare(query string) (driver.Stmt, error) {
+	return c.Conn.Prepare(builder.BindVars(query, c.bindVar))
+}
+
+func (c *bindVarsConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, builder.BindVars(query, c.bindVar))
+	}
+	return c.Prepare(query)
+}
+
+func (c *bindVarsConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *bindVarsConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if execer, ok := c.Conn.(driver.ExecerContext); ok {
+		return execer.ExecContext(ctx, builder.BindVars(query, c.bindVar), args)
+	}
+	return nil, driver.ErrSkip
+}
+
+func (c *bindVarsConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if queryer, ok := c.Conn.(driver.QueryerContext); ok {
+		return queryer.QueryContext(ctx, builder.BindVars(query, c.bindVar), args)
+	}
+	return nil, driver.ErrSkip
+}
+
+func (c *bindVarsConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *bindVarsConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *bindVarsConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c *bindVarsConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
@@ -0,0 +1,637 @@
+package sqliteconnector
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-courier/sqlx/v2"
+	"github.com/go-courier/sqlx/v2/builder"
+	"github.com/pkg/errors"
+)
+
+var _ interface {
+	driver.Connector
+	builder.Dialect
+	builder.RebuildDialect
+} = (*SQLiteConnector)(nil)
+
+// SQLiteConnector connects sqlite, mostly for fast local testing of migrations and queries.
+//
+// ALTER TABLE of sqlite is limited, https://www.sqlite.org/lang_altertable.html
+// only renaming table, renaming column, adding nullable (or defaulted) column are supported directly,
+// other changes, like dropping or modifying column, changing primary key, foreign keys or check constraints,
+// are applied by rebuilding the table, see RebuildTable.
+// Comments and ON UPDATE of columns are not supported by sqlite, which are ignored.
+type SQLiteConnector struct {
+	// SQLiteDriver opens conn of sqlite, like &sqlite3.SQLiteDriver{} of github.com/mattn/go-sqlite3,
+	// sqlx doesn't depend on any driver of sqlite
+	SQLiteDriver driver.Driver
+	// DSN of sqlite, like file::memory:?cache=shared
+	DSN string
+	// IfExists guards creating tables by IF NOT EXISTS, indexes are always guarded,
+	// columns are not guarded, which sqlite could not do
+	IfExists bool
+}
+
+func (c *SQLiteConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	if c.SQLiteDriver == nil {
+		return nil, errors.New("missing driver of sqlite")
+	}
+	conn, err := c.SQLiteDriver.Open(c.DSN)
+	if err != nil {
+		return nil, err
+	}
+	return &bindVarsConn{Conn: conn, bindVar: c.BindVar}, nil
+}
+
+func (c SQLiteConnector) Driver() driver.Driver {
+	return c.SQLiteDriver
+}
+
+func (SQLiteConnector) DriverName() string {
+	return "sqlite"
+}
+
+// Quote quotes ident by double quotes when NeedsQuote
+func (c SQLiteConnector) Quote(ident string) string {
+	if !builder.NeedsQuote(ident, c.IsReservedWord) {
+		return ident
+	}
+	return `"` + strings.Replace(ident, `"`, `""`, -1) + `"`
+}
+
+func (SQLiteConnector) BindVar(i int) string {
+	return "?"
+}
+
+// SupportsReturning for RETURNING clause is added since sqlite 3.35
+func (SQLiteConnector) SupportsReturning() bool {
+	return true
+}
+
+func (SQLiteConnector) PrimaryKeyName() string {
+	return "primary"
+}
+
+func (SQLiteConnector) IsReservedWord(name string) bool {
+	return reservedWords[strings.ToLower(name)]
+}
+
+// IsErrorUnknownDatabase always returns false, database file of sqlite is created when opening
+func (SQLiteConnector) IsErrorUnknownDatabase(err error) bool {
+	return false
+}
+
+func (SQLiteConnector) IsErrorConflict(err error) bool {
+	return err != nil && strings.Contains(sqlx.UnwrapAll(err).Error(), "UNIQUE constraint failed")
+}
+
+// CreateDatabase returns nil, database of sqlite is the file opened
+func (c *SQLiteConnector) CreateDatabase(dbName string) builder.SqlExpr {
+	return nil
+}
+
+// CreateSchema returns nil, schema of sqlite is the database attached by ATTACH DATABASE
+func (c *SQLiteConnector) CreateSchema(schema string) builder.SqlExpr {
+	return nil
+}
+
+// DropDatabase returns nil, database of sqlite is the file opened
+func (c *SQLiteConnector) DropDatabase(dbName string) builder.SqlExpr {
+	return nil
+}
+
+// AddPrimaryKey rebuilds table, sqlite could not alter primary key
+func (c *SQLiteConnector) AddPrimaryKey(key *builder.Key) builder.SqlExpr {
+	return c.rebuild(key.Table, key.Table)
+}
+
+// DropPrimaryKey rebuilds table without the primary key, sqlite could not alter primary key
+func (c *SQLiteConnector) DropPrimaryKey(key *builder.Key) builder.SqlExpr {
+	return c.rebuild(cloneWith(key.Table, func(t *builder.Table) {
+		t.Keys.Remove(key.Name)
+	}), key.Table)
+}
+
+// AddIndex creates index named with prefix of table name, since names of indexes are unique in database.
+// Primary key could not be added by ALTER TABLE, so the table is rebuilt.
+func (c *SQLiteConnector) AddIndex(key *builder.Key) builder.SqlExpr {
+	if key.IsPrimary() {
+		return c.AddPrimaryKey(key)
+	}
+
+	e := builder.Expr("CREATE ")
+	if key.IsUnique {
+		e.WriteString("UNIQUE ")
+	}
+	e.WriteString("INDEX IF NOT EXISTS ")
+	writeIndexName(e, key.Table, key.Name)
+	e.WriteString(" ON ")
+	e.WriteString(c.Quote(key.Table.Name))
+	e.WriteByte(' ')
+	e.WriteGroup(func(e *builder.Ex) {
+		e.WriteExpr(key.Members())
+	})
+
+	if key.IsPartial() {
+		e.WriteString(" WHERE ")
+		e.WriteString(key.Where)
+	}
+
+	e.WriteEnd()
+	return e
+}
+
+// DropIndex drops index, primary key could not be dropped by ALTER TABLE, so the table is rebuilt.
+func (c *SQLiteConnector) DropIndex(key *builder.Key) builder.SqlExpr {
+	if key.IsPrimary() {
+		return c.DropPrimaryKey(key)
+	}
+
+	e := builder.Expr("DROP INDEX IF EXISTS ")
+	writeIndexName(e, key.Table, key.Name)
+	e.WriteEnd()
+	return e
+}
+
+// RenameIndex drops index and creates it by new name, sqlite could not rename index
+func (c *SQLiteConnector) RenameIndex(key *builder.Key, target *builder.Key) builder.SqlExpr {
+	return builder.MultiWith("\n", c.DropIndex(key), c.AddIndex(target))
+}
+
+// writeIndexName writes name of index, sqlite qualifies index by schema instead of table
+func writeIndexName(e *builder.Ex, t *builder.Table, keyName string) {
+	if t.Schema != "" {
+		e.WriteString(t.Schema)
+		e.WriteByte('.')
+	}
+	e.WriteString(t.Name)
+	e.WriteByte('_')
+	e.WriteString(keyName)
+}
+
+// AddForeignKey rebuilds the table, foreign key could only be declared when creating table.
+func (c *SQLiteConnector) AddForeignKey(fk *builder.ForeignKey) builder.SqlExpr {
+	return c.rebuild(fk.Table, fk.Table)
+}
+
+// DropForeignKey rebuilds the table without the foreign key.
+func (c *SQLiteConnector) DropForeignKey(fk *builder.ForeignKey) builder.SqlExpr {
+	return c.rebuild(cloneWith(fk.Table, func(t *builder.Table) {
+		t.ForeignKeys.Remove(fk.Name)
+	}), fk.Table)
+}
+
+// AddConstraint rebuilds the table, check constraint could only be declared when creating table.
+func (c *SQLiteConnector) AddConstraint(constraint *builder.Constraint) builder.SqlExpr {
+	return c.rebuild(constraint.Table, constraint.Table)
+}
+
+// DropConstraint rebuilds the table without the check constraint.
+func (c *SQLiteConnector) DropConstraint(constraint *builder.Constraint) builder.SqlExpr {
+	return c.rebuild(cloneWith(constraint.Table, func(t *builder.Table) {
+		t.Constraints.Remove(constraint.Name)
+	}), constraint.Table)
+}
+
+func (c *SQLiteConnector) CreateTableIsNotExists(t *builder.Table) (exprs []builder.SqlExpr) {
+	return c.createTable(t, true)
+}
+
+func (c *SQLiteConnector) CreateTable(t *builder.Table) builder.SqlExpr {
+	return builder.MultiWith("\n", c.createTable(t, c.IfExists)...)
+}
+
+func (c *SQLiteConnector) createTable(t *builder.Table, ifNotExists bool) (exprs []builder.SqlExpr) {
+	exprs = append(exprs, c.tableDef(t, t, ifNotExists))
+
+	t.Keys.Range(func(key *builder.Key, idx int) {
+		if !key.IsPrimary() {
+			exprs = append(exprs, c.AddIndex(key))
+		}
+	})
+
+	return
+}
+
+// tableDef returns CREATE TABLE of target by definition of t,
+// primary key, check constraints and foreign keys are declared inline,
+// since sqlite could not add them by ALTER TABLE.
+func (c *SQLiteConnector) tableDef(t *builder.Table, target *builder.Table, ifNotExists bool) *builder.Ex {
+	expr := builder.Expr("CREATE TABLE ")
+	if ifNotExists {
+		expr.WriteString("IF NOT EXISTS ")
+	}
+	expr.WriteExpr(target)
+	expr.WriteByte(' ')
+	expr.WriteGroup(func(e *builder.Ex) {
+		n := 0
+
+		writeDef := func(write func()) {
+			if n > 0 {
+				e.WriteByte(',')
+			}
+			e.WriteByte('\n')
+			e.WriteByte('\t')
+			write()
+			n++
+		}
+
+		t.Columns.Range(func(col *builder.Column, idx int) {
+			if col.DeprecatedActions != nil {
+				return
+			}
+			writeDef(func() {
+				e.WriteExpr(col)
+				e.WriteByte(' ')
+				e.WriteExpr(c.columnDef(col))
+			})
+		})
+
+		t.Keys.Range(func(key *builder.Key, idx int) {
+			// primary key of autoincrement column is declared as INTEGER PRIMARY KEY AUTOINCREMENT
+			if !key.IsPrimary() || isAutoIncrementKey(t, key) {
+				return
+			}
+			writeDef(func() {
+				e.WriteString("PRIMARY KEY ")
+				e.WriteGroup(func(e *builder.Ex) {
+					e.WriteExpr(key.Columns)
+				})
+			})
+		})
+
+		t.Constraints.Range(func(constraint *builder.Constraint, idx int) {
+			writeDef(func() {
+				e.WriteString("CONSTRAINT ")
+				e.WriteString(t.Name)
+				e.WriteByte('_')
+				e.WriteString(constraint.Name)
+				e.WriteString(" CHECK ")
+				e.WriteGroup(func(e *builder.Ex) {
+					e.WriteString(constraint.Def())
+				})
+			})
+		})
+
+		t.ForeignKeys.Range(func(fk *builder.ForeignKey, idx int) {
+			writeDef(func() {
+				e.WriteString("CONSTRAINT ")
+				e.WriteString(t.Name)
+				e.WriteByte('_')
+				e.WriteString(fk.Name)
+				e.WriteString(" FOREIGN KEY ")
+				e.WriteGroup(func(e *builder.Ex) {
+					e.WriteExpr(fk.Columns)
+				})
+				// referenced table could not be qualified by schema in sqlite
+				e.WriteString(" REFERENCES ")
+				e.WriteString(c.Quote(fk.RefTable.Name))
+				e.WriteByte(' ')
+				e.WriteGroup(func(e *builder.Ex) {
+					e.WriteExpr(fk.RefColumns)
+				})
+				if fk.OnDelete != "" {
+					e.WriteString(" ON DELETE ")
+					e.WriteString(fk.OnDelete)
+				}
+				if fk.OnUpdate != "" {
+					e.WriteString(" ON UPDATE ")
+					e.WriteString(fk.OnUpdate)
+				}
+			})
+		})
+
+		if n > 0 {
+			e.WriteByte('\n')
+		}
+	})
+
+	expr.WriteEnd()
+	return expr
+}
+
+func isAutoIncrementKey(t *builder.Table, key *builder.Key) bool {
+	autoIncrement := t.Columns.AutoIncrement()
+	if autoIncrement == nil || key.Columns.Len() != 1 {
+		return false
+	}
+	isAutoIncrement := false
+	key.Columns.Range(func(col *builder.Column, idx int) {
+		isAutoIncrement = strings.ToLower(col.Name) == strings.ToLower(autoIncrement.Name)
+	})
+	return isAutoIncrement
+}
+
+// RebuildTable rebuilds table from prev to t by the generalized ALTER TABLE procedure of sqlite,
+// https://www.sqlite.org/lang_altertable.html#otheralter
+// new table is created by definition of t, and data of columns both in prev and t are copied,
+// then the new table replaces prev, and indexes are recreated.
+//
+// PRAGMA foreign_keys is no-op in transaction, so the statements should be executed out of transaction,
+// and foreign keys are enabled after rebuilt.
+// Triggers and views are not managed by sqlx, which should be recreated by caller when they depend on the table.
+func (c *SQLiteConnector) RebuildTable(t *builder.Table, prev *builder.Table) builder.SqlExpr {
+	tmp := builder.T("_" + t.Name + "_new")
+	tmp.Schema = t.Schema
+
+	colNames := make([]string, 0)
+
+	t.Columns.Range(func(col *builder.Column, idx int) {
+		if col.DeprecatedActions != nil || col.IsGenerated() {
+			return
+		}
+		if prevCol := prev.Col(col.Name); prevCol != nil && prevCol.DeprecatedActions == nil && !prevCol.IsGenerated() {
+			colNames = append(colNames, c.Quote(col.Name))
+		}
+	})
+
+	exprs := []builder.SqlExpr{
+		// 1. disable foreign key constraints
+		builder.Expr("PRAGMA foreign_keys = OFF;"),
+		// 2. start a transaction
+		builder.Expr("BEGIN;"),
+		// 4. create new table in the desired revised format
+		c.tableDef(t, tmp, false),
+	}
+
+	// 5. transfer content into new table
+	if len(colNames) > 0 {
+		cols := strings.Join(colNames, ",")
+
+		e := builder.Expr("INSERT INTO ")
+		e.WriteExpr(tmp)
+		e.WriteString(" (" + cols + ") SELECT " + cols + " FROM ")
+		e.WriteExpr(prev)
+		e.WriteEnd()
+		exprs = append(exprs, e)
+	}
+
+	// 6. drop the old table
+	drop := builder.Expr("DROP TABLE ")
+	drop.WriteExpr(prev)
+	drop.WriteEnd()
+
+	// 7. change the name of new table to the name of old table
+	rename := builder.Expr("ALTER TABLE ")
+	rename.WriteExpr(tmp)
+	rename.WriteString(" RENAME TO ")
+	rename.WriteString(c.Quote(t.Name))
+	rename.WriteEnd()
+
+	exprs = append(exprs, drop, rename)
+
+	// 8. recreate indexes
+	t.Keys.Range(func(key *builder.Key, idx int) {
+		if !key.IsPrimary() {
+			exprs = append(exprs, c.AddIndex(key))
+		}
+	})
+
+	exprs = append(exprs,
+		// 10. check foreign key constraints
+		builder.Expr("PRAGMA foreign_key_check;"),
+		// 11. commit the transaction
+		builder.Expr("COMMIT;"),
+		// 12. re-enable foreign key constraints
+		builder.Expr("PRAGMA foreign_keys = ON;"),
+	)
+
+	return builder.MultiWith("\n", exprs...)
+}
+
+// rebuild rebuilds table from prev to t, which is marked for RequiresRebuild
+func (c *SQLiteConnector) rebuild(t *builder.Table, prev *builder.Table) builder.SqlExpr {
+	return &rebuildExpr{SqlExpr: c.RebuildTable(t, prev)}
+}
+
+type rebuildExpr struct {
+	builder.SqlExpr
+}
+
+// RequiresRebuild returns true when the change rebuilds table,
+// all changes of the table are applied by one RebuildTable then, see builder.RebuildDialect
+func (c *SQLiteConnector) RequiresRebuild(action builder.DiffAction) bool {
+	_, ok := action.Expr.(*rebuildExpr)
+	return ok
+}
+
+// cloneWith returns copy of t modified by modify
+func cloneWith(t *builder.Table, modify func(t *builder.Table)) *builder.Table {
+	next := t.Clone()
+	modify(next)
+	return next
+}
+
+func (c *SQLiteConnector) DropTable(t *builder.Table) builder.SqlExpr {
+	e := builder.Expr("DROP TABLE IF EXISTS ")
+	e.WriteExpr(t)
+	e.WriteEnd()
+	return e
+}
+
+// RenameTable renames table, and recreates indexes named with prefix of table name,
+// sqlite could not rename index
+func (c *SQLiteConnector) RenameTable(from *builder.Table, to *builder.Table) builder.SqlExpr {
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(from)
+	e.WriteString(" RENAME TO ")
+	e.WriteString(c.Quote(to.Name))
+	e.WriteEnd()
+
+	exprs := []builder.SqlExpr{e}
+
+	from.Keys.Range(func(key *builder.Key, idx int) {
+		if key.IsPrimary() {
+			return
+		}
+		exprs = append(exprs, c.RenameIndex(key, key.On(to)))
+	})
+
+	return builder.MultiWith("\n", exprs...)
+}
+
+// TruncateTable deletes all rows, sqlite has no TRUNCATE TABLE
+func (c *SQLiteConnector) TruncateTable(t *builder.Table) builder.SqlExpr {
+	e := builder.Expr("DELETE FROM ")
+	e.WriteExpr(t)
+	e.WriteEnd()
+	return e
+}
+
+// AddColumn adds column by ALTER TABLE,
+// but column NOT NULL without default, autoincrement column or stored generated column could not be added,
+// then the table is rebuilt with other columns of table copied.
+func (c *SQLiteConnector) AddColumn(col *builder.Column) builder.SqlExpr {
+	if (!col.Null && col.Default == nil && !col.IsGenerated()) || col.AutoIncrement ||
+		(col.IsGenerated() && strings.ToUpper(col.GeneratedStorage) != builder.GeneratedStorageVirtual) {
+		return c.rebuild(col.Table, cloneWith(col.Table, func(t *builder.Table) {
+			t.Columns.Remove(col.Name)
+		}))
+	}
+
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(col.Table)
+	e.WriteString(" ADD COLUMN ")
+	e.WriteExpr(col)
+	e.WriteByte(' ')
+	e.WriteExpr(c.columnDef(col))
+	e.WriteEnd()
+	return e
+}
+
+// columnDef returns data type of column, with generation expression of generated column
+func (c *SQLiteConnector) columnDef(col *builder.Column) builder.SqlExpr {
+	if !col.IsGenerated() {
+		return c.DataType(col.ColumnType)
+	}
+
+	storage := col.GeneratedStorage
+	if storage == "" {
+		storage = builder.GeneratedStorageStored
+	}
+
+	e := builder.Expr(c.dataType(col.ColumnType.Type, col.ColumnType))
+	if !col.Null {
+		e.WriteString(" NOT NULL")
+	}
+	e.WriteString(" GENERATED ALWAYS AS ")
+	e.WriteGroup(func(e *builder.Ex) {
+		e.WriteString(col.GeneratedExpr)
+	})
+	e.WriteByte(' ')
+	e.WriteString(strings.ToUpper(storage))
+	return e
+}
+
+func (c *SQLiteConnector) RenameColumn(col *builder.Column, target *builder.Column) builder.SqlExpr {
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(col.Table)
+	e.WriteString(" RENAME COLUMN ")
+	e.WriteExpr(col)
+	e.WriteString(" TO ")
+	e.WriteExpr(target)
+	e.WriteEnd()
+	return e
+}
+
+// ModifyColumn rebuilds the table, sqlite could not alter column
+func (c *SQLiteConnector) ModifyColumn(col *builder.Column, prev *builder.Column) builder.SqlExpr {
+	return c.rebuild(col.Table, prev.Table)
+}
+
+// ModifyColumnRisk returns RiskTableRewrite, ModifyColumn always rebuilds the table
+func (c *SQLiteConnector) ModifyColumnRisk(col *builder.Column, prev *builder.Column) builder.Risk {
+	return builder.RiskTableRewrite
+}
+
+// SetColumnDefault rebuilds the table, sqlite could not alter default of column
+func (c *SQLiteConnector) SetColumnDefault(col *builder.Column) builder.SqlExpr {
+	return c.rebuild(col.Table, col.Table)
+}
+
+// DropColumnDefault rebuilds the table, sqlite could not alter default of column
+func (c *SQLiteConnector) DropColumnDefault(col *builder.Column) builder.SqlExpr {
+	return c.rebuild(col.Table, col.Table)
+}
+
+// ModifyTableComment returns nil, sqlite has no comment of table
+func (c *SQLiteConnector) ModifyTableComment(t *builder.Table) builder.SqlExpr {
+	return nil
+}
+
+// ModifyColumnComment returns nil, sqlite has no comment of column
+func (c *SQLiteConnector) ModifyColumnComment(col *builder.Column) builder.SqlExpr {
+	return nil
+}
+
+// DropColumn rebuilds the table without the column and indexes on it,
+// ALTER TABLE DROP COLUMN of sqlite fails when the column is indexed or referenced.
+func (c *SQLiteConnector) DropColumn(col *builder.Column) builder.SqlExpr {
+	return c.rebuild(cloneWith(col.Table, func(t *builder.Table) {
+		t.Columns.Remove(col.Name)
+
+		keyNames := make([]string, 0)
+		t.Keys.Range(func(key *builder.Key, idx int) {
+			if key.Columns.Col(col.Name) != nil {
+				keyNames = append(keyNames, key.Name)
+			}
+		})
+		for _, name := range keyNames {
+			t.Keys.Remove(name)
+		}
+
+		fkNames := make([]string, 0)
+		t.ForeignKeys.Range(func(fk *builder.ForeignKey, idx int) {
+			if fk.HasCol(col.Name) {
+				fkNames = append(fkNames, fk.Name)
+			}
+		})
+		for _, name := range fkNames {
+			t.ForeignKeys.Remove(name)
+		}
+	}), col.Table)
+}
+
+func (c *SQLiteConnector) DataType(columnType *builder.ColumnType) builder.SqlExpr {
+	return builder.Expr(c.dataType(columnType.Type, columnType) + c.dataTypeModify(columnType))
+}
+
+func (c *SQLiteConnector) dataType(typ reflect.Type, columnType *builder.ColumnType) string {
+	if columnType.GetDataType != nil {
+		return columnType.GetDataType(c.DriverName())
+	}
+
+	switch typ.Kind() {
+	case reflect.Ptr:
+		return c.dataType(typ.Elem(), columnType)
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "real"
+	case reflect.String:
+		return "text"
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return "blob"
+		}
+	}
+	switch typ.Name() {
+	case "NullInt64":
+		return "integer"
+	case "NullFloat64":
+		return "real"
+	case "NullBool":
+		return "boolean"
+	case "Time":
+		return "datetime"
+	}
+	panic(fmt.Errorf("unsupport type %s", typ))
+}
+
+func (c *SQLiteConnector) dataTypeModify(columnType *builder.ColumnType) string {
+	buf := bytes.NewBuffer(nil)
+
+	if !columnType.Null {
+		buf.WriteString(" NOT NULL")
+	}
+
+	// autoincrement column of sqlite should be INTEGER PRIMARY KEY
+	if columnType.AutoIncrement {
+		buf.WriteString(" PRIMARY KEY AUTOINCREMENT")
+	}
+
+	if columnType.Default != nil {
+		buf.WriteString(" DEFAULT ")
+		buf.WriteString(*columnType.Default)
+	}
+
+	return buf.String()
+}
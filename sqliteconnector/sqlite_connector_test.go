@@ -0,0 +1,324 @@
+package sqliteconnector
+
+import (
+	"context"
+	"database/sql/driver"
+	"strconv"
+	"testing"
+
+	"github.com/go-courier/sqlx/v2/builder"
+	"github.com/go-courier/sqlx/v2/builder/buidertestingutils"
+	"github.com/onsi/gomega"
+)
+
+func TestSQLiteConnector(t *testing.T) {
+	c := &SQLiteConnector{}
+
+	table := builder.T("t",
+		builder.Col("F_id").Type(uint64(0), ",autoincrement"),
+		builder.Col("f_old_name").Type("", ",deprecated=f_name"),
+		builder.Col("F_name").Type("", ",size=128,default=''"),
+		builder.Col("F_created_at").Type(int64(0), ",default='0'"),
+		builder.Col("F_desc").Type("", ""),
+		builder.PrimaryKey(builder.Cols("F_id")),
+		builder.UniqueIndex("I_name", builder.Cols("F_name")),
+		builder.Index("I_created_at", builder.Cols("F_created_at")),
+	)
+
+	t.Run("CreateDatabase", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(c.CreateDatabase("db")).To(gomega.BeNil())
+	})
+
+	t.Run("CreateTable", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(c.CreateTable(table)).
+			To(buidertestingutils.BeExpr( /* language=SQLite */ `CREATE TABLE t (
+	f_id integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+	f_name text NOT NULL DEFAULT '',
+	f_created_at integer NOT NULL DEFAULT '0',
+	f_desc text NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS t_i_name ON t (f_name);
+CREATE INDEX IF NOT EXISTS t_i_created_at ON t (f_created_at);`))
+	})
+
+	t.Run("AddIndex", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(c.AddIndex(table.Key("I_name"))).
+			To(buidertestingutils.BeExpr( /* language=SQLite */ `CREATE UNIQUE INDEX IF NOT EXISTS t_i_name ON t (f_name);`))
+	})
+
+	t.Run("AddPartialIndex", func(t *testing.T) {
+		key := builder.PartialIndex("i_desc", builder.Cols("f_desc"), "f_desc <> ''").On(table)
+
+		gomega.NewWithT(t).Expect(c.AddIndex(key)).
+			To(buidertestingutils.BeExpr( /* language=SQLite */ `CREATE INDEX IF NOT EXISTS t_i_desc ON t (f_desc) WHERE f_desc <> '';`))
+	})
+
+	t.Run("DropIndex", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(c.DropIndex(table.Key("I_name"))).
+			To(buidertestingutils.BeExpr( /* language=SQLite */ `DROP INDEX IF EXISTS t_i_name;`))
+	})
+
+	t.Run("DropIndexWithSchema", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(c.DropIndex(table.WithSchema("other").Key("I_name"))).
+			To(buidertestingutils.BeExpr( /* language=SQLite */ `DROP INDEX IF EXISTS other.t_i_name;`))
+	})
+
+	t.Run("AddNullableColumn", func(t *testing.T) {
+		col := builder.Col("f_nickname").Type("", ",null").On(table)
+
+		gomega.NewWithT(t).Expect(c.AddColumn(col)).
+			To(buidertestingutils.BeExpr( /* language=SQLite */ `ALTER TABLE t ADD COLUMN f_nickname text;`))
+	})
+
+	t.Run("AddDefaultedColumn", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(c.AddColumn(table.Col("F_name"))).
+			To(buidertestingutils.BeExpr( /* language=SQLite */ `ALTER TABLE t ADD COLUMN f_name text NOT NULL DEFAULT '';`))
+	})
+
+	t.Run("AddNotNullColumn", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(c.AddColumn(table.Col("F_desc"))).
+			To(buidertestingutils.BeExpr( /* language=SQLite */ `PRAGMA foreign_keys = OFF;
+BEGIN;
+CREATE TABLE _t_new (
+	f_id integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+	f_name text NOT NULL DEFAULT '',
+	f_created_at integer NOT NULL DEFAULT '0',
+	f_desc text NOT NULL
+);
+INSERT INTO _t_new (f_id,f_name,f_created_at) SELECT f_id,f_name,f_created_at FROM t;
+DROP TABLE t;
+ALTER TABLE _t_new RENAME TO t;
+CREATE UNIQUE INDEX IF NOT EXISTS t_i_name ON t (f_name);
+CREATE INDEX IF NOT EXISTS t_i_created_at ON t (f_created_at);
+PRAGMA foreign_key_check;
+COMMIT;
+PRAGMA foreign_keys = ON;`))
+	})
+
+	t.Run("DropColumn", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(c.DropColumn(table.Col("F_created_at"))).
+			To(buidertestingutils.BeExpr( /* language=SQLite */ `PRAGMA foreign_keys = OFF;
+BEGIN;
+CREATE TABLE _t_new (
+	f_id integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+	f_name text NOT NULL DEFAULT '',
+	f_desc text NOT NULL
+);
+INSERT INTO _t_new (f_id,f_name,f_desc) SELECT f_id,f_name,f_desc FROM t;
+DROP TABLE t;
+ALTER TABLE _t_new RENAME TO t;
+CREATE UNIQUE INDEX IF NOT EXISTS t_i_name ON t (f_name);
+PRAGMA foreign_key_check;
+COMMIT;
+PRAGMA foreign_keys = ON;`))
+
+		gomega.NewWithT(t).Expect(table.Col("F_created_at")).NotTo(gomega.BeNil())
+		gomega.NewWithT(t).Expect(table.Key("I_created_at")).NotTo(gomega.BeNil())
+	})
+
+	t.Run("ModifyColumn", func(t *testing.T) {
+		prevTable := builder.T("t",
+			builder.Col("f_id").Type(uint64(0), ",autoincrement"),
+			builder.Col("f_name").Type("", ",size=128,default=''"),
+			builder.Col("f_created_at").Type("", ",default=''"),
+			builder.PrimaryKey(builder.Cols("f_id")),
+		)
+
+		gomega.NewWithT(t).Expect(c.ModifyColumn(table.Col("F_created_at"), prevTable.Col("F_created_at"))).
+			To(buidertestingutils.BeExpr( /* language=SQLite */ `PRAGMA foreign_keys = OFF;
+BEGIN;
+CREATE TABLE _t_new (
+	f_id integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+	f_name text NOT NULL DEFAULT '',
+	f_created_at integer NOT NULL DEFAULT '0',
+	f_desc text NOT NULL
+);
+INSERT INTO _t_new (f_id,f_name,f_created_at) SELECT f_id,f_name,f_created_at FROM t;
+DROP TABLE t;
+ALTER TABLE _t_new RENAME TO t;
+CREATE UNIQUE INDEX IF NOT EXISTS t_i_name ON t (f_name);
+CREATE INDEX IF NOT EXISTS t_i_created_at ON t (f_created_at);
+PRAGMA foreign_key_check;
+COMMIT;
+PRAGMA foreign_keys = ON;`))
+	})
+
+	t.Run("RenameTable", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(c.RenameTable(table, builder.T("t_renamed"))).
+			To(buidertestingutils.BeExpr( /* language=SQLite */ `ALTER TABLE t RENAME TO t_renamed;
+DROP INDEX IF EXISTS t_i_name;
+CREATE UNIQUE INDEX IF NOT EXISTS t_renamed_i_name ON t_renamed (f_name);
+DROP INDEX IF EXISTS t_i_created_at;
+CREATE INDEX IF NOT EXISTS t_renamed_i_created_at ON t_renamed (f_created_at);`))
+	})
+
+	t.Run("TruncateTable", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(c.TruncateTable(table)).
+			To(buidertestingutils.BeExpr( /* language=SQLite */ `DELETE FROM t;`))
+	})
+}
+
+func TestSQLiteConnector_Diff(t *testing.T) {
+	c := &SQLiteConnector{}
+
+	prevTable := builder.T("t",
+		builder.Col("f_name").Type("", ",size=128,default=''"),
+		builder.Col("f_desc").Type("", ",size=128"),
+	)
+
+	table := builder.T("t",
+		builder.Col("f_name").Type("", ",size=128,default=''"),
+		builder.Col("f_desc").Type("", ",size=128"),
+		builder.Col("f_nickname").Type("", ",null,comment=nickname"),
+		builder.Index("i_desc", builder.Cols("f_desc")),
+	)
+
+	gomega.NewWithT(t).Expect(builder.RenderMigration(table.Diff(prevTable, c), c)).To(gomega.Equal(`-- migration of sqlite
+ALTER TABLE t ADD COLUMN f_nickname text;
+CREATE INDEX IF NOT EXISTS t_i_desc ON t (f_desc);
+`))
+}
+
+func TestSQLiteConnector_DiffRebuild(t *testing.T) {
+	c := &SQLiteConnector{}
+
+	prevTable := builder.T("t",
+		builder.Col("f_id").Type(uint64(0), ""),
+		builder.Col("f_desc").Type("", ",size=128"),
+		builder.Index("i_desc", builder.Cols("f_desc")),
+	)
+
+	table := builder.T("t",
+		builder.Col("f_id").Type(uint64(0), ""),
+		builder.Col("f_a").Type("", ",size=128"),
+		builder.Col("f_b").Type("", ",size=128"),
+		builder.Col("f_desc").Type("", ",size=128,default=''"),
+		builder.Index("i_a", builder.Cols("f_a")),
+	)
+
+	actions := table.DiffActions(prevTable, c)
+
+	gomega.NewWithT(t).Expect(actions).To(gomega.HaveLen(1))
+	gomega.NewWithT(t).Expect(actions[0].Kind).To(gomega.Equal(builder.DiffActionRebuildTable))
+	gomega.NewWithT(t).Expect(actions[0].Expr).To(buidertestingutils.BeExpr( /* language=SQLite */ `PRAGMA foreign_keys = OFF;
+BEGIN;
+CREATE TABLE _t_new (
+	f_id integer NOT NULL,
+	f_a text NOT NULL,
+	f_b text NOT NULL,
+	f_desc text NOT NULL DEFAULT ''
+);
+INSERT INTO _t_new (f_id,f_desc) SELECT f_id,f_desc FROM t;
+DROP TABLE t;
+ALTER TABLE _t_new RENAME TO t;
+CREATE INDEX IF NOT EXISTS t_i_a ON t (f_a);
+PRAGMA foreign_key_check;
+COMMIT;
+PRAGMA foreign_keys = ON;`))
+	// i_desc dropped by the rebuild
+	gomega.NewWithT(t).Expect(builder.HasDestructiveChanges(actions)).To(gomega.BeTrue())
+
+	t.Run("rebuild dropped column", func(t *testing.T) {
+		actions := builder.T("t",
+			builder.Col("f_id").Type(uint64(0), ""),
+			builder.Col("f_desc").Type("", ",size=128,default=''"),
+		).DiffActions(builder.T("t",
+			builder.Col("f_id").Type(uint64(0), ""),
+			builder.Col("f_desc").Type("", ",size=128"),
+			builder.Col("f_name").Type("", ",size=128"),
+		), c)
+
+		gomega.NewWithT(t).Expect(actions).To(gomega.HaveLen(1))
+		gomega.NewWithT(t).Expect(actions[0].Kind).To(gomega.Equal(builder.DiffActionRebuildTable))
+		gomega.NewWithT(t).Expect(actions[0].IsDestructive()).To(gomega.BeTrue())
+	})
+
+	t.Run("rebuild without losing columns or keys", func(t *testing.T) {
+		actions := builder.T("t",
+			builder.Col("f_id").Type(uint64(0), ""),
+			builder.Col("f_desc").Type("", ",size=128,default=''"),
+			builder.Index("i_desc", builder.Cols("f_desc")),
+		).DiffActions(prevTable, c)
+
+		gomega.NewWithT(t).Expect(actions).To(gomega.HaveLen(1))
+		gomega.NewWithT(t).Expect(actions[0].Kind).To(gomega.Equal(builder.DiffActionRebuildTable))
+		gomega.NewWithT(t).Expect(builder.HasDestructiveChanges(actions)).To(gomega.BeFalse())
+	})
+
+	t.Run("without rebuild", func(t *testing.T) {
+		actions := builder.T("t",
+			builder.Col("f_id").Type(uint64(0), ""),
+			builder.Col("f_desc").Type("", ",size=128"),
+			builder.Col("f_nickname").Type("", ",null"),
+		).DiffActions(prevTable, c)
+
+		gomega.NewWithT(t).Expect(actions).To(gomega.HaveLen(2))
+		gomega.NewWithT(t).Expect(actions[0].Kind).To(gomega.Equal(builder.DiffActionAddColumn))
+		gomega.NewWithT(t).Expect(actions[1].Kind).To(gomega.Equal(builder.DiffActionDropIndex))
+	})
+
+	t.Run("primary key changed", func(t *testing.T) {
+		actions := builder.T("t",
+			builder.Col("f_id").Type(uint64(0), ""),
+			builder.Col("f_desc").Type("", ",size=128"),
+			builder.PrimaryKey(builder.Cols("f_id", "f_desc")),
+		).DiffActions(builder.T("t",
+			builder.Col("f_id").Type(uint64(0), ""),
+			builder.Col("f_desc").Type("", ",size=128"),
+			builder.PrimaryKey(builder.Cols("f_id")),
+		), c)
+
+		gomega.NewWithT(t).Expect(actions).To(gomega.HaveLen(1))
+		gomega.NewWithT(t).Expect(actions[0].Kind).To(gomega.Equal(builder.DiffActionRebuildTable))
+		gomega.NewWithT(t).Expect(builder.ResolveExpr(actions[0].Expr).Query()).To(gomega.ContainSubstring("PRIMARY KEY (f_id,f_desc)"))
+	})
+}
+
+func TestSQLiteConnector_IsReservedWord(t *testing.T) {
+	c := &SQLiteConnector{}
+
+	gomega.NewWithT(t).Expect(c.IsReservedWord("PRAGMA")).To(gomega.BeTrue())
+	gomega.NewWithT(t).Expect(c.IsReservedWord("f_name")).To(gomega.BeFalse())
+}
+
+type fakeDriver struct {
+	queries *[]string
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{queries: d.queries}, nil
+}
+
+type fakeConn struct {
+	driver.Conn
+	queries *[]string
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	*c.queries = append(*c.queries, query)
+	return driver.RowsAffected(1), nil
+}
+
+func TestSQLiteConnector_Connect(t *testing.T) {
+	queries := make([]string, 0)
+
+	c := &SQLiteConnector{SQLiteDriver: &fakeDriver{queries: &queries}}
+
+	conn, err := c.Connect(context.Background())
+	gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+
+	_, err = conn.(driver.ExecerContext).ExecContext(context.Background(), "UPDATE t SET f_name = '?' WHERE f_id = ?", []driver.NamedValue{{Ordinal: 1, Value: int64(1)}})
+	gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+	gomega.NewWithT(t).Expect(queries).To(gomega.Equal([]string{"UPDATE t SET f_name = '?' WHERE f_id = ?"}))
+
+	_, err = conn.(driver.QueryerContext).QueryContext(context.Background(), "SELECT 1", nil)
+	gomega.NewWithT(t).Expect(err).To(gomega.Equal(driver.ErrSkip))
+
+	named := &bindVarsConn{Conn: &fakeConn{queries: &queries}, bindVar: func(i int) string {
+		return "?" + strconv.Itoa(i)
+	}}
+
+	_, err = named.ExecContext(context.Background(), "UPDATE t SET f_name = ? WHERE f_id = ?", nil)
+	gomega.NewWithT(t).Expect(err).To(gomega.BeNil())
+	gomega.NewWithT(t).Expect(queries[1]).To(gomega.Equal("UPDATE t SET f_name = ?1 WHERE f_id = ?2"))
+}
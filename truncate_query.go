@@ -0,0 +1,33 @@
+package sqlx
+
+import (
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+)
+
+// TruncateQuery limits the length of query printed in logs, 0 means no limit.
+// The query is rendered lazily, and truncated without breaking runes.
+func TruncateQuery(q fmt.Stringer, maxLength int) fmt.Stringer {
+	if maxLength <= 0 {
+		return q
+	}
+	return &truncatedQuery{Stringer: q, maxLength: maxLength}
+}
+
+type truncatedQuery struct {
+	fmt.Stringer
+	maxLength int
+}
+
+func (p *truncatedQuery) String() string {
+	s := p.Stringer.String()
+	if len(s) <= p.maxLength {
+		return s
+	}
+	end := p.maxLength
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+	return s[:end] + "...(truncated, " + strconv.Itoa(len(s)-end) + " bytes)"
+}
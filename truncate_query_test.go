@@ -0,0 +1,32 @@
+package sqlx_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-courier/sqlx/v2"
+	"github.com/onsi/gomega"
+)
+
+type stringer string
+
+func (s stringer) String() string {
+	return string(s)
+}
+
+func TestTruncateQuery(t *testing.T) {
+	q := stringer("SELECT * FROM t WHERE f_name = '" + strings.Repeat("x", 100) + "'")
+
+	t.Run("no limit", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(sqlx.TruncateQuery(q, 0).String()).To(gomega.Equal(string(q)))
+	})
+	t.Run("shorter than limit", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(sqlx.TruncateQuery(q, 1000).String()).To(gomega.Equal(string(q)))
+	})
+	t.Run("truncated", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(sqlx.TruncateQuery(q, 13).String()).To(gomega.Equal("SELECT * FROM...(truncated, 120 bytes)"))
+	})
+	t.Run("truncated without breaking rune", func(t *testing.T) {
+		gomega.NewWithT(t).Expect(sqlx.TruncateQuery(stringer("名字"), 4).String()).To(gomega.Equal("名...(truncated, 3 bytes)"))
+	})
+}